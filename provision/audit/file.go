@@ -0,0 +1,44 @@
+package audit
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/pkg/errors"
+)
+
+// File is an audit sink that appends every record as a line of JSON to a file. Pass it to the operations with types.WithAuditSink.
+type File struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFile creates an audit sink writing to the file at the given path. The file is created on the first record if it does not exist.
+func NewFile(path string) *File {
+	return &File{path: path}
+}
+
+// Record appends the record to the file.
+// The file is opened for every record, so that it can be rotated while Hydroform is running.
+func (f *File) Record(r types.AuditRecord) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return errors.Wrap(err, "could not encode the audit record")
+	}
+	line = append(line, '\n')
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return errors.Wrap(err, "could not open the audit log")
+	}
+	if _, err := file.Write(line); err != nil {
+		file.Close()
+		return errors.Wrap(err, "could not write the audit log")
+	}
+	return file.Close()
+}
@@ -0,0 +1,65 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hf-audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+
+	f := NewFile(path)
+	records := []types.AuditRecord{
+		{
+			Time:      time.Date(2020, 3, 1, 10, 0, 0, 0, time.UTC),
+			Operation: "provision",
+			Caller:    "jane",
+			Cluster:   &types.Cluster{Name: "my-cluster", NodeCount: 3},
+			Provider:  &types.Provider{Type: types.GCP, ProjectName: "my-project"},
+			Plan:      &types.PlanSummary{Added: 3},
+			Duration:  10 * time.Minute,
+			Result:    types.AuditSuccess,
+		},
+		{
+			Time:      time.Date(2020, 3, 1, 11, 0, 0, 0, time.UTC),
+			Operation: "deprovision",
+			Caller:    "joe",
+			Cluster:   &types.Cluster{Name: "my-cluster", NodeCount: 3},
+			Provider:  &types.Provider{Type: types.GCP, ProjectName: "my-project"},
+			Duration:  time.Minute,
+			Result:    types.AuditFailure,
+			Error:     "unable to deprovision gcp cluster",
+		},
+	}
+	for _, r := range records {
+		require.NoError(t, f.Record(r))
+	}
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	info, err := file.Stat()
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm(), "The audit log should only be readable by its owner")
+
+	var read []types.AuditRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var r types.AuditRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &r), "Every line should be a record")
+		read = append(read, r)
+	}
+	require.NoError(t, scanner.Err())
+	require.Equal(t, records, read, "The records should be appended in order")
+}
@@ -81,7 +81,7 @@ fi
 ##
 # GO VET
 ##
-packagesToVet=("./internal/..." "./action/..." "./types/..." "./examples/...")
+packagesToVet=("./internal/..." "./action/..." "./types/..." "./examples/..." "./cmd/...")
 
 for vPackage in "${packagesToVet[@]}"; do
 	vetResult=$(go vet ${vPackage})
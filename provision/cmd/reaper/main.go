@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"time"
+
+	hf "github.com/kyma-incubator/hydroform/provision"
+	"github.com/kyma-incubator/hydroform/provision/types"
+)
+
+func main() {
+	dataDir := flag.String("data-dir", "", "Hydroform data directory to scan for expired clusters. Defaults to the Hydroform default data directory.")
+	keyFile := flag.String("encryption-key-file", "", "File containing the key the cluster states are encrypted with, if any.")
+	dryRun := flag.Bool("dry-run", false, "Only list the expired clusters without deprovisioning them.")
+	flag.Parse()
+
+	log.SetOutput(ioutil.Discard)
+
+	var ops []types.Option
+	if *dataDir != "" {
+		ops = append(ops, types.WithDataDir(*dataDir))
+	}
+	if *keyFile != "" {
+		ops = append(ops, types.WithEncryptionKeyFile(*keyFile))
+	}
+
+	if *dryRun {
+		fmt.Println("Dry run, the following clusters would be reaped:")
+	} else {
+		fmt.Println("Reaping expired clusters...")
+	}
+
+	reaped, err := hf.Reap(*dryRun, ops...)
+	for _, c := range reaped {
+		fmt.Printf("%s/%s/%s\texpired at %s\n", c.Provider, c.Project, c.Name, c.ClusterSpec.ExpiresAt.Format(time.RFC3339))
+	}
+	if err != nil {
+		fmt.Println("Error", err.Error())
+		os.Exit(1)
+	}
+
+	fmt.Printf("%d cluster(s) reaped\n", len(reaped))
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+
+	hf "github.com/kyma-incubator/hydroform/provision"
+	"github.com/kyma-incubator/hydroform/provision/types"
+)
+
+func main() {
+	kubeconfig := flag.String("k", "", "Path to the kubeconfig of the existing cluster. If empty, KUBECONFIG or the default kubeconfig is used.")
+	context := flag.String("c", "", "Context of the existing cluster in the kubeconfig. If empty, the current context is used.")
+	flag.Parse()
+
+	log.SetOutput(ioutil.Discard)
+
+	cluster := &types.Cluster{
+		Name: "existing-cluster",
+	}
+	provider := &types.Provider{
+		Type:        types.Existing,
+		Credentials: &types.Credentials{File: *kubeconfig, Env: *kubeconfig == ""},
+	}
+	if *context != "" {
+		provider.CustomConfigurations = map[string]interface{}{
+			"context": *context,
+		}
+	}
+
+	fmt.Println("Connecting...")
+
+	cluster, err := hf.Provision(cluster, provider)
+	if err != nil {
+		fmt.Println("Error", err.Error())
+		return
+	}
+
+	fmt.Printf("Connected to Kubernetes %s at %s\n", cluster.ClusterInfo.KubernetesVersion, cluster.ClusterInfo.Endpoint)
+
+	fmt.Println("Getting the status")
+
+	status, err := hf.Status(cluster, provider)
+	if err != nil {
+		fmt.Println("Error", err.Error())
+		return
+	}
+
+	fmt.Println("Status:", status.Phase)
+	for _, c := range status.Conditions {
+		fmt.Printf("  %s: %s %s\n", c.Type, c.Status, c.Message)
+	}
+}
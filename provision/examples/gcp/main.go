@@ -7,6 +7,7 @@ import (
 	"log"
 
 	hf "github.com/kyma-incubator/hydroform/provision"
+	"github.com/kyma-incubator/hydroform/provision/kubeconfig"
 	"github.com/kyma-incubator/hydroform/provision/types"
 )
 
@@ -67,7 +68,7 @@ func main() {
 		return
 	}
 
-	err = ioutil.WriteFile("kubeconfig.yaml", content, 0600)
+	err = kubeconfig.Merge("kubeconfig.yaml", cluster.Name, content)
 	if err != nil {
 		fmt.Println("Error", err.Error())
 		return
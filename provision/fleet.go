@@ -0,0 +1,122 @@
+package provision
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kyma-incubator/hydroform/provision/action"
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/pkg/errors"
+)
+
+// DefaultFleetConcurrency is the number of clusters of a fleet handled at the same time if no limit is given.
+const DefaultFleetConcurrency = 5
+
+// ProvisionFleet creates all clusters of the fleet, running at most concurrency provisionings at the same time. If concurrency is not positive, DefaultFleetConcurrency is used.
+// The clusters are kept in the data directory like clusters provisioned one by one, so that List, Describe and Reap find them.
+// The before and after actions run once for the whole fleet. Failing to create a cluster does not stop the others from being created.
+// It returns the result of each cluster and an error listing all clusters that could not be created.
+func ProvisionFleet(members []types.FleetMember, concurrency int, ops ...types.Option) (*types.FleetSummary, error) {
+	return runFleet(members, concurrency, ops, func(m types.FleetMember, ops []types.Option) (*types.Cluster, error) {
+		return provision(m.Cluster, m.Provider, ops...)
+	})
+}
+
+// DeprovisionFleet removes all clusters of a fleet created with ProvisionFleet, running at most concurrency deprovisionings at the same time. If concurrency is not positive, DefaultFleetConcurrency is used.
+// Use the same options as for ProvisionFleet, so that the clusters are found in the data directory.
+// The before and after actions run once for the whole fleet. Failing to remove a cluster does not stop the others from being removed.
+// It returns the result of each cluster and an error listing all clusters that could not be removed.
+func DeprovisionFleet(members []types.FleetMember, concurrency int, ops ...types.Option) (*types.FleetSummary, error) {
+	return runFleet(members, concurrency, ops, func(m types.FleetMember, ops []types.Option) (*types.Cluster, error) {
+		return m.Cluster, deprovision(m.Cluster, m.Provider, ops...)
+	})
+}
+
+// runFleet runs the operation for every member of the fleet with at most concurrency operations at the same time.
+func runFleet(members []types.FleetMember, concurrency int, ops []types.Option, operation func(types.FleetMember, []types.Option) (*types.Cluster, error)) (*types.FleetSummary, error) {
+	if err := validateFleet(members, ops...); err != nil {
+		return nil, err
+	}
+	if concurrency <= 0 {
+		concurrency = DefaultFleetConcurrency
+	}
+
+	if err := action.Before(); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	summary := &types.FleetSummary{Results: make([]*types.FleetResult, len(members))}
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, m := range members {
+		// each member works on its own copy of the provider, since operations may change it
+		provider := *m.Provider
+		member := types.FleetMember{Cluster: m.Cluster, Provider: &provider}
+		result := &types.FleetResult{Cluster: m.Cluster, Provider: m.Provider}
+		summary.Results[i] = result
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			// the members share the data directory, each cluster has its own directory in it
+			memberStart := time.Now()
+			cl, err := operation(member, ops)
+			result.Duration = time.Since(memberStart)
+			result.Err = err
+			if err == nil && cl != nil {
+				result.Cluster = cl
+			}
+		}()
+	}
+	wg.Wait()
+	summary.Duration = time.Since(start)
+
+	for _, r := range summary.Results {
+		if r.Err != nil {
+			summary.Failed++
+		} else {
+			summary.Succeeded++
+		}
+	}
+
+	if err := summary.Err(); err != nil {
+		return summary, err
+	}
+	return summary, action.After()
+}
+
+// validateFleet makes sure that all members are complete and that no cluster appears twice in the fleet.
+func validateFleet(members []types.FleetMember, ops ...types.Option) error {
+	os := &types.Options{}
+	for _, o := range ops {
+		o(os)
+	}
+
+	var errMessage string
+	// a fixed context would make the members replace each other in the kubeconfig file
+	if os.Kubeconfig != nil && os.Kubeconfig.Context != "" && len(members) > 1 {
+		errMessage += fmt.Sprintf("\n - all members would be merged into the kubeconfig context %s, leave the context empty to name it after each cluster", os.Kubeconfig.Context)
+	}
+	seen := make(map[string]bool)
+	for i, m := range members {
+		if m.Cluster == nil || m.Provider == nil {
+			errMessage += fmt.Sprintf("\n - member %d: cluster and provider are required", i)
+			continue
+		}
+		key := fmt.Sprintf("%s/%s/%s", m.Provider.Type, m.Provider.ProjectName, m.Cluster.Name)
+		if seen[key] {
+			errMessage += fmt.Sprintf("\n - %s: the cluster appears more than once", key)
+		}
+		seen[key] = true
+	}
+
+	if errMessage != "" {
+		return errors.New("invalid fleet: " + errMessage)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package provision
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kyma-incubator/hydroform/provision/action"
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/stretchr/testify/require"
+)
+
+type countingAction struct {
+	runs int
+}
+
+func (a *countingAction) Run(args ...interface{}) (interface{}, error) {
+	a.runs++
+	return nil, nil
+}
+
+func TestProvisionFleet(t *testing.T) {
+	// none of the members reaches the provider, so nothing is written to the data directory
+	dataDir := "test-data"
+	members := []types.FleetMember{
+		{Cluster: &types.Cluster{Name: "one"}, Provider: &types.Provider{Type: types.AWS, ProjectName: "project"}},
+		{Cluster: &types.Cluster{Name: "two"}, Provider: &types.Provider{Type: "unknown", ProjectName: "project"}},
+		{Cluster: &types.Cluster{Name: "three"}, Provider: &types.Provider{Type: types.AWS, ProjectName: "project"}},
+	}
+
+	before := &countingAction{}
+	action.SetBefore(before)
+	defer action.SetBefore(nil)
+
+	summary, err := ProvisionFleet(members, 2, types.WithDataDir(dataDir))
+	require.Error(t, err, "Provisioning clusters of unsupported providers should fail")
+	require.Equal(t, 1, before.runs, "The before action should run once for the whole fleet")
+
+	require.Len(t, summary.Results, len(members))
+	require.Equal(t, 0, summary.Succeeded)
+	require.Equal(t, 3, summary.Failed)
+	for i, r := range summary.Results {
+		require.Equal(t, members[i].Cluster, r.Cluster, "Results should be in the order of the members")
+		require.Error(t, r.Err)
+	}
+	require.Contains(t, err.Error(), "aws/project/one: aws not supported yet")
+	require.Contains(t, err.Error(), "unknown/project/two: unknown provider")
+}
+
+func TestProvisionFleetInvalid(t *testing.T) {
+	members := []types.FleetMember{
+		{Cluster: &types.Cluster{Name: "one"}, Provider: &types.Provider{Type: types.Kind, ProjectName: "project"}},
+		{Cluster: &types.Cluster{Name: "one"}, Provider: &types.Provider{Type: types.Kind, ProjectName: "project"}},
+		{Cluster: &types.Cluster{Name: "two"}},
+	}
+
+	summary, err := ProvisionFleet(members, 0)
+	require.Nil(t, summary, "No cluster should be provisioned for an invalid fleet")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "kind/project/one: the cluster appears more than once")
+	require.Contains(t, err.Error(), "member 2: cluster and provider are required")
+
+	members = members[:1]
+	members = append(members, types.FleetMember{Cluster: &types.Cluster{Name: "two"}, Provider: &types.Provider{Type: types.Kind, ProjectName: "project"}})
+	_, err = ProvisionFleet(members, 0, types.WithKubeconfig("config", "shared"))
+	require.Error(t, err, "Merging all members into the same context should fail")
+	require.Contains(t, err.Error(), "kubeconfig context shared")
+}
+
+func TestRunFleetConcurrency(t *testing.T) {
+	var members []types.FleetMember
+	for _, name := range []string{"one", "two", "three", "four", "five", "six", "seven"} {
+		members = append(members, types.FleetMember{Cluster: &types.Cluster{Name: name}, Provider: &types.Provider{Type: types.Kind, ProjectName: "project"}})
+	}
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	release := make(chan struct{})
+	started := make(chan struct{}, len(members))
+	operation := func(m types.FleetMember, ops []types.Option) (*types.Cluster, error) {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+		started <- struct{}{}
+
+		<-release
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return m.Cluster, nil
+	}
+
+	var summary *types.FleetSummary
+	var err error
+	done := make(chan struct{})
+	go func() {
+		summary, err = runFleet(members, 3, nil, operation)
+		close(done)
+	}()
+
+	// wait until the limit is reached and give further operations the chance to start
+	for i := 0; i < 3; i++ {
+		<-started
+	}
+	time.Sleep(50 * time.Millisecond)
+	mu.Lock()
+	require.Equal(t, 3, running, "Only as many operations as the limit allows should run")
+	mu.Unlock()
+
+	close(release)
+	<-done
+	require.NoError(t, err)
+	require.Equal(t, 3, maxRunning, "At most the limit of operations should run at the same time")
+	require.Equal(t, len(members), summary.Succeeded)
+}
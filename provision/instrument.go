@@ -0,0 +1,116 @@
+package provision
+
+import (
+	"strings"
+	"time"
+
+	"github.com/kyma-incubator/hydroform/provision/internal/tracing"
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/pkg/errors"
+)
+
+const redacted = "[REDACTED]"
+
+// secretSettings are the parts of custom configuration keys that mark the value as secret.
+var secretSettings = []string{"password", "token", "client_secret", "private_key", "access_key", "credentials"}
+
+// instrument prepares the tracing, metrics and audit of an operation on a cluster, as enabled with the options.
+// It returns the options to pass on to the provisioners and a function to defer with the address of the error of the operation.
+// If the audit record cannot be stored, the operation fails.
+func instrument(operation string, cluster *types.Cluster, provider *types.Provider, ops []types.Option) ([]types.Option, func(*error)) {
+	os := &types.Options{}
+	for _, o := range ops {
+		o(os)
+	}
+	start := time.Now()
+
+	passOn := make([]types.Option, len(ops), len(ops)+2)
+	copy(passOn, ops)
+
+	tracer, span := tracing.New(os).Start(operation, tracing.Cluster(provider.Type, provider.ProjectName, cluster.Name)...)
+	if tracer != nil {
+		// the spans of the provisioners become children of the span of the operation
+		passOn = append(passOn, types.WithContext(tracer.Context()))
+	}
+
+	var record *types.AuditRecord
+	if os.AuditSink != nil {
+		// take the specification before the operation changes it
+		record = &types.AuditRecord{
+			Time:      start.UTC(),
+			Operation: operation,
+			Caller:    os.Caller,
+			Cluster:   auditCluster(cluster),
+			Provider:  auditProvider(provider),
+		}
+		observer := os.PlanObserver
+		passOn = append(passOn, types.WithPlanObserver(func(s types.PlanSummary) {
+			record.Plan = &s
+			if observer != nil {
+				observer(s)
+			}
+		}))
+	}
+
+	return passOn, func(err *error) {
+		if record != nil {
+			record.Duration = time.Since(start)
+			record.Result = types.AuditSuccess
+			if *err != nil {
+				record.Result = types.AuditFailure
+				record.Error = (*err).Error()
+			}
+			if auditErr := os.AuditSink.Record(*record); auditErr != nil && *err == nil {
+				*err = errors.Wrap(auditErr, "could not audit the operation")
+			}
+		}
+
+		tracing.End(span, *err)
+		if os.Metrics != nil {
+			os.Metrics.ObserveOperation(operation, provider.Type, time.Since(start), *err)
+		}
+	}
+}
+
+// auditCluster copies the cluster specification for the audit log, without the cluster state since it contains secrets.
+func auditCluster(cluster *types.Cluster) *types.Cluster {
+	c := *cluster
+	c.ClusterInfo = nil
+	if cluster.Labels != nil {
+		c.Labels = make(map[string]string, len(cluster.Labels))
+		for k, v := range cluster.Labels {
+			c.Labels[k] = v
+		}
+	}
+	return &c
+}
+
+// auditProvider copies the provider specification for the audit log, without the credentials and secret custom configurations.
+func auditProvider(provider *types.Provider) *types.Provider {
+	p := *provider
+	if provider.Credentials != nil {
+		cr := *provider.Credentials
+		cr.Data = nil
+		p.Credentials = &cr
+	}
+	if provider.CustomConfigurations != nil {
+		p.CustomConfigurations = make(map[string]interface{}, len(provider.CustomConfigurations))
+		for k, v := range provider.CustomConfigurations {
+			if isSecretSetting(k) {
+				v = redacted
+			}
+			p.CustomConfigurations[k] = v
+		}
+	}
+	return &p
+}
+
+func isSecretSetting(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range secretSettings {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -2,15 +2,16 @@ package azure
 
 import (
 	"fmt"
-	"io/ioutil"
 	"regexp"
 
-	"github.com/BurntSushi/toml"
-	"github.com/hashicorp/terraform/states/statefile"
+	"github.com/kyma-incubator/hydroform/provision/internal/credentials"
 	"github.com/kyma-incubator/hydroform/provision/internal/errs"
+	"github.com/kyma-incubator/hydroform/provision/internal/labels"
 	terraform_operator "github.com/kyma-incubator/hydroform/provision/internal/operator/terraform"
 
 	"github.com/kyma-incubator/hydroform/provision/internal/operator"
+	"github.com/kyma-incubator/hydroform/provision/internal/tracing"
+	"github.com/kyma-incubator/hydroform/provision/internal/versions"
 	"github.com/kyma-incubator/hydroform/provision/types"
 	"github.com/pkg/errors"
 )
@@ -18,6 +19,12 @@ import (
 // azureProvisioner implements Provisioner
 type azureProvisioner struct {
 	provisionOperator operator.Operator
+	// newCloud creates the Azure client used by the pre-flight checks.
+	newCloud func(p *credentials.AzurePrincipal) (cloud, error)
+	// versions provides the Kubernetes versions the requested version is resolved against.
+	versions types.VersionSource
+	// tracer traces the validation and the generation of kubeconfigs.
+	tracer *tracing.Tracer
 }
 
 // Provision requests provisioning of a new Kubernetes cluster on Azure with the given configurations.
@@ -26,7 +33,10 @@ func (a *azureProvisioner) Provision(cluster *types.Cluster, provider *types.Pro
 		return cluster, err
 	}
 
-	config := a.loadConfigurations(cluster, provider)
+	config, err := a.loadConfigurations(cluster, provider)
+	if err != nil {
+		return cluster, err
+	}
 
 	clusterInfo, err := a.provisionOperator.Create(provider.Type, config)
 	if err != nil {
@@ -37,51 +47,136 @@ func (a *azureProvisioner) Provision(cluster *types.Cluster, provider *types.Pro
 	return cluster, nil
 }
 
+// Import brings an existing Azure cluster under management, as if it was provisioned with the given configurations.
+// It returns the cluster with its ClusterInfo and the settings in which the existing cluster differs from the configurations.
+func (a *azureProvisioner) Import(cluster *types.Cluster, provider *types.Provider, id string) (*types.Cluster, []types.Difference, error) {
+	if err := a.validateInputs(cluster, provider); err != nil {
+		return cluster, nil, err
+	}
+
+	config, err := a.loadConfigurations(cluster, provider)
+	if err != nil {
+		return cluster, nil, err
+	}
+
+	clusterInfo, diffs, err := a.provisionOperator.Import(provider.Type, config, id)
+	if err != nil {
+		return cluster, nil, errors.Wrap(err, "unable to import azure cluster")
+	}
+
+	cluster.ClusterInfo = clusterInfo
+	return cluster, diffs, nil
+}
+
+// Eject writes a standalone terraform project for the requested cluster into the given directory, so that it can be managed without Hydroform.
+func (a *azureProvisioner) Eject(cluster *types.Cluster, p *types.Provider, dir string) error {
+	if err := a.validateInputs(cluster, p); err != nil {
+		return err
+	}
+
+	config, err := a.loadConfigurations(cluster, p)
+	if err != nil {
+		return err
+	}
+
+	var state *types.InternalState
+	if cluster.ClusterInfo != nil {
+		state = cluster.ClusterInfo.InternalState
+	}
+
+	if err := a.provisionOperator.Eject(state, p.Type, config, dir); err != nil {
+		return errors.Wrap(err, "unable to eject azure cluster")
+	}
+	return nil
+}
+
+// Rollback restores the version of the state of the requested cluster with the given serial and applies the configuration the Azure cluster had at that version.
+func (a *azureProvisioner) Rollback(cluster *types.Cluster, p *types.Provider, serial uint64) (*types.Cluster, error) {
+	if err := a.validateInputs(cluster, p); err != nil {
+		return cluster, err
+	}
+
+	config, err := a.loadConfigurations(cluster, p)
+	if err != nil {
+		return cluster, err
+	}
+
+	clusterInfo, err := a.provisionOperator.Rollback(p.Type, config, serial)
+	if err != nil {
+		return cluster, errors.Wrap(err, "unable to roll back azure cluster")
+	}
+
+	cluster.ClusterInfo = clusterInfo
+	return cluster, nil
+}
+
 // Status returns the ClusterStatus for the requested cluster.
 func (a *azureProvisioner) Status(cluster *types.Cluster, p *types.Provider) (*types.ClusterStatus, error) {
-	var state *statefile.File
-	if cluster.ClusterInfo != nil && cluster.ClusterInfo.InternalState != nil {
-		state = cluster.ClusterInfo.InternalState.TerraformState
+	var state *types.InternalState
+	if cluster.ClusterInfo != nil {
+		state = cluster.ClusterInfo.InternalState
 	}
 
 	if err := a.validateInputs(cluster, p); err != nil {
 		return nil, err
 	}
 
-	cfg := a.loadConfigurations(cluster, p)
+	cfg, err := a.loadConfigurations(cluster, p)
+	if err != nil {
+		return nil, err
+	}
 
 	return a.provisionOperator.Status(state, p.Type, cfg)
 }
 
 // Credentials returns the Kubeconfig file as a byte array for the requested cluster.
-func (a *azureProvisioner) Credentials(cluster *types.Cluster, p *types.Provider) ([]byte, error) {
+func (a *azureProvisioner) Credentials(cluster *types.Cluster, p *types.Provider) (data []byte, err error) {
 	if err := a.validateInputs(cluster, p); err != nil {
 		return nil, err
 	}
-	if cluster.ClusterInfo == nil || cluster.ClusterInfo.InternalState == nil || cluster.ClusterInfo.InternalState.TerraformState == nil {
+
+	_, span := a.tracer.Start("kubeconfig")
+	defer func() { tracing.End(span, err) }()
+
+	if cluster.ClusterInfo == nil {
+		return nil, errors.New(errs.EmptyClusterInfo)
+	}
+	// the state may only be kept encrypted, for example after the cluster was serialized
+	sf, err := a.provisionOperator.State(cluster.ClusterInfo.InternalState)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read the cluster state")
+	}
+	if sf == nil {
 		// TODO add a way to get the kubeconfig from the state file if possible
 		return nil, errors.New(errs.EmptyClusterInfo)
 	}
 
-	kubeconfig := cluster.ClusterInfo.InternalState.TerraformState.State.Modules[""].OutputValues["kube_config"].Value.AsString()
-
-	return []byte(kubeconfig), nil
+	output, ok := sf.State.RootModule().OutputValues["kube_config"]
+	if !ok {
+		return nil, errors.New("the cluster state contains no kubeconfig")
+	}
+	return []byte(output.Value.AsString()), nil
 }
 
 // Deprovision requests deprovisioning of an existing cluster on Azure with the given configurations.
 func (a *azureProvisioner) Deprovision(cluster *types.Cluster, p *types.Provider) error {
+	// the version is not resolved, the cluster is deleted whichever version it runs
+	cluster = versions.Unresolved(cluster)
 	if err := a.validateInputs(cluster, p); err != nil {
 		return err
 	}
 
-	config := a.loadConfigurations(cluster, p)
+	config, err := a.loadConfigurations(cluster, p)
+	if err != nil {
+		return err
+	}
 
-	var state *statefile.File
-	if cluster.ClusterInfo != nil && cluster.ClusterInfo.InternalState != nil {
-		state = cluster.ClusterInfo.InternalState.TerraformState
+	var state *types.InternalState
+	if cluster.ClusterInfo != nil {
+		state = cluster.ClusterInfo.InternalState
 	}
 
-	err := a.provisionOperator.Delete(state, p.Type, config)
+	err = a.provisionOperator.Delete(state, p.Type, config)
 	if err != nil {
 		return errors.Wrap(err, "unable to deprovision azure cluster")
 	}
@@ -108,10 +203,16 @@ func New(operatorType operator.Type, ops ...types.Option) *azureProvisioner {
 
 	return &azureProvisioner{
 		provisionOperator: op,
+		newCloud:          newAzureCloud,
+		versions:          os.VersionSource,
+		tracer:            tracing.New(os),
 	}
 }
 
-func (a *azureProvisioner) validateInputs(cluster *types.Cluster, provider *types.Provider) error {
+func (a *azureProvisioner) validateInputs(cluster *types.Cluster, provider *types.Provider) (err error) {
+	_, span := a.tracer.Start("validate")
+	defer func() { tracing.End(span, err) }()
+
 	var errMessage string
 	if cluster.NodeCount < 1 {
 		errMessage += fmt.Sprintf(errs.CannotBeLess, "Cluster.NodeCount", 1)
@@ -129,64 +230,51 @@ func (a *azureProvisioner) validateInputs(cluster *types.Cluster, provider *type
 	}
 	if cluster.KubernetesVersion == "" {
 		errMessage += fmt.Sprintf(errs.CannotBeEmpty, "Cluster.KubernetesVersion")
+	} else if _, err := versions.ForCluster(a.versions, types.Azure, cluster); err != nil {
+		errMessage += fmt.Sprintf(errs.Custom, err.Error())
 	}
 	if cluster.DiskSizeGB < 0 {
 		errMessage += fmt.Sprintf(errs.CannotBeLess, "Cluster.DiskSizeGB", 0)
 	}
 
-	if provider.CredentialsFilePath == "" {
-		errMessage += fmt.Sprintf(errs.CannotBeEmpty, "Provider.CredentialsFilePath")
+	if _, err := credentials.Azure(provider); err != nil {
+		errMessage += fmt.Sprintf(errs.Custom, err.Error())
 	}
 
+	errMessage += labels.Validate(types.Azure, labels.ForCluster(cluster))
+
 	if errMessage != "" {
-		return errors.New("input validation failed with the following information: " + errMessage)
+		return types.Classify(types.ErrorClassValidation, errors.New("input validation failed with the following information: "+errMessage))
 	}
 
 	return nil
 }
 
-func (a *azureProvisioner) loadConfigurations(cluster *types.Cluster, provider *types.Provider) map[string]interface{} {
+func (a *azureProvisioner) loadConfigurations(cluster *types.Cluster, provider *types.Provider) (map[string]interface{}, error) {
 	config := map[string]interface{}{}
 	config["cluster_name"] = cluster.Name
 	config["agent_count"] = cluster.NodeCount
 	config["agent_vm_size"] = cluster.MachineType
 	config["agent_disk_size"] = cluster.DiskSizeGB
-	config["kubernetes_version"] = cluster.KubernetesVersion
+	// the version is validated already
+	version, _ := versions.ForCluster(a.versions, types.Azure, cluster)
+	config["kubernetes_version"] = version
 	config["location"] = cluster.Location
 	config["project"] = provider.ProjectName
 	config["resource_group"] = provider.ProjectName
-	config["subscription_id"], config["tenant_id"], config["client_id"], config["client_secret"] = azureCredentials(provider.CredentialsFilePath)
-
-	for k, v := range provider.CustomConfigurations {
-		config[k] = v
-	}
-
-	return config
-}
-
-// azureCredentials extracts the values of a credentials file to authenticate on azure.
-// It expects a file following the TOML format (https://github.com/toml-lang/toml#user-content-spec), containing the SUBSCRIPTION_ID, TENANT_ID, CLIENT_ID and CLIENT_SECRET.
-func azureCredentials(path string) (subscriptionID, tenantID, clientID, clientSecret string) {
-	data, err := ioutil.ReadFile(path)
+	principal, err := credentials.Azure(provider)
 	if err != nil {
-		return
+		return nil, err
 	}
+	config["subscription_id"] = principal.SubscriptionID
+	config["tenant_id"] = principal.TenantID
+	config["client_id"] = principal.ClientID
+	config["client_secret"] = operator.Sensitive(principal.ClientSecret)
+	config["labels"] = labels.ForCluster(cluster)
 
-	c := struct {
-		SubscriptionID string `toml:"SUBSCRIPTION_ID"`
-		TenantID       string `toml:"TENANT_ID"`
-		ClientID       string `toml:"CLIENT_ID"`
-		Secret         string `toml:"CLIENT_SECRET"`
-	}{}
-
-	if _, err = toml.Decode(string(data), &c); err != nil {
-		return
+	for k, v := range provider.CustomConfigurations {
+		config[k] = v
 	}
 
-	subscriptionID = c.SubscriptionID
-	tenantID = c.TenantID
-	clientID = c.ClientID
-	clientSecret = c.Secret
-
-	return
+	return config, nil
 }
@@ -1,17 +1,24 @@
 package azure
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"testing"
 
+	"github.com/hashicorp/terraform/addrs"
+	"github.com/hashicorp/terraform/states"
 	"github.com/hashicorp/terraform/states/statefile"
+	"github.com/kyma-incubator/hydroform/provision/internal/encryption"
+	"github.com/kyma-incubator/hydroform/provision/internal/operator"
 	"github.com/kyma-incubator/hydroform/provision/internal/operator/mocks"
 	"github.com/pkg/errors"
 
 	"github.com/kyma-incubator/hydroform/provision/types"
 	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
 )
 
 func TestValidateInputs(t *testing.T) {
@@ -29,7 +36,7 @@ func TestValidateInputs(t *testing.T) {
 	provider := &types.Provider{
 		Type:                types.Azure,
 		ProjectName:         "my-resource-group",
-		CredentialsFilePath: "/path/to/credentials",
+		CredentialsFilePath: "testdata/credentials.toml",
 		CustomConfigurations: map[string]interface{}{
 			"target_provider": "azure",
 			"target_secret":   "secret-name",
@@ -73,6 +80,8 @@ func TestValidateInputs(t *testing.T) {
 	provider.CredentialsFilePath = ""
 	require.Error(t, g.validateInputs(cluster, provider), "Validation should fail when credentials file path is empty")
 	provider.CredentialsFilePath = "/path/to/credentials"
+	require.Error(t, g.validateInputs(cluster, provider), "Validation should fail when the credentials file cannot be read")
+	provider.CredentialsFilePath = "testdata/credentials.toml"
 
 	provider.ProjectName = ""
 	require.Error(t, g.validateInputs(cluster, provider), "Validation should fail when project name is empty")
@@ -120,13 +129,14 @@ func TestLoadConfigurations(t *testing.T) {
 	require.NoError(t, err, "Creating a fake credentials file should not have an error")
 	defer os.Remove(provider.CredentialsFilePath)
 
-	config := g.loadConfigurations(cluster, provider)
+	config, err := g.loadConfigurations(cluster, provider)
+	require.NoError(t, err, "Loading the configurations should succeed")
 
 	require.Equal(t, cluster.Name, config["cluster_name"])
 	require.Equal(t, "fake-subscription-id", config["subscription_id"])
 	require.Equal(t, "fake-tenant-id", config["tenant_id"])
 	require.Equal(t, "fake-client-id", config["client_id"])
-	require.Equal(t, "fake-client-secret", config["client_secret"])
+	require.Equal(t, operator.Sensitive("fake-client-secret"), config["client_secret"])
 	require.Equal(t, cluster.NodeCount, config["agent_count"])
 	require.Equal(t, cluster.MachineType, config["agent_vm_size"])
 	require.Equal(t, cluster.DiskSizeGB, config["agent_disk_size"])
@@ -137,6 +147,10 @@ func TestLoadConfigurations(t *testing.T) {
 	for k, v := range provider.CustomConfigurations {
 		require.Equal(t, v, config[k], fmt.Sprintf("Custom config %s is incorrect", k))
 	}
+
+	provider.CredentialsFilePath = "/path/to/credentials"
+	_, err = g.loadConfigurations(cluster, provider)
+	require.Error(t, err, "Loading the configurations should fail when the credentials cannot be read")
 }
 
 func fakeCredentials(file string) error {
@@ -166,7 +180,7 @@ func TestProvision(t *testing.T) {
 	provider := &types.Provider{
 		Type:                types.Azure,
 		ProjectName:         "my-resource-group",
-		CredentialsFilePath: "/path/to/credentials",
+		CredentialsFilePath: "testdata/credentials.toml",
 		CustomConfigurations: map[string]interface{}{
 			"target_provider": "azure",
 			"target_secret":   "secret-name",
@@ -185,16 +199,21 @@ func TestProvision(t *testing.T) {
 			TerraformState: nil,
 		},
 	}
-	mockOp.On("Create", types.Azure, g.loadConfigurations(cluster, provider)).Return(result, nil)
+	config, err := g.loadConfigurations(cluster, provider)
+	require.NoError(t, err)
+	mockOp.On("Create", types.Azure, config).Return(result, nil)
 
-	cluster, err := g.Provision(cluster, provider)
+	cluster, err = g.Provision(cluster, provider)
 	require.NoError(t, err, "Provision should succeed")
 	require.Equal(t, result, cluster.ClusterInfo, "The cluster info returned from the operator should be in the cluster returned by Provision")
+	require.Empty(t, cluster.ClusterInfo.KubernetesVersion, "The requested Kubernetes version should not be reported as the running one")
 
 	badCluster := &types.Cluster{
 		CPU: 1,
 	}
-	mockOp.On("Create", types.Azure, g.loadConfigurations(badCluster, provider)).Return(badCluster, errors.New("Unable to provision cluster"))
+	config, err = g.loadConfigurations(badCluster, provider)
+	require.NoError(t, err)
+	mockOp.On("Create", types.Azure, config).Return(badCluster, errors.New("Unable to provision cluster"))
 
 	_, err = g.Provision(badCluster, provider)
 	require.Error(t, err, "Provision should fail")
@@ -219,7 +238,7 @@ func TestDeprovision(t *testing.T) {
 	provider := &types.Provider{
 		Type:                types.Azure,
 		ProjectName:         "my-resource-group",
-		CredentialsFilePath: "/path/to/credentials",
+		CredentialsFilePath: "testdata/credentials.toml",
 		CustomConfigurations: map[string]interface{}{
 			"target_provider": "azure",
 			"target_secret":   "secret-name",
@@ -228,15 +247,110 @@ func TestDeprovision(t *testing.T) {
 		},
 	}
 
-	var state *statefile.File
-	mockOp.On("Delete", state, types.Azure, g.loadConfigurations(cluster, provider)).Return(nil)
+	var state *types.InternalState
+	config, err := g.loadConfigurations(cluster, provider)
+	require.NoError(t, err)
+	mockOp.On("Delete", state, types.Azure, config).Return(nil)
 
-	err := g.Deprovision(cluster, provider)
+	err = g.Deprovision(cluster, provider)
 	require.NoError(t, err, "Deprovision should succeed")
 
 	provider.ProjectName = "invalid-resource-group"
-	mockOp.On("Delete", state, types.Azure, g.loadConfigurations(cluster, provider)).Return(errors.New("Unable to deprovision cluster"))
+	config, err = g.loadConfigurations(cluster, provider)
+	require.NoError(t, err)
+	mockOp.On("Delete", state, types.Azure, config).Return(errors.New("Unable to deprovision cluster"))
 
 	err = g.Deprovision(cluster, provider)
 	require.Error(t, err, "Deprovision should fail")
 }
+
+func TestImport(t *testing.T) {
+	mockOp := &mocks.Operator{}
+	g := azureProvisioner{
+		provisionOperator: mockOp,
+	}
+
+	cluster := &types.Cluster{
+		KubernetesVersion: "1.15",
+		Name:              "hydro-cluster",
+		DiskSizeGB:        30,
+		NodeCount:         2,
+		Location:          "europe-west3",
+		MachineType:       "type1",
+	}
+	provider := &types.Provider{
+		Type:                types.Azure,
+		ProjectName:         "my-resource-group",
+		CredentialsFilePath: "testdata/credentials.toml",
+	}
+
+	result := &types.ClusterInfo{
+		Endpoint:          "https://cluster-url.fake",
+		KubernetesVersion: "1.15.7",
+		Status: &types.ClusterStatus{
+			Phase: types.Provisioned,
+		},
+	}
+	diffs := []types.Difference{{Setting: "Cluster.MachineType", Requested: "type1", Actual: "type2"}}
+	config, err := g.loadConfigurations(cluster, provider)
+	require.NoError(t, err)
+	mockOp.On("Import", types.Azure, config, "").Return(result, diffs, nil)
+
+	cluster, d, err := g.Import(cluster, provider, "")
+	require.NoError(t, err, "Import should succeed")
+	require.Equal(t, result, cluster.ClusterInfo, "The cluster info returned from the operator should be in the cluster returned by Import")
+	require.Equal(t, "1.15.7", cluster.ClusterInfo.KubernetesVersion, "The running Kubernetes version should be kept")
+	require.Equal(t, diffs, d, "The differences found by the operator should be returned")
+
+	cluster.Name = ""
+	_, _, err = g.Import(cluster, provider, "")
+	require.Error(t, err, "Import should fail with invalid inputs")
+}
+
+func TestCredentialsEncrypted(t *testing.T) {
+	cluster := &types.Cluster{
+		CPU:               1,
+		KubernetesVersion: "1.12",
+		Name:              "hydro-cluster",
+		DiskSizeGB:        30,
+		NodeCount:         2,
+		Location:          "europe-west3",
+		MachineType:       "type1",
+	}
+	provider := &types.Provider{
+		Type:                types.Azure,
+		ProjectName:         "my-resource-group",
+		CredentialsFilePath: "testdata/credentials.toml",
+		CustomConfigurations: map[string]interface{}{
+			"target_provider": "azure",
+			"target_secret":   "secret-name",
+			"disk_type":       "pd-standard",
+		},
+	}
+	encryptionKey := types.WithEncryptionKey([]byte("my-secret"))
+
+	sf := statefile.New(states.BuildState(func(s *states.SyncState) {
+		s.SetOutputValue(addrs.OutputValue{Name: "kube_config"}.Absolute(addrs.RootModuleInstance), cty.StringVal("my-kubeconfig"), true)
+	}), "lineage", 1)
+	key, err := encryption.LoadKey(&types.EncryptionKey{Value: []byte("my-secret")})
+	require.NoError(t, err)
+	buf := &bytes.Buffer{}
+	require.NoError(t, statefile.Write(sf, buf))
+	enc, err := encryption.Encrypt(key, buf.Bytes())
+	require.NoError(t, err)
+
+	// a persisted cluster only keeps the encrypted state
+	cluster.ClusterInfo = &types.ClusterInfo{InternalState: &types.InternalState{TerraformState: sf, EncryptedTerraformState: enc}}
+	data, err := json.Marshal(cluster)
+	require.NoError(t, err)
+	loaded := &types.Cluster{}
+	require.NoError(t, json.Unmarshal(data, loaded))
+	require.Nil(t, loaded.ClusterInfo.InternalState.TerraformState)
+
+	kubeconfig, err := New(operator.TerraformOperator, encryptionKey).Credentials(loaded, provider)
+	require.NoError(t, err, "The kubeconfig should be read from the encrypted state")
+	require.Equal(t, "my-kubeconfig", string(kubeconfig))
+
+	_, err = New(operator.TerraformOperator).Credentials(loaded, provider)
+	require.Error(t, err, "Reading an encrypted state without key should fail")
+}
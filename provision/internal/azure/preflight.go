@@ -0,0 +1,189 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/authorization/mgmt/2015-07-01/authorization"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2018-10-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2018-05-01/resources"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure/auth"
+	"github.com/kyma-incubator/hydroform/provision/internal/credentials"
+	"github.com/kyma-incubator/hydroform/provision/internal/errs"
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/pkg/errors"
+)
+
+// requiredActions are the actions the service principal needs on the resource group to manage AKS clusters.
+var requiredActions = []string{
+	"Microsoft.ContainerService/managedClusters/read",
+	"Microsoft.ContainerService/managedClusters/write",
+	"Microsoft.ContainerService/managedClusters/delete",
+}
+
+// cloud is the part of the Azure API the pre-flight checks need. It can be stubbed in tests.
+type cloud interface {
+	// ResourceGroupExists checks if the resource group exists in the subscription.
+	ResourceGroupExists(name string) (bool, error)
+	// Permissions returns the allowed and denied actions of the service principal on the resource group.
+	Permissions(resourceGroup string) (actions, notActions []string, err error)
+	// MachineSizes returns the virtual machine sizes available in the location.
+	MachineSizes(location string) ([]string, error)
+}
+
+// Validate runs the input validation and checks against Azure that the cluster can be provisioned.
+func (a *azureProvisioner) Validate(cluster *types.Cluster, provider *types.Provider) error {
+	if err := a.validateInputs(cluster, provider); err != nil {
+		return err
+	}
+
+	principal, _ := credentials.Azure(provider)
+	c, err := a.newCloud(principal)
+	if err != nil {
+		return types.Classify(types.ErrorClassCredentials, errors.Wrap(err, "unable to connect to azure"))
+	}
+	return preflight(c, cluster, provider)
+}
+
+// preflight checks the resource group, the permissions of the service principal, the location and the machine type of the cluster.
+func preflight(c cloud, cluster *types.Cluster, provider *types.Provider) error {
+	var errMessage string
+
+	exists, err := c.ResourceGroupExists(provider.ProjectName)
+	if err != nil {
+		return types.Classify(types.ErrorClassValidation, errors.New("pre-flight checks failed with the following information: "+fmt.Sprintf(errs.Custom, err.Error())))
+	}
+	if !exists {
+		errMessage += fmt.Sprintf(errs.Custom, fmt.Sprintf("resource group %s does not exist", provider.ProjectName))
+	} else {
+		actions, notActions, err := c.Permissions(provider.ProjectName)
+		if err != nil {
+			errMessage += fmt.Sprintf(errs.Custom, err.Error())
+		} else if missing := missingActions(requiredActions, actions, notActions); len(missing) > 0 {
+			errMessage += fmt.Sprintf(errs.Custom, fmt.Sprintf("the service principal is missing the permissions %s on resource group %s", strings.Join(missing, ", "), provider.ProjectName))
+		}
+	}
+
+	sizes, err := c.MachineSizes(cluster.Location)
+	if err != nil {
+		errMessage += fmt.Sprintf(errs.Custom, err.Error())
+	} else if !contains(sizes, cluster.MachineType) {
+		errMessage += fmt.Sprintf(errs.Custom, fmt.Sprintf("machine type %s is not available in location %s", cluster.MachineType, cluster.Location))
+	}
+
+	if errMessage != "" {
+		return types.Classify(types.ErrorClassValidation, errors.New("pre-flight checks failed with the following information: "+errMessage))
+	}
+	return nil
+}
+
+// missingActions returns the required actions not covered by the allowed actions or excluded by the denied ones.
+// Azure actions are case insensitive and can contain wildcards.
+func missingActions(required, actions, notActions []string) []string {
+	var missing []string
+	for _, r := range required {
+		if !matchesAny(actions, r) || matchesAny(notActions, r) {
+			missing = append(missing, r)
+		}
+	}
+	return missing
+}
+
+func matchesAny(patterns []string, action string) bool {
+	for _, p := range patterns {
+		expr := "(?i)^" + strings.Replace(regexp.QuoteMeta(p), `\*`, ".*", -1) + "$"
+		if ok, _ := regexp.MatchString(expr, action); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if strings.EqualFold(e, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// azureCloud is the cloud implementation using the Azure Resource Manager API.
+type azureCloud struct {
+	groups      resources.GroupsClient
+	permissions authorization.PermissionsClient
+	sizes       compute.VirtualMachineSizesClient
+}
+
+// newAzureCloud creates clients for the Azure APIs authenticated as the given service principal.
+func newAzureCloud(p *credentials.AzurePrincipal) (cloud, error) {
+	authorizer, err := auth.NewClientCredentialsConfig(p.ClientID, p.ClientSecret, p.TenantID).Authorizer()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &azureCloud{
+		groups:      resources.NewGroupsClient(p.SubscriptionID),
+		permissions: authorization.NewPermissionsClient(p.SubscriptionID),
+		sizes:       compute.NewVirtualMachineSizesClient(p.SubscriptionID),
+	}
+	c.groups.Authorizer = authorizer
+	c.permissions.Authorizer = authorizer
+	c.sizes.Authorizer = authorizer
+	return c, nil
+}
+
+func (c *azureCloud) ResourceGroupExists(name string) (bool, error) {
+	res, err := c.groups.CheckExistence(context.Background(), name)
+	if err != nil {
+		if res.Response != nil && res.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "could not check resource group %s", name)
+	}
+	return res.StatusCode == http.StatusNoContent, nil
+}
+
+func (c *azureCloud) Permissions(resourceGroup string) (actions, notActions []string, err error) {
+	iter, err := c.permissions.ListForResourceGroupComplete(context.Background(), resourceGroup)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "could not list the permissions on resource group %s", resourceGroup)
+	}
+	for ; iter.NotDone(); err = iter.Next() {
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "could not list the permissions on resource group %s", resourceGroup)
+		}
+		p := iter.Value()
+		if p.Actions != nil {
+			actions = append(actions, *p.Actions...)
+		}
+		if p.NotActions != nil {
+			notActions = append(notActions, *p.NotActions...)
+		}
+	}
+	return actions, notActions, nil
+}
+
+func (c *azureCloud) MachineSizes(location string) ([]string, error) {
+	res, err := c.sizes.List(context.Background(), location)
+	if err != nil {
+		if dErr, ok := err.(autorest.DetailedError); ok && dErr.StatusCode == http.StatusNotFound {
+			return nil, errors.Errorf("location %s does not exist", location)
+		}
+		return nil, errors.Wrapf(err, "could not list the machine sizes of location %s", location)
+	}
+
+	var sizes []string
+	if res.Value != nil {
+		for _, s := range *res.Value {
+			if s.Name != nil {
+				sizes = append(sizes, *s.Name)
+			}
+		}
+	}
+	return sizes, nil
+}
@@ -0,0 +1,69 @@
+package azure
+
+import (
+	"testing"
+
+	"github.com/kyma-incubator/hydroform/provision/internal/credentials"
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCloud is an Azure subscription with a single resource group and location.
+type fakeCloud struct {
+	actions, notActions []string
+}
+
+func (f *fakeCloud) ResourceGroupExists(name string) (bool, error) {
+	return name == "my-resource-group", nil
+}
+
+func (f *fakeCloud) Permissions(resourceGroup string) ([]string, []string, error) {
+	return f.actions, f.notActions, nil
+}
+
+func (f *fakeCloud) MachineSizes(location string) ([]string, error) {
+	if location != "westeurope" {
+		return nil, nil
+	}
+	return []string{"Standard_D2_v3", "Standard_D4_v3"}, nil
+}
+
+func TestValidatePreflight(t *testing.T) {
+	c := &fakeCloud{actions: []string{"*"}}
+	a := azureProvisioner{
+		newCloud: func(p *credentials.AzurePrincipal) (cloud, error) {
+			require.Equal(t, "fake-subscription-id", p.SubscriptionID, "The cloud should be created with the service principal")
+			return c, nil
+		},
+	}
+
+	cluster := &types.Cluster{
+		KubernetesVersion: "1.15",
+		Name:              "hydro-cluster",
+		DiskSizeGB:        30,
+		NodeCount:         2,
+		Location:          "westeurope",
+		MachineType:       "standard_d2_v3",
+	}
+	provider := &types.Provider{
+		Type:                types.Azure,
+		ProjectName:         "my-resource-group",
+		CredentialsFilePath: "testdata/credentials.toml",
+	}
+	require.NoError(t, a.Validate(cluster, provider), "Pre-flight checks should pass")
+
+	cluster.MachineType = "Standard_M128"
+	require.Error(t, a.Validate(cluster, provider), "Unavailable machine types should be reported")
+	cluster.MachineType = "Standard_D2_v3"
+
+	provider.ProjectName = "other-group"
+	require.Error(t, a.Validate(cluster, provider), "A missing resource group should be reported")
+	provider.ProjectName = "my-resource-group"
+
+	c.actions = []string{"Microsoft.ContainerService/*/read"}
+	require.Error(t, a.Validate(cluster, provider), "Missing permissions should be reported")
+	c.actions = []string{"microsoft.containerservice/*"}
+	require.NoError(t, a.Validate(cluster, provider), "Actions should match case insensitive with wildcards")
+	c.notActions = []string{"Microsoft.ContainerService/managedClusters/delete"}
+	require.Error(t, a.Validate(cluster, provider), "Denied actions should be reported")
+}
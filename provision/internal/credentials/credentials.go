@@ -0,0 +1,204 @@
+package credentials
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// AzurePrincipal contains the service principal used to authenticate on Azure.
+type AzurePrincipal struct {
+	SubscriptionID string
+	TenantID       string
+	ClientID       string
+	ClientSecret   string
+}
+
+// GCP returns the service account key to access GCP.
+// It returns an error if the credentials are missing or are not a service account key.
+func GCP(p *types.Provider) ([]byte, error) {
+	data, err := load(p, func() ([]byte, error) {
+		if v := os.Getenv("GOOGLE_CREDENTIALS"); v != "" {
+			return []byte(v), nil
+		}
+		if path := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"); path != "" {
+			return readFile(path)
+		}
+		return nil, errors.New("neither GOOGLE_CREDENTIALS nor GOOGLE_APPLICATION_CREDENTIALS is set")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	key := struct {
+		Type        string `json:"type"`
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+	}{}
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, errors.Wrap(err, "GCP credentials are not a valid service account key")
+	}
+	if key.Type != "service_account" {
+		return nil, errors.Errorf("GCP credentials must be a service account key, found type %q", key.Type)
+	}
+	var missing []string
+	if key.ClientEmail == "" {
+		missing = append(missing, "client_email")
+	}
+	if key.PrivateKey == "" {
+		missing = append(missing, "private_key")
+	}
+	if len(missing) > 0 {
+		return nil, errors.Errorf("GCP service account key is missing %s", strings.Join(missing, ", "))
+	}
+	return data, nil
+}
+
+// Gardener returns the kubeconfig of the Gardener service account.
+// It returns an error if the credentials are missing or are not a usable kubeconfig.
+func Gardener(p *types.Provider) ([]byte, error) {
+	data, err := load(p, func() ([]byte, error) {
+		path := os.Getenv("KUBECONFIG")
+		if path == "" {
+			return nil, errors.New("KUBECONFIG is not set")
+		}
+		return readFile(path)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := clientcmd.RESTConfigFromKubeConfig(data); err != nil {
+		return nil, errors.Wrap(err, "Gardener credentials are not a valid kubeconfig")
+	}
+	return data, nil
+}
+
+// Existing returns the kubeconfig of a cluster created outside of Hydroform.
+// With the environment as source, the first file in KUBECONFIG is read, or the default kubeconfig if KUBECONFIG is not set.
+// It returns an error if the credentials are missing or are not a usable kubeconfig.
+func Existing(p *types.Provider) ([]byte, error) {
+	data, err := load(p, func() ([]byte, error) {
+		path := clientcmd.RecommendedHomeFile
+		if paths := filepath.SplitList(os.Getenv("KUBECONFIG")); len(paths) > 0 {
+			path = paths[0]
+		}
+		return readFile(path)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := clientcmd.Load(data); err != nil {
+		return nil, errors.Wrap(err, "credentials of the existing cluster are not a valid kubeconfig")
+	}
+	return data, nil
+}
+
+// Azure returns the service principal to access Azure.
+// The credentials can either be a TOML file with the keys SUBSCRIPTION_ID, TENANT_ID, CLIENT_ID and CLIENT_SECRET, or an SDK auth file.
+// It returns an error if the credentials are missing or incomplete.
+func Azure(p *types.Provider) (*AzurePrincipal, error) {
+	var c *AzurePrincipal
+	if src := p.Credentials; src != nil && len(src.Data) == 0 && src.File == "" && src.Env {
+		c = &AzurePrincipal{
+			SubscriptionID: os.Getenv("ARM_SUBSCRIPTION_ID"),
+			TenantID:       os.Getenv("ARM_TENANT_ID"),
+			ClientID:       os.Getenv("ARM_CLIENT_ID"),
+			ClientSecret:   os.Getenv("ARM_CLIENT_SECRET"),
+		}
+	} else {
+		data, err := load(p, nil)
+		if err != nil {
+			return nil, err
+		}
+		if c, err = parseAzure(data); err != nil {
+			return nil, err
+		}
+	}
+
+	var missing []string
+	if c.SubscriptionID == "" {
+		missing = append(missing, "subscription ID")
+	}
+	if c.TenantID == "" {
+		missing = append(missing, "tenant ID")
+	}
+	if c.ClientID == "" {
+		missing = append(missing, "client ID")
+	}
+	if c.ClientSecret == "" {
+		missing = append(missing, "client secret")
+	}
+	if len(missing) > 0 {
+		return nil, errors.Errorf("Azure credentials are missing the %s", strings.Join(missing, ", "))
+	}
+	return c, nil
+}
+
+// parseAzure reads an SDK auth file if the data is JSON and a TOML file otherwise.
+func parseAzure(data []byte) (*AzurePrincipal, error) {
+	if json.Valid(data) {
+		sdk := struct {
+			SubscriptionID string `json:"subscriptionId"`
+			TenantID       string `json:"tenantId"`
+			ClientID       string `json:"clientId"`
+			ClientSecret   string `json:"clientSecret"`
+		}{}
+		if err := json.Unmarshal(data, &sdk); err != nil {
+			return nil, errors.Wrap(err, "Azure credentials are not a valid SDK auth file")
+		}
+		return &AzurePrincipal{SubscriptionID: sdk.SubscriptionID, TenantID: sdk.TenantID, ClientID: sdk.ClientID, ClientSecret: sdk.ClientSecret}, nil
+	}
+
+	c := struct {
+		SubscriptionID string `toml:"SUBSCRIPTION_ID"`
+		TenantID       string `toml:"TENANT_ID"`
+		ClientID       string `toml:"CLIENT_ID"`
+		Secret         string `toml:"CLIENT_SECRET"`
+	}{}
+	if _, err := toml.Decode(string(data), &c); err != nil {
+		return nil, errors.Wrap(err, "Azure credentials are neither a valid TOML file nor an SDK auth file")
+	}
+	return &AzurePrincipal{SubscriptionID: c.SubscriptionID, TenantID: c.TenantID, ClientID: c.ClientID, ClientSecret: c.Secret}, nil
+}
+
+// load reads the raw credentials from the source configured in the provider.
+// fromEnv reads the credentials from the environment variables of the provider, it is only called if the provider asks for it.
+func load(p *types.Provider, fromEnv func() ([]byte, error)) ([]byte, error) {
+	c := p.Credentials
+	if c == nil {
+		if p.CredentialsFilePath == "" {
+			return nil, errors.New("Provider.CredentialsFilePath or Provider.Credentials must be set")
+		}
+		return readFile(p.CredentialsFilePath)
+	}
+
+	switch {
+	case len(c.Data) > 0:
+		return c.Data, nil
+	case c.File != "":
+		return readFile(c.File)
+	case c.Env && fromEnv != nil:
+		return fromEnv()
+	}
+	return nil, errors.New("Provider.Credentials must have data, a file or the environment as source")
+}
+
+func readFile(path string) ([]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read the credentials file")
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return nil, errors.Errorf("credentials file %s is empty", path)
+	}
+	return data, nil
+}
@@ -0,0 +1,134 @@
+package credentials
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	serviceAccount = `{"type": "service_account", "client_email": "hydroform@my-project.iam.gserviceaccount.com", "private_key": "fake"}`
+	kubeconfig     = `apiVersion: v1
+kind: Config
+current-context: garden
+clusters:
+- name: garden
+  cluster:
+    server: https://gardener.fake
+contexts:
+- name: garden
+  context:
+    cluster: garden
+    user: robot
+users:
+- name: robot
+  user:
+    token: fake-token
+`
+)
+
+func TestGCP(t *testing.T) {
+	key, err := GCP(&types.Provider{Credentials: &types.Credentials{Data: []byte(serviceAccount)}})
+	require.NoError(t, err)
+	require.Equal(t, serviceAccount, string(key))
+
+	_, err = GCP(&types.Provider{Credentials: &types.Credentials{Data: []byte(`{"type": "authorized_user"}`)}})
+	require.Error(t, err, "Only service account keys should be accepted")
+	_, err = GCP(&types.Provider{Credentials: &types.Credentials{Data: []byte(`{"type": "service_account"}`)}})
+	require.EqualError(t, err, "GCP service account key is missing client_email, private_key")
+	_, err = GCP(&types.Provider{Credentials: &types.Credentials{Data: []byte("not json")}})
+	require.Error(t, err, "Invalid JSON should be rejected")
+
+	defer os.Setenv("GOOGLE_CREDENTIALS", os.Getenv("GOOGLE_CREDENTIALS"))
+	os.Setenv("GOOGLE_CREDENTIALS", serviceAccount)
+	key, err = GCP(&types.Provider{Credentials: &types.Credentials{Env: true}})
+	require.NoError(t, err)
+	require.Equal(t, serviceAccount, string(key))
+}
+
+func TestGardener(t *testing.T) {
+	f, err := ioutil.TempFile("", "kubeconfig")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(kubeconfig)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	data, err := Gardener(&types.Provider{CredentialsFilePath: f.Name()})
+	require.NoError(t, err, "The credentials file path should still be supported")
+	require.Equal(t, kubeconfig, string(data))
+
+	_, err = Gardener(&types.Provider{Credentials: &types.Credentials{File: f.Name()}})
+	require.NoError(t, err)
+
+	_, err = Gardener(&types.Provider{Credentials: &types.Credentials{Data: []byte("kind: Config")}})
+	require.Error(t, err, "A kubeconfig without cluster should be rejected")
+	_, err = Gardener(&types.Provider{CredentialsFilePath: "/does/not/exist"})
+	require.Error(t, err, "A missing file should be reported")
+}
+
+func TestExisting(t *testing.T) {
+	data, err := Existing(&types.Provider{Credentials: &types.Credentials{Data: []byte(kubeconfig)}})
+	require.NoError(t, err)
+	require.Equal(t, kubeconfig, string(data))
+
+	_, err = Existing(&types.Provider{Credentials: &types.Credentials{Data: []byte("not: [a kubeconfig")}})
+	require.Error(t, err, "An invalid kubeconfig should be rejected")
+
+	f, err := ioutil.TempFile("", "kubeconfig")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(kubeconfig)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	defer os.Setenv("KUBECONFIG", os.Getenv("KUBECONFIG"))
+	os.Setenv("KUBECONFIG", f.Name()+string(os.PathListSeparator)+"/does/not/exist")
+	data, err = Existing(&types.Provider{Credentials: &types.Credentials{Env: true}})
+	require.NoError(t, err, "The first file in KUBECONFIG should be read")
+	require.Equal(t, kubeconfig, string(data))
+}
+
+func TestAzure(t *testing.T) {
+	expected := &AzurePrincipal{SubscriptionID: "subscription", TenantID: "tenant", ClientID: "client", ClientSecret: "secret"}
+
+	toml := `SUBSCRIPTION_ID = "subscription"
+TENANT_ID = "tenant"
+CLIENT_ID = "client"
+CLIENT_SECRET = "secret"`
+	c, err := Azure(&types.Provider{Credentials: &types.Credentials{Data: []byte(toml)}})
+	require.NoError(t, err)
+	require.Equal(t, expected, c)
+
+	sdkAuth := `{"clientId": "client", "clientSecret": "secret", "subscriptionId": "subscription", "tenantId": "tenant", "activeDirectoryEndpointUrl": "https://login.microsoftonline.com"}`
+	c, err = Azure(&types.Provider{Credentials: &types.Credentials{Data: []byte(sdkAuth)}})
+	require.NoError(t, err)
+	require.Equal(t, expected, c)
+
+	_, err = Azure(&types.Provider{Credentials: &types.Credentials{Data: []byte(`CLIENT_ID = "client"`)}})
+	require.EqualError(t, err, "Azure credentials are missing the subscription ID, tenant ID, client secret")
+	_, err = Azure(&types.Provider{Credentials: &types.Credentials{Data: []byte("not = toml = at all")}})
+	require.Error(t, err, "Invalid credentials should be rejected")
+
+	for k, v := range map[string]string{"ARM_SUBSCRIPTION_ID": "subscription", "ARM_TENANT_ID": "tenant", "ARM_CLIENT_ID": "client", "ARM_CLIENT_SECRET": "secret"} {
+		defer os.Setenv(k, os.Getenv(k))
+		os.Setenv(k, v)
+	}
+	c, err = Azure(&types.Provider{Credentials: &types.Credentials{Env: true}})
+	require.NoError(t, err)
+	require.Equal(t, expected, c)
+}
+
+func TestLoad(t *testing.T) {
+	_, err := load(&types.Provider{}, nil)
+	require.Error(t, err, "Credentials without source should be rejected")
+	_, err = load(&types.Provider{Credentials: &types.Credentials{}}, nil)
+	require.Error(t, err, "Credentials without source should be rejected")
+
+	data, err := load(&types.Provider{CredentialsFilePath: "/ignored", Credentials: &types.Credentials{Data: []byte("data")}}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "data", string(data), "Credentials should take precedence over the file path")
+}
@@ -0,0 +1,91 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/pkg/errors"
+)
+
+// LoadKey reads the key from the source defined in k and derives an AES-256 key from it.
+// If k is nil, no key is returned and encryption is disabled.
+func LoadKey(k *types.EncryptionKey) ([]byte, error) {
+	if k == nil {
+		return nil, nil
+	}
+
+	var raw []byte
+	switch {
+	case len(k.Value) > 0:
+		raw = k.Value
+	case k.File != "":
+		data, err := ioutil.ReadFile(k.File)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read encryption key file")
+		}
+		raw = bytes.TrimSpace(data)
+	case k.Env != "":
+		v, ok := os.LookupEnv(k.Env)
+		if !ok {
+			return nil, errors.Errorf("encryption key environment variable %s is not set", k.Env)
+		}
+		raw = []byte(v)
+	}
+
+	if len(raw) == 0 {
+		return nil, errors.New("encryption key cannot be empty")
+	}
+
+	key := sha256.Sum256(raw)
+	return key[:], nil
+}
+
+// Encrypt encrypts the data with the given key using AES-GCM.
+// The random nonce is prepended to the returned cipher text.
+func Encrypt(key, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, errors.Wrap(err, "could not generate nonce")
+	}
+
+	return gcm.Seal(nonce, nonce, data, nil), nil
+}
+
+// Decrypt decrypts data previously encrypted with Encrypt using the same key.
+func Decrypt(key, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("encrypted data is too short")
+	}
+
+	nonce, cipherText := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, cipherText, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decrypt data, the encryption key might be wrong")
+	}
+	return plain, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid encryption key")
+	}
+	return cipher.NewGCM(block)
+}
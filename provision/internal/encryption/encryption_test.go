@@ -0,0 +1,63 @@
+package encryption
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadKey(t *testing.T) {
+	key, err := LoadKey(nil)
+	require.NoError(t, err)
+	require.Nil(t, key, "No key source should disable encryption")
+
+	key, err = LoadKey(&types.EncryptionKey{Value: []byte("my-secret")})
+	require.NoError(t, err)
+	require.Len(t, key, 32, "An AES-256 key should be derived")
+
+	f, err := ioutil.TempFile("", "hf-key")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString("my-secret\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	fileKey, err := LoadKey(&types.EncryptionKey{File: f.Name()})
+	require.NoError(t, err)
+	require.Equal(t, key, fileKey, "Keys from a file should ignore surrounding whitespace")
+
+	os.Setenv("HF_TEST_KEY", "my-secret")
+	defer os.Unsetenv("HF_TEST_KEY")
+	envKey, err := LoadKey(&types.EncryptionKey{Env: "HF_TEST_KEY"})
+	require.NoError(t, err)
+	require.Equal(t, key, envKey)
+
+	_, err = LoadKey(&types.EncryptionKey{Env: "HF_TEST_KEY_MISSING"})
+	require.Error(t, err, "Missing environment variables should fail")
+	_, err = LoadKey(&types.EncryptionKey{})
+	require.Error(t, err, "Empty keys should fail")
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	key, err := LoadKey(&types.EncryptionKey{Value: []byte("my-secret")})
+	require.NoError(t, err)
+
+	enc, err := Encrypt(key, []byte("cluster state"))
+	require.NoError(t, err)
+	require.NotContains(t, string(enc), "cluster state")
+
+	dec, err := Decrypt(key, enc)
+	require.NoError(t, err)
+	require.Equal(t, "cluster state", string(dec))
+
+	wrongKey, err := LoadKey(&types.EncryptionKey{Value: []byte("wrong-secret")})
+	require.NoError(t, err)
+	_, err = Decrypt(wrongKey, enc)
+	require.Error(t, err, "Decrypting with the wrong key should fail")
+
+	_, err = Decrypt(key, []byte("short"))
+	require.Error(t, err, "Decrypting truncated data should fail")
+}
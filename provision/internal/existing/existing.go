@@ -0,0 +1,231 @@
+package existing
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kyma-incubator/hydroform/provision/internal/credentials"
+	"github.com/kyma-incubator/hydroform/provision/internal/errs"
+	"github.com/kyma-incubator/hydroform/provision/internal/tracing"
+	"github.com/kyma-incubator/hydroform/provision/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// conditionAPIServer reports whether the API server of the cluster answers.
+	conditionAPIServer = "APIServerAvailable"
+	// conditionNodes reports whether all nodes of the cluster are ready.
+	conditionNodes = "EveryNodeReady"
+)
+
+// existingProvisioner implements Provisioner for clusters created outside of Hydroform, such as minikube, k3s or on-premise clusters.
+// It never creates or deletes anything, it only reaches the cluster with the kubeconfig given as credentials.
+type existingProvisioner struct {
+	// newClient creates the client to reach the cluster, replaced in tests.
+	newClient func(config *rest.Config) (kubernetes.Interface, error)
+	// tracer traces the validation.
+	tracer *tracing.Tracer
+}
+
+// Provision checks that the existing cluster can be reached with the given kubeconfig and returns it with the information read from the cluster.
+func (e *existingProvisioner) Provision(cluster *types.Cluster, p *types.Provider) (*types.Cluster, error) {
+	if err := e.validateInputs(cluster, p); err != nil {
+		return nil, err
+	}
+
+	k8s, restConfig, err := e.client(p)
+	if err != nil {
+		return cluster, err
+	}
+
+	version, err := k8s.Discovery().ServerVersion()
+	if err != nil {
+		return cluster, errors.Wrap(err, "unable to connect to the existing cluster")
+	}
+
+	cluster.ClusterInfo = &types.ClusterInfo{
+		Endpoint:                 strings.TrimPrefix(restConfig.Host, "https://"),
+		CertificateAuthorityData: restConfig.CAData,
+		KubernetesVersion:        strings.TrimPrefix(version.GitVersion, "v"),
+		Status:                   clusterStatus(k8s),
+	}
+	return cluster, nil
+}
+
+// Status returns the ClusterStatus of the existing cluster as reported by its API server and nodes.
+// A cluster whose API server cannot be reached has the Unknown phase, a cluster with nodes which are not ready the Errored phase.
+func (e *existingProvisioner) Status(cluster *types.Cluster, p *types.Provider) (*types.ClusterStatus, error) {
+	if err := e.validateInputs(cluster, p); err != nil {
+		return nil, err
+	}
+
+	k8s, _, err := e.client(p)
+	if err != nil {
+		return nil, err
+	}
+	return clusterStatus(k8s), nil
+}
+
+// Credentials returns the kubeconfig of the existing cluster as a byte array.
+// It only contains the context selected with the context custom configuration, or the current context otherwise, and has all certificates and keys inlined.
+func (e *existingProvisioner) Credentials(cluster *types.Cluster, p *types.Provider) ([]byte, error) {
+	if err := e.validateInputs(cluster, p); err != nil {
+		return nil, err
+	}
+
+	config, err := loadKubeconfig(p)
+	if err != nil {
+		return nil, err
+	}
+	return clientcmd.Write(*config)
+}
+
+// Deprovision does nothing but validating the inputs, since Hydroform did not create the existing cluster and must not delete it.
+func (e *existingProvisioner) Deprovision(cluster *types.Cluster, p *types.Provider) error {
+	return e.validateInputs(cluster, p)
+}
+
+// Validate runs the input validation and checks that the API server of the existing cluster answers.
+func (e *existingProvisioner) Validate(cluster *types.Cluster, p *types.Provider) error {
+	if err := e.validateInputs(cluster, p); err != nil {
+		return err
+	}
+
+	k8s, _, err := e.client(p)
+	if err != nil {
+		return err
+	}
+	if _, err := k8s.Discovery().ServerVersion(); err != nil {
+		return errors.Wrap(err, "unable to connect to the existing cluster")
+	}
+	return nil
+}
+
+// New creates a new instance of existingProvisioner.
+func New(ops ...types.Option) *existingProvisioner {
+	// parse config
+	os := &types.Options{}
+	for _, o := range ops {
+		o(os)
+	}
+
+	return &existingProvisioner{
+		newClient: func(config *rest.Config) (kubernetes.Interface, error) {
+			return kubernetes.NewForConfig(config)
+		},
+		tracer: tracing.New(os),
+	}
+}
+
+func (e *existingProvisioner) validateInputs(cluster *types.Cluster, provider *types.Provider) (err error) {
+	_, span := e.tracer.Start("validate")
+	defer func() { tracing.End(span, err) }()
+
+	var errMessage string
+	if cluster.Name == "" {
+		errMessage += fmt.Sprintf(errs.CannotBeEmpty, "Cluster.Name")
+	}
+	// Reap would only forget an expired existing cluster, since Deprovision leaves it untouched
+	if cluster.TTL > 0 || !cluster.ExpiresAt.IsZero() {
+		errMessage += fmt.Sprintf(errs.Custom, "Cluster.TTL and Cluster.ExpiresAt cannot be set for existing clusters, Hydroform does not delete them")
+	}
+	if _, err := loadKubeconfig(provider); err != nil {
+		errMessage += fmt.Sprintf(errs.Custom, err.Error())
+	}
+
+	if errMessage != "" {
+		return types.Classify(types.ErrorClassValidation, errors.New("input validation failed with the following information: "+errMessage))
+	}
+
+	return nil
+}
+
+// client creates a kubernetes client for the existing cluster and returns it with the configuration it uses.
+func (e *existingProvisioner) client(p *types.Provider) (kubernetes.Interface, *rest.Config, error) {
+	config, err := loadKubeconfig(p)
+	if err != nil {
+		return nil, nil, err
+	}
+	restConfig, err := clientcmd.NewDefaultClientConfig(*config, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "unable to read the kubeconfig of the existing cluster")
+	}
+	k8s, err := e.newClient(restConfig)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "unable to create a client for the existing cluster")
+	}
+	return k8s, restConfig, nil
+}
+
+// loadKubeconfig reads the kubeconfig given as credentials and reduces it to the context of the existing cluster.
+// The context is taken from the context custom configuration if set, and is the current context of the kubeconfig otherwise.
+func loadKubeconfig(p *types.Provider) (*api.Config, error) {
+	data, err := credentials.Existing(p)
+	if err != nil {
+		return nil, err
+	}
+	config, err := clientcmd.Load(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read the kubeconfig of the existing cluster")
+	}
+
+	if c, ok := p.CustomConfigurations["context"]; ok {
+		name, _ := c.(string)
+		if _, ok := config.Contexts[name]; !ok {
+			return nil, errors.Errorf("context %q not found in the kubeconfig of the existing cluster", name)
+		}
+		config.CurrentContext = name
+	}
+
+	// drop all other clusters and inline certificate files, so that the kubeconfig can be used anywhere
+	if err := api.MinifyConfig(config); err != nil {
+		return nil, errors.Wrap(err, "unable to select the context of the existing cluster")
+	}
+	if err := api.FlattenConfig(config); err != nil {
+		return nil, errors.Wrap(err, "unable to read the certificates of the existing cluster")
+	}
+	return config, nil
+}
+
+// clusterStatus checks the API server and the nodes of the cluster.
+func clusterStatus(k8s kubernetes.Interface) *types.ClusterStatus {
+	status := &types.ClusterStatus{Phase: types.Provisioned}
+
+	if _, err := k8s.Discovery().ServerVersion(); err != nil {
+		status.Phase = types.Unknown
+		status.Conditions = append(status.Conditions, types.Condition{Type: conditionAPIServer, Status: "False", Reason: "Unreachable", Message: err.Error()})
+		return status
+	}
+	status.Conditions = append(status.Conditions, types.Condition{Type: conditionAPIServer, Status: "True"})
+
+	nodes, err := k8s.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		status.Phase = types.Unknown
+		status.Conditions = append(status.Conditions, types.Condition{Type: conditionNodes, Status: "Unknown", Reason: "ListFailed", Message: err.Error()})
+		return status
+	}
+
+	ready := 0
+	for _, n := range nodes.Items {
+		for _, c := range n.Status.Conditions {
+			if c.Type == corev1.NodeReady && c.Status == corev1.ConditionTrue {
+				ready++
+			}
+		}
+	}
+	msg := fmt.Sprintf("%d of %d nodes ready", ready, len(nodes.Items))
+	if len(nodes.Items) == 0 || ready < len(nodes.Items) {
+		status.Phase = types.Errored
+		status.Conditions = append(status.Conditions, types.Condition{Type: conditionNodes, Status: "False", Reason: "NodesNotReady", Message: msg})
+		return status
+	}
+	status.Conditions = append(status.Conditions, types.Condition{Type: conditionNodes, Status: "True", Message: msg})
+	return status
+}
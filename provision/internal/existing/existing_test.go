@@ -0,0 +1,149 @@
+package existing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const kubeconfig = `apiVersion: v1
+kind: Config
+current-context: minikube
+clusters:
+- name: minikube
+  cluster:
+    server: https://192.168.99.100:8443
+    certificate-authority-data: Y2VydA==
+- name: unreachable
+  cluster:
+    server: https://127.0.0.1:1
+contexts:
+- name: minikube
+  context:
+    cluster: minikube
+    user: minikube
+- name: unreachable
+  context:
+    cluster: unreachable
+    user: minikube
+users:
+- name: minikube
+  user:
+    token: fake-token
+`
+
+func TestValidateInputs(t *testing.T) {
+	e := New()
+
+	cluster := &types.Cluster{Name: "minikube"}
+	provider := &types.Provider{
+		Type:        types.Existing,
+		Credentials: &types.Credentials{Data: []byte(kubeconfig)},
+	}
+
+	require.NoError(t, e.validateInputs(cluster, provider), "Validation should pass")
+
+	cluster.Name = ""
+	require.Error(t, e.validateInputs(cluster, provider), "Validation should fail when cluster name is empty")
+	cluster.Name = "minikube"
+
+	cluster.TTL = time.Hour
+	require.Error(t, e.validateInputs(cluster, provider), "Validation should fail when the cluster has a TTL")
+	cluster.TTL = 0
+	cluster.ExpiresAt = time.Now()
+	require.Error(t, e.validateInputs(cluster, provider), "Validation should fail when the cluster expires")
+	cluster.ExpiresAt = time.Time{}
+
+	provider.CustomConfigurations = map[string]interface{}{"context": "unknown"}
+	require.Error(t, e.validateInputs(cluster, provider), "Validation should fail when the context is not in the kubeconfig")
+	provider.CustomConfigurations = nil
+
+	provider.Credentials = nil
+	require.Error(t, e.validateInputs(cluster, provider), "Validation should fail without kubeconfig")
+}
+
+func TestProvision(t *testing.T) {
+	k8s := fake.NewSimpleClientset(node("node-1", corev1.ConditionTrue))
+	k8s.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: "v1.16.3"}
+	e := &existingProvisioner{
+		newClient: func(config *rest.Config) (kubernetes.Interface, error) { return k8s, nil },
+	}
+
+	cluster := &types.Cluster{Name: "minikube"}
+	provider := &types.Provider{Type: types.Existing, Credentials: &types.Credentials{Data: []byte(kubeconfig)}}
+
+	cl, err := e.Provision(cluster, provider)
+	require.NoError(t, err)
+	require.Equal(t, "192.168.99.100:8443", cl.ClusterInfo.Endpoint)
+	require.Equal(t, []byte("cert"), cl.ClusterInfo.CertificateAuthorityData)
+	require.Equal(t, "1.16.3", cl.ClusterInfo.KubernetesVersion)
+	require.Equal(t, types.Provisioned, cl.ClusterInfo.Status.Phase)
+	require.Nil(t, cl.ClusterInfo.InternalState, "Nothing should be kept for a cluster Hydroform does not manage")
+
+	require.NoError(t, e.Deprovision(cl, provider))
+	nodes, err := k8s.CoreV1().Nodes().List(metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, nodes.Items, 1, "Deprovisioning should not touch the cluster")
+}
+
+func TestStatus(t *testing.T) {
+	k8s := fake.NewSimpleClientset(node("node-1", corev1.ConditionTrue), node("node-2", corev1.ConditionFalse))
+	e := &existingProvisioner{
+		newClient: func(config *rest.Config) (kubernetes.Interface, error) { return k8s, nil },
+	}
+	cluster := &types.Cluster{Name: "minikube"}
+	provider := &types.Provider{Type: types.Existing, Credentials: &types.Credentials{Data: []byte(kubeconfig)}}
+
+	status, err := e.Status(cluster, provider)
+	require.NoError(t, err)
+	require.Equal(t, types.Errored, status.Phase, "A cluster with nodes which are not ready should be errored")
+	require.Equal(t, []types.Condition{
+		{Type: conditionAPIServer, Status: "True"},
+		{Type: conditionNodes, Status: "False", Reason: "NodesNotReady", Message: "1 of 2 nodes ready"},
+	}, status.Conditions)
+
+	// a real client which cannot reach the API server
+	provider.CustomConfigurations = map[string]interface{}{"context": "unreachable"}
+	status, err = New().Status(cluster, provider)
+	require.NoError(t, err)
+	require.Equal(t, types.Unknown, status.Phase, "The status of an unreachable cluster should be unknown")
+	require.Equal(t, "False", status.Conditions[0].Status)
+}
+
+func TestCredentials(t *testing.T) {
+	e := New()
+	cluster := &types.Cluster{Name: "minikube"}
+	provider := &types.Provider{
+		Type:                 types.Existing,
+		Credentials:          &types.Credentials{Data: []byte(kubeconfig)},
+		CustomConfigurations: map[string]interface{}{"context": "unreachable"},
+	}
+
+	data, err := e.Credentials(cluster, provider)
+	require.NoError(t, err)
+	config, err := clientcmd.Load(data)
+	require.NoError(t, err)
+	require.Equal(t, "unreachable", config.CurrentContext, "The configured context should be selected")
+	require.Len(t, config.Clusters, 1, "Other clusters should be dropped")
+	require.Equal(t, "https://127.0.0.1:1", config.Clusters["unreachable"].Server)
+	require.Equal(t, "fake-token", config.AuthInfos["minikube"].Token)
+}
+
+func node(name string, ready corev1.ConditionStatus) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}},
+		},
+	}
+}
@@ -4,13 +4,16 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
-	"k8s.io/client-go/tools/clientcmd"
-
-	"github.com/hashicorp/terraform/states/statefile"
+	"github.com/kyma-incubator/hydroform/provision/internal/credentials"
 	"github.com/kyma-incubator/hydroform/provision/internal/errs"
+	"github.com/kyma-incubator/hydroform/provision/internal/labels"
 	"github.com/kyma-incubator/hydroform/provision/internal/operator"
 	terraform_operator "github.com/kyma-incubator/hydroform/provision/internal/operator/terraform"
+	"github.com/kyma-incubator/hydroform/provision/internal/tracing"
+	"github.com/kyma-incubator/hydroform/provision/internal/versions"
 	"github.com/kyma-incubator/hydroform/provision/types"
 	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -18,13 +21,46 @@ import (
 )
 
 const (
-	gcpProfile   string = "gcp"
-	awsProfile   string = "aws"
-	azureProfile string = "az"
+	gcpProfile       string = "gcp"
+	awsProfile       string = "aws"
+	azureProfile     string = "az"
+	openstackProfile string = "openstack"
+	alicloudProfile  string = "alicloud"
+
+	// openstackTarget and alicloudTarget are target providers only Gardener supports, so they are no provider types of their own.
+	openstackTarget string = "openstack"
+	alicloudTarget  string = "alicloud"
+
+	// minPodPidsLimit is the lowest PID limit for pods Gardener accepts.
+	minPodPidsLimit = 100
+	// defaultHibernationTimeout is the time to wait for a shoot to hibernate or wake up, if no update timeout is set.
+	defaultHibernationTimeout = 30 * time.Minute
+	// maxAlicloudZones is the number of worker subnets that fit into the VPC of an alicloud shoot.
+	maxAlicloudZones = 8
 )
 
+// timeWindowRegexp matches the time format of Gardener maintenance windows.
+var timeWindowRegexp = regexp.MustCompile(`^([01][0-9]|2[0-3])[0-5][0-9][0-5][0-9][+-][0-9]{4}$`)
+
+// admissionPluginRegexp matches the names of kube-apiserver admission plugins, such as PodNodeSelector.
+var admissionPluginRegexp = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*$`)
+
+// cronFieldRegexp matches a single field of a cron expression, such as 0, */5, 1-5 or MON,FRI.
+var cronFieldRegexp = regexp.MustCompile(`^[0-9A-Za-z*/,-]+$`)
+
+// locationRegexp matches IANA time zone names, such as Europe/Berlin or Etc/GMT+1.
+var locationRegexp = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_+-]*(/[A-Za-z0-9_+-]+)*$`)
+
 type gardenerProvisioner struct {
 	operator operator.Operator
+	// hibernationTimeout is the time to wait for a shoot to hibernate or wake up.
+	hibernationTimeout time.Duration
+	// newGarden creates the garden cluster client used by the pre-flight checks.
+	newGarden func(p *types.Provider) (garden, error)
+	// versions provides the Kubernetes versions the requested version is resolved against.
+	versions types.VersionSource
+	// tracer traces the validation and the generation of kubeconfigs.
+	tracer *tracing.Tracer
 }
 
 func New(operatorType operator.Type, ops ...types.Option) *gardenerProvisioner {
@@ -42,8 +78,22 @@ func New(operatorType operator.Type, ops ...types.Option) *gardenerProvisioner {
 	default:
 		op = &operator.Unknown{}
 	}
+
+	hibernationTimeout := defaultHibernationTimeout
+	if os.Timeouts != nil && os.Timeouts.Update > 0 {
+		hibernationTimeout = os.Timeouts.Update
+	}
+	// the operation timeout bounds the whole operation, waiting included
+	if os.Timeouts != nil && os.Timeouts.Operation > 0 && os.Timeouts.Operation < hibernationTimeout {
+		hibernationTimeout = os.Timeouts.Operation
+	}
+
 	return &gardenerProvisioner{
-		operator: op,
+		operator:           op,
+		hibernationTimeout: hibernationTimeout,
+		newGarden:          newGardenCluster,
+		versions:           os.VersionSource,
+		tracer:             tracing.New(os),
 	}
 }
 
@@ -58,32 +108,89 @@ func (g *gardenerProvisioner) Provision(cluster *types.Cluster, provider *types.
 	if err != nil {
 		return cluster, errors.Wrap(err, "unable to provision gardener cluster")
 	}
+
 	cluster.ClusterInfo = clusterInfo
 	return cluster, nil
 }
 
-// Status returns the ClusterStatus for the requested cluster.
-func (g *gardenerProvisioner) Status(cluster *types.Cluster, p *types.Provider) (*types.ClusterStatus, error) {
-	var state *statefile.File
-	if cluster.ClusterInfo != nil && cluster.ClusterInfo.InternalState != nil {
-		state = cluster.ClusterInfo.InternalState.TerraformState
+// Import brings an existing Gardener cluster under management, as if it was provisioned with the given configurations.
+// It returns the cluster with its ClusterInfo and the settings in which the existing cluster differs from the configurations.
+func (g *gardenerProvisioner) Import(cluster *types.Cluster, provider *types.Provider, id string) (*types.Cluster, []types.Difference, error) {
+	if err := g.validate(cluster, provider); err != nil {
+		return cluster, nil, err
+	}
+
+	config := g.loadConfigurations(cluster, provider)
+
+	clusterInfo, diffs, err := g.operator.Import(provider.Type, config, id)
+	if err != nil {
+		return cluster, nil, errors.Wrap(err, "unable to import gardener cluster")
+	}
+
+	cluster.ClusterInfo = clusterInfo
+	return cluster, diffs, nil
+}
+
+// Eject writes a standalone terraform project for the requested cluster into the given directory, so that it can be managed without Hydroform.
+func (g *gardenerProvisioner) Eject(cluster *types.Cluster, p *types.Provider, dir string) error {
+	if err := g.validate(cluster, p); err != nil {
+		return err
+	}
+
+	config := g.loadConfigurations(cluster, p)
+
+	var state *types.InternalState
+	if cluster.ClusterInfo != nil {
+		state = cluster.ClusterInfo.InternalState
 	}
 
+	if err := g.operator.Eject(state, p.Type, config, dir); err != nil {
+		return errors.Wrap(err, "unable to eject gardener cluster")
+	}
+	return nil
+}
+
+// Rollback restores the version of the state of the requested cluster with the given serial and applies the configuration the Gardener cluster had at that version.
+func (g *gardenerProvisioner) Rollback(cluster *types.Cluster, p *types.Provider, serial uint64) (*types.Cluster, error) {
+	if err := g.validate(cluster, p); err != nil {
+		return cluster, err
+	}
+
+	config := g.loadConfigurations(cluster, p)
+
+	clusterInfo, err := g.operator.Rollback(p.Type, config, serial)
+	if err != nil {
+		return cluster, errors.Wrap(err, "unable to roll back gardener cluster")
+	}
+
+	cluster.ClusterInfo = clusterInfo
+	return cluster, nil
+}
+
+// Status returns the ClusterStatus for the requested cluster.
+// The status is read from the shoot in the garden cluster, so it reflects ongoing operations and the health of the cluster.
+func (g *gardenerProvisioner) Status(cluster *types.Cluster, p *types.Provider) (*types.ClusterStatus, error) {
 	if err := g.validate(cluster, p); err != nil {
 		return nil, err
 	}
 
-	cfg := g.loadConfigurations(cluster, p)
+	garden, err := gardenClient(p)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to connect to the garden cluster")
+	}
 
-	return g.operator.Status(state, p.Type, cfg)
+	return shootStatus(garden, shootNamespace(p), cluster.Name)
 }
 
-func (g *gardenerProvisioner) Credentials(cluster *types.Cluster, provider *types.Provider) ([]byte, error) {
+func (g *gardenerProvisioner) Credentials(cluster *types.Cluster, provider *types.Provider) (data []byte, err error) {
 	if err := g.validate(cluster, provider); err != nil {
 		return nil, err
 	}
 
-	config, err := clientcmd.BuildConfigFromFlags("", provider.CredentialsFilePath)
+	_, span := g.tracer.Start("kubeconfig")
+	defer func() { tracing.End(span, err) }()
+
+	config, err := gardenConfig(provider)
 	if err != nil {
 		return nil, err
 	}
@@ -93,7 +200,7 @@ func (g *gardenerProvisioner) Credentials(cluster *types.Cluster, provider *type
 		return nil, err
 	}
 
-	s, err := k8s.CoreV1().Secrets(fmt.Sprintf("garden-%s", provider.ProjectName)).Get(fmt.Sprintf("%s.kubeconfig", cluster.Name), metav1.GetOptions{})
+	s, err := k8s.CoreV1().Secrets(shootNamespace(provider)).Get(fmt.Sprintf("%s.kubeconfig", cluster.Name), metav1.GetOptions{})
 	if err != nil {
 		return nil, err
 	}
@@ -102,15 +209,17 @@ func (g *gardenerProvisioner) Credentials(cluster *types.Cluster, provider *type
 }
 
 func (g *gardenerProvisioner) Deprovision(cluster *types.Cluster, p *types.Provider) error {
+	// the version is not resolved, the cluster is deleted whichever version it runs
+	cluster = versions.Unresolved(cluster)
 	if err := g.validate(cluster, p); err != nil {
 		return err
 	}
 
 	config := g.loadConfigurations(cluster, p)
 
-	var state *statefile.File
-	if cluster.ClusterInfo != nil && cluster.ClusterInfo.InternalState != nil {
-		state = cluster.ClusterInfo.InternalState.TerraformState
+	var state *types.InternalState
+	if cluster.ClusterInfo != nil {
+		state = cluster.ClusterInfo.InternalState
 	}
 
 	err := g.operator.Delete(state, p.Type, config)
@@ -121,7 +230,33 @@ func (g *gardenerProvisioner) Deprovision(cluster *types.Cluster, p *types.Provi
 	return nil
 }
 
-func (g *gardenerProvisioner) validate(cluster *types.Cluster, provider *types.Provider) error {
+// Hibernate scales the cluster down to save costs and waits until Gardener finished hibernating it.
+func (g *gardenerProvisioner) Hibernate(cluster *types.Cluster, p *types.Provider) error {
+	return g.setHibernation(cluster, p, true)
+}
+
+// WakeUp brings a hibernated cluster back and waits until Gardener finished waking it up.
+func (g *gardenerProvisioner) WakeUp(cluster *types.Cluster, p *types.Provider) error {
+	return g.setHibernation(cluster, p, false)
+}
+
+func (g *gardenerProvisioner) setHibernation(cluster *types.Cluster, p *types.Provider, hibernated bool) error {
+	if err := g.validate(cluster, p); err != nil {
+		return err
+	}
+
+	garden, err := gardenClient(p)
+	if err != nil {
+		return errors.Wrap(err, "unable to connect to the garden cluster")
+	}
+
+	return setHibernation(garden, shootNamespace(p), cluster.Name, hibernated, g.hibernationTimeout)
+}
+
+func (g *gardenerProvisioner) validate(cluster *types.Cluster, provider *types.Provider) (err error) {
+	_, span := g.tracer.Start("validate")
+	defer func() { tracing.End(span, err) }()
+
 	var errMessage string
 
 	// Cluster
@@ -141,14 +276,16 @@ func (g *gardenerProvisioner) validate(cluster *types.Cluster, provider *types.P
 	}
 	if cluster.KubernetesVersion == "" {
 		errMessage += fmt.Sprintf(errs.CannotBeEmpty, "Cluster.KubernetesVersion")
+	} else if _, err := versions.ForCluster(g.versions, types.Gardener, cluster); err != nil {
+		errMessage += fmt.Sprintf(errs.Custom, err.Error())
 	}
 	if cluster.DiskSizeGB <= 0 {
 		errMessage += fmt.Sprintf(errs.CannotBeLess, "Cluster.DiskSizeGB", 0)
 	}
 
 	// Provider
-	if provider.CredentialsFilePath == "" {
-		errMessage += fmt.Sprintf(errs.CannotBeEmpty, "Provider.CredentialsFilePath")
+	if _, err := credentials.Gardener(provider); err != nil {
+		errMessage += fmt.Sprintf(errs.Custom, err.Error())
 	}
 	if provider.ProjectName == "" {
 		errMessage += fmt.Sprintf(errs.CannotBeEmpty, "Provider.ProjectName")
@@ -157,8 +294,10 @@ func (g *gardenerProvisioner) validate(cluster *types.Cluster, provider *types.P
 	// Custom gardener configuration
 	targetProvider, ok := provider.CustomConfigurations["target_provider"]
 	if ok {
-		if targetProvider != string(types.GCP) && targetProvider != string(types.AWS) && targetProvider != string(types.Azure) {
-			errMessage += fmt.Sprintf(errs.Custom, "Provider.CustomConfigurations['target_provider'] has to be one of: gcp, azure, aws")
+		switch targetProvider {
+		case string(types.GCP), string(types.AWS), string(types.Azure), openstackTarget, alicloudTarget:
+		default:
+			errMessage += fmt.Sprintf(errs.Custom, "Provider.CustomConfigurations['target_provider'] has to be one of: gcp, azure, aws, openstack, alicloud")
 		}
 	} else {
 		errMessage += fmt.Sprintf(errs.CannotBeEmpty, "Provider.CustomConfigurations['target_provider']")
@@ -182,23 +321,38 @@ func (g *gardenerProvisioner) validate(cluster *types.Cluster, provider *types.P
 	if _, ok := provider.CustomConfigurations["worker_max_unavailable"]; !ok {
 		errMessage += fmt.Sprintf(errs.CannotBeEmpty, "Provider.CustomConfigurations['worker_max_unavailable']")
 	}
-	if _, ok := provider.CustomConfigurations["workercidr"]; !ok && (targetProvider == string(types.GCP) || targetProvider == string(types.Azure)) {
+	if _, ok := provider.CustomConfigurations["workercidr"]; !ok && (targetProvider == string(types.GCP) || targetProvider == string(types.Azure) || targetProvider == openstackTarget) {
 		errMessage += fmt.Sprintf(errs.CannotBeEmpty, "Provider.CustomConfigurations['workercidr']")
 	}
-	if _, ok := provider.CustomConfigurations["zones"]; !ok && (targetProvider == string(types.GCP) || targetProvider == string(types.AWS)) {
+	if _, ok := provider.CustomConfigurations["zones"]; !ok && (targetProvider == string(types.GCP) || targetProvider == string(types.AWS) || targetProvider == openstackTarget || targetProvider == alicloudTarget) {
 		errMessage += fmt.Sprintf(errs.CannotBeEmpty, "Provider.CustomConfigurations['zone']")
 	}
-	if _, ok := provider.CustomConfigurations["vnetcidr"]; !ok && (targetProvider == string(types.Azure) || targetProvider == string(types.AWS)) {
+	if _, ok := provider.CustomConfigurations["vnetcidr"]; !ok && (targetProvider == string(types.Azure) || targetProvider == string(types.AWS) || targetProvider == alicloudTarget) {
 		errMessage += fmt.Sprintf(errs.CannotBeEmpty, "Provider.CustomConfigurations['vnetcidr']")
 	}
+	if zones, ok := provider.CustomConfigurations["zones"].([]string); ok && targetProvider == alicloudTarget && len(zones) > maxAlicloudZones {
+		errMessage += fmt.Sprintf(errs.Custom, fmt.Sprintf("Provider.CustomConfigurations['zones'] cannot have more than %d zones on alicloud", maxAlicloudZones))
+	}
 
-	if _, ok := provider.CustomConfigurations["machine_image_name"]; !ok && targetProvider == string(types.Azure) {
+	if _, ok := provider.CustomConfigurations["machine_image_name"]; !ok && (targetProvider == string(types.Azure) || targetProvider == openstackTarget || targetProvider == alicloudTarget) {
 		errMessage += fmt.Sprintf(errs.CannotBeEmpty, "Provider.CustomConfigurations['machine_image_name']")
 	}
-	if _, ok := provider.CustomConfigurations["machine_image_version"]; !ok && targetProvider == string(types.Azure) {
+	if _, ok := provider.CustomConfigurations["machine_image_version"]; !ok && (targetProvider == string(types.Azure) || targetProvider == openstackTarget || targetProvider == alicloudTarget) {
 		errMessage += fmt.Sprintf(errs.CannotBeEmpty, "Provider.CustomConfigurations['machine_image_version']")
 	}
 
+	if _, ok := provider.CustomConfigurations["floating_pool_name"]; !ok && targetProvider == openstackTarget {
+		errMessage += fmt.Sprintf(errs.CannotBeEmpty, "Provider.CustomConfigurations['floating_pool_name']")
+	}
+	if _, ok := provider.CustomConfigurations["load_balancer_provider"]; !ok && targetProvider == openstackTarget {
+		errMessage += fmt.Sprintf(errs.CannotBeEmpty, "Provider.CustomConfigurations['load_balancer_provider']")
+	}
+	if v, ok := provider.CustomConfigurations["target_profile"]; ok {
+		if s, ok := v.(string); !ok || s == "" {
+			errMessage += fmt.Sprintf(errs.CannotBeEmpty, "Provider.CustomConfigurations['target_profile']")
+		}
+	}
+
 	if _, ok := provider.CustomConfigurations["networking_type"]; !ok {
 		errMessage += fmt.Sprintf(errs.CannotBeEmpty, "Provider.CustomConfigurations['networking_type']")
 	}
@@ -209,28 +363,39 @@ func (g *gardenerProvisioner) validate(cluster *types.Cluster, provider *types.P
 		errMessage += fmt.Sprintf(errs.CannotBeEmpty, "Provider.CustomConfigurations['gcp_control_plane_zone']")
 	}
 
+	errMessage += validateShootSettings(provider.CustomConfigurations)
+	errMessage += labels.Validate(types.Gardener, labels.ForCluster(cluster))
+
 	if errMessage != "" {
-		return errors.New("input validation failed with the following information: " + errMessage)
+		return types.Classify(types.ErrorClassValidation, errors.New("input validation failed with the following information: "+errMessage))
 	}
 	return nil
 }
 
-func (*gardenerProvisioner) loadConfigurations(cluster *types.Cluster, provider *types.Provider) map[string]interface{} {
+func (g *gardenerProvisioner) loadConfigurations(cluster *types.Cluster, provider *types.Provider) map[string]interface{} {
 	config := map[string]interface{}{}
 	config["cluster_name"] = cluster.Name
-	config["credentials_file_path"] = provider.CredentialsFilePath
+	// the credentials are validated already, they are passed to terraform in a private vars file of the operation
+	kubeconfig, _ := credentials.Gardener(provider)
+	config["credentials"] = operator.Sensitive(kubeconfig)
 	config["node_count"] = cluster.NodeCount
 	config["machine_type"] = cluster.MachineType
 	config["disk_size"] = cluster.DiskSizeGB
-	config["kubernetes_version"] = cluster.KubernetesVersion
+	// the version is validated already
+	version, _ := versions.ForCluster(g.versions, types.Gardener, cluster)
+	config["kubernetes_version"] = version
 	config["location"] = cluster.Location
 	config["project"] = provider.ProjectName
-	config["namespace"] = fmt.Sprintf("garden-%s", provider.ProjectName)
+	config["namespace"] = shootNamespace(provider)
+	config["labels"] = labels.ForCluster(cluster)
 
 	for k, v := range provider.CustomConfigurations {
 		config[k] = v
 	}
 
+	// landscapes can name their cloud profiles freely, the defaults are the profile names of the Gardener project
+	_, customProfile := config["target_profile"]
+
 	switch config["target_provider"] {
 	case string(types.GCP):
 		config["target_profile"] = gcpProfile
@@ -256,6 +421,109 @@ func (*gardenerProvisioner) loadConfigurations(cluster *types.Cluster, provider
 
 		// need to set the zoned property if we have a cluster with zones
 		config["zoned"] = strconv.FormatBool(len(config["zones"].([]string)) > 0) // add zoned boolean
+	case openstackTarget:
+		config["target_profile"] = openstackProfile
+
+		// nodes CIDR is usually the same as workercidr
+		if v, ok := config["networking_nodes"]; !ok || v == "" {
+			config["networking_nodes"] = config["workercidr"]
+		}
+	case alicloudTarget:
+		config["target_profile"] = alicloudProfile
+
+		// nodes CIDR is usually the same as vnetcidr
+		if v, ok := config["networking_nodes"]; !ok || v == "" {
+			config["networking_nodes"] = config["vnetcidr"]
+		}
+	}
+
+	if customProfile {
+		config["target_profile"] = provider.CustomConfigurations["target_profile"]
 	}
 	return config
 }
+
+// validateShootSettings checks the optional maintenance, hibernation and Kubernetes settings of the shoot.
+// Without any of them, the shoot is maintained daily between 03:00 and 04:00 UTC with all auto updates enabled.
+func validateShootSettings(cfg map[string]interface{}) string {
+	var errMessage string
+
+	for _, k := range []string{"maintenance_window_begin", "maintenance_window_end"} {
+		if v, ok := cfg[k]; ok {
+			if s, ok := v.(string); !ok || !timeWindowRegexp.MatchString(s) {
+				errMessage += fmt.Sprintf(errs.Custom, fmt.Sprintf("Provider.CustomConfigurations['%s'] must have the format HHMMSS+ZZZZ, for example 030000+0000", k))
+			}
+		}
+	}
+
+	for _, k := range []string{"auto_update_kubernetes_version", "auto_update_machine_image_version", "allow_privileged_containers", "kubelet_cpu_cfs_quota"} {
+		if v, ok := cfg[k]; ok {
+			if _, ok := v.(bool); !ok {
+				errMessage += fmt.Sprintf(errs.Custom, fmt.Sprintf("Provider.CustomConfigurations['%s'] must be a boolean", k))
+			}
+		}
+	}
+
+	if v, ok := cfg["kube_api_server_admission_plugins"]; ok {
+		plugins, ok := v.([]string)
+		if !ok {
+			errMessage += fmt.Sprintf(errs.Custom, "Provider.CustomConfigurations['kube_api_server_admission_plugins'] must be a list of admission plugin names")
+		}
+		for _, p := range plugins {
+			if !admissionPluginRegexp.MatchString(p) {
+				errMessage += fmt.Sprintf(errs.Custom, fmt.Sprintf("Provider.CustomConfigurations['kube_api_server_admission_plugins'] contains an invalid admission plugin name: %q", p))
+			}
+		}
+	}
+
+	if v, ok := cfg["kubelet_pod_pids_limit"]; ok {
+		if limit, ok := v.(int); !ok || limit < minPodPidsLimit {
+			errMessage += fmt.Sprintf(errs.CannotBeLess, "Provider.CustomConfigurations['kubelet_pod_pids_limit']", minPodPidsLimit)
+		}
+	}
+	if v, ok := cfg["kubelet_cpu_manager_policy"]; ok {
+		if v != "none" && v != "static" {
+			errMessage += fmt.Sprintf(errs.Custom, "Provider.CustomConfigurations['kubelet_cpu_manager_policy'] has to be one of: none, static")
+		}
+	}
+
+	if v, ok := cfg["hibernation_schedules"]; ok {
+		schedules, ok := v.([]map[string]string)
+		if !ok {
+			errMessage += fmt.Sprintf(errs.Custom, "Provider.CustomConfigurations['hibernation_schedules'] must be a list of schedules with a 'start' and/or 'end' cron expression")
+		}
+		for i, sc := range schedules {
+			if sc["start"] == "" && sc["end"] == "" {
+				errMessage += fmt.Sprintf(errs.Custom, fmt.Sprintf("Provider.CustomConfigurations['hibernation_schedules'][%d] needs a start or an end", i))
+			}
+			for _, k := range []string{"start", "end"} {
+				if sc[k] != "" && !isCron(sc[k]) {
+					errMessage += fmt.Sprintf(errs.Custom, fmt.Sprintf("Provider.CustomConfigurations['hibernation_schedules'][%d] %s must be a cron expression with 5 fields", i, k))
+				}
+			}
+		}
+	}
+	if v, ok := cfg["hibernation_location"]; ok {
+		if s, ok := v.(string); !ok || !locationRegexp.MatchString(s) {
+			errMessage += fmt.Sprintf(errs.Custom, "Provider.CustomConfigurations['hibernation_location'] must be a time zone name")
+		} else if _, err := time.LoadLocation(s); err != nil {
+			errMessage += fmt.Sprintf(errs.Custom, fmt.Sprintf("Provider.CustomConfigurations['hibernation_location'] is not a known time zone: %s", s))
+		}
+	}
+
+	return errMessage
+}
+
+// isCron returns true if the given schedule is a cron expression with 5 fields.
+func isCron(schedule string) bool {
+	fields := strings.Fields(schedule)
+	if len(fields) != 5 {
+		return false
+	}
+	for _, f := range fields {
+		if !cronFieldRegexp.MatchString(f) {
+			return false
+		}
+	}
+	return true
+}
@@ -2,9 +2,10 @@ package gardener
 
 import (
 	"fmt"
+	"io/ioutil"
 	"testing"
 
-	"github.com/hashicorp/terraform/states/statefile"
+	"github.com/kyma-incubator/hydroform/provision/internal/operator"
 	"github.com/kyma-incubator/hydroform/provision/internal/operator/mocks"
 	"github.com/pkg/errors"
 
@@ -28,7 +29,7 @@ func TestValidate(t *testing.T) {
 		provider := &types.Provider{
 			Type:                types.Gardener,
 			ProjectName:         "my-project",
-			CredentialsFilePath: "/path/to/credentials",
+			CredentialsFilePath: "testdata/kubeconfig.yaml",
 			CustomConfigurations: map[string]interface{}{
 				"target_provider":        "gcp",
 				"target_secret":          "secret-name",
@@ -77,7 +78,7 @@ func TestValidate(t *testing.T) {
 		provider := &types.Provider{
 			Type:                types.Gardener,
 			ProjectName:         "my-project",
-			CredentialsFilePath: "/path/to/credentials",
+			CredentialsFilePath: "testdata/kubeconfig.yaml",
 			CustomConfigurations: map[string]interface{}{
 				"target_provider":        "azure",
 				"target_secret":          "secret_name",
@@ -128,7 +129,7 @@ func TestValidate(t *testing.T) {
 		provider := &types.Provider{
 			Type:                types.Gardener,
 			ProjectName:         "my-project",
-			CredentialsFilePath: "/path/to/credentials",
+			CredentialsFilePath: "testdata/kubeconfig.yaml",
 			CustomConfigurations: map[string]interface{}{
 				"target_provider":        "aws",
 				"target_secret":          "secret-name",
@@ -161,6 +162,109 @@ func TestValidate(t *testing.T) {
 		require.Error(t, g.validate(cluster, provider), "Validation should fail when vnetcidr is empty")
 		provider.CustomConfigurations["vnetcidr"] = "172.31.0.0/16"
 	})
+
+	t.Run("Validate OpenStack config", func(t *testing.T) {
+		g := gardenerProvisioner{}
+
+		cluster := &types.Cluster{
+			CPU:               1,
+			KubernetesVersion: "1.16",
+			Name:              "hydro-cluster",
+			DiskSizeGB:        30,
+			NodeCount:         2,
+			Location:          "eu-de-1",
+			MachineType:       "medium_4_8",
+		}
+		provider := &types.Provider{
+			Type:                types.Gardener,
+			ProjectName:         "my-project",
+			CredentialsFilePath: "testdata/kubeconfig.yaml",
+			CustomConfigurations: map[string]interface{}{
+				"target_provider":        "openstack",
+				"target_secret":          "secret-name",
+				"target_profile":         "converged-cloud",
+				"disk_type":              "default",
+				"workercidr":             "10.250.0.0/19",
+				"zones":                  []string{"eu-de-1a"},
+				"floating_pool_name":     "FloatingIP-external",
+				"load_balancer_provider": "f5",
+				"machine_image_name":     "gardenlinux",
+				"machine_image_version":  "27.1.0",
+				"worker_max_surge":       4,
+				"worker_max_unavailable": 1,
+				"worker_maximum":         4,
+				"worker_minimum":         2,
+				"networking_type":        "calico",
+			},
+		}
+
+		performBasicValidation(t, g, cluster, provider)
+
+		//openstack specific validation
+		delete(provider.CustomConfigurations, "floating_pool_name")
+		require.Error(t, g.validate(cluster, provider), "Validation should fail when floating_pool_name is empty")
+		provider.CustomConfigurations["floating_pool_name"] = "FloatingIP-external"
+
+		delete(provider.CustomConfigurations, "load_balancer_provider")
+		require.Error(t, g.validate(cluster, provider), "Validation should fail when load_balancer_provider is empty")
+		provider.CustomConfigurations["load_balancer_provider"] = "f5"
+
+		delete(provider.CustomConfigurations, "workercidr")
+		require.Error(t, g.validate(cluster, provider), "Validation should fail when workercidr is empty")
+		provider.CustomConfigurations["workercidr"] = "10.250.0.0/19"
+
+		provider.CustomConfigurations["target_profile"] = ""
+		require.Error(t, g.validate(cluster, provider), "Validation should fail when the cloud profile is empty")
+		provider.CustomConfigurations["target_profile"] = "converged-cloud"
+	})
+
+	t.Run("Validate Alicloud config", func(t *testing.T) {
+		g := gardenerProvisioner{}
+
+		cluster := &types.Cluster{
+			CPU:               1,
+			KubernetesVersion: "1.16",
+			Name:              "hydro-cluster",
+			DiskSizeGB:        30,
+			NodeCount:         2,
+			Location:          "eu-central-1",
+			MachineType:       "ecs.sn2ne.large",
+		}
+		provider := &types.Provider{
+			Type:                types.Gardener,
+			ProjectName:         "my-project",
+			CredentialsFilePath: "testdata/kubeconfig.yaml",
+			CustomConfigurations: map[string]interface{}{
+				"target_provider":        "alicloud",
+				"target_secret":          "secret-name",
+				"disk_type":              "cloud_efficiency",
+				"vnetcidr":               "10.250.0.0/16",
+				"zones":                  []string{"eu-central-1a"},
+				"machine_image_name":     "coreos-alicloud",
+				"machine_image_version":  "2023.4.0",
+				"worker_max_surge":       4,
+				"worker_max_unavailable": 1,
+				"worker_maximum":         4,
+				"worker_minimum":         2,
+				"networking_type":        "calico",
+			},
+		}
+
+		performBasicValidation(t, g, cluster, provider)
+
+		//alicloud specific validation
+		delete(provider.CustomConfigurations, "vnetcidr")
+		require.Error(t, g.validate(cluster, provider), "Validation should fail when vnetcidr is empty")
+		provider.CustomConfigurations["vnetcidr"] = "10.250.0.0/16"
+
+		delete(provider.CustomConfigurations, "machine_image_name")
+		require.Error(t, g.validate(cluster, provider), "Validation should fail when machine_image_name is empty")
+		provider.CustomConfigurations["machine_image_name"] = "coreos-alicloud"
+
+		provider.CustomConfigurations["zones"] = []string{"a", "b", "c", "d", "e", "f", "g", "h", "i"}
+		require.Error(t, g.validate(cluster, provider), "Validation should fail when there are more zones than subnets")
+		provider.CustomConfigurations["zones"] = []string{"eu-central-1a"}
+	})
 }
 
 func performBasicValidation(t *testing.T, g gardenerProvisioner, cluster *types.Cluster, provider *types.Provider) {
@@ -197,7 +301,7 @@ func performBasicValidation(t *testing.T, g gardenerProvisioner, cluster *types.
 
 	provider.CredentialsFilePath = ""
 	require.Error(t, g.validate(cluster, provider), "Validation should fail when credentials file path is empty")
-	provider.CredentialsFilePath = "/path/to/credentials"
+	provider.CredentialsFilePath = "testdata/kubeconfig.yaml"
 
 	provider.ProjectName = ""
 	require.Error(t, g.validate(cluster, provider), "Validation should fail when project name is empty")
@@ -254,7 +358,7 @@ func TestLoadConfigurations(t *testing.T) {
 	provider := &types.Provider{
 		Type:                types.Gardener,
 		ProjectName:         "my-project",
-		CredentialsFilePath: "/path/to/credentials",
+		CredentialsFilePath: "testdata/kubeconfig.yaml",
 		CustomConfigurations: map[string]interface{}{
 			"target_provider":        "gcp",
 			"target_secret":          "secret-name",
@@ -268,7 +372,9 @@ func TestLoadConfigurations(t *testing.T) {
 	config := g.loadConfigurations(cluster, provider)
 
 	require.Equal(t, cluster.Name, config["cluster_name"])
-	require.Equal(t, provider.CredentialsFilePath, config["credentials_file_path"])
+	credentials, err := ioutil.ReadFile(provider.CredentialsFilePath)
+	require.NoError(t, err)
+	require.Equal(t, operator.Sensitive(credentials), config["credentials"], "The credentials should be passed as sensitive value")
 	require.Equal(t, cluster.NodeCount, config["node_count"])
 	require.Equal(t, cluster.MachineType, config["machine_type"])
 	require.Equal(t, cluster.DiskSizeGB, config["disk_size"])
@@ -279,6 +385,11 @@ func TestLoadConfigurations(t *testing.T) {
 	for k, v := range provider.CustomConfigurations {
 		require.Equal(t, v, config[k], fmt.Sprintf("Custom config %s is incorrect", k))
 	}
+	require.Equal(t, gcpProfile, config["target_profile"], "The default cloud profile should be used")
+
+	provider.CustomConfigurations["target_profile"] = "my-gcp-profile"
+	config = g.loadConfigurations(cluster, provider)
+	require.Equal(t, "my-gcp-profile", config["target_profile"], "The cloud profile should be overridable")
 }
 
 func TestProvision(t *testing.T) {
@@ -299,7 +410,7 @@ func TestProvision(t *testing.T) {
 	provider := &types.Provider{
 		Type:                types.Gardener,
 		ProjectName:         "my-project",
-		CredentialsFilePath: "/path/to/credentials",
+		CredentialsFilePath: "testdata/kubeconfig.yaml",
 		CustomConfigurations: map[string]interface{}{
 			"target_provider":        "gcp",
 			"target_secret":          "secret-name",
@@ -330,6 +441,7 @@ func TestProvision(t *testing.T) {
 	cluster, err := g.Provision(cluster, provider)
 	require.NoError(t, err, "Provision should succeed")
 	require.Equal(t, result, cluster.ClusterInfo, "The cluster info returned from the operator should be in the cluster returned by Provision")
+	require.Empty(t, cluster.ClusterInfo.KubernetesVersion, "The requested Kubernetes version should not be reported as the running one")
 
 	badCluster := &types.Cluster{
 		CPU: 1,
@@ -359,7 +471,7 @@ func TestDeProvision(t *testing.T) {
 	provider := &types.Provider{
 		Type:                types.Gardener,
 		ProjectName:         "my-project",
-		CredentialsFilePath: "/path/to/credentials",
+		CredentialsFilePath: "testdata/kubeconfig.yaml",
 		CustomConfigurations: map[string]interface{}{
 			"target_provider":        "gcp",
 			"target_secret":          "secret-name",
@@ -374,7 +486,7 @@ func TestDeProvision(t *testing.T) {
 			"networking_type":        "calico",
 		},
 	}
-	var state *statefile.File
+	var state *types.InternalState
 	mockOp.On("Delete", state, types.Gardener, g.loadConfigurations(cluster, provider)).Return(nil)
 
 	err := g.Deprovision(cluster, provider)
@@ -386,3 +498,62 @@ func TestDeProvision(t *testing.T) {
 	err = g.Deprovision(cluster, provider)
 	require.Error(t, err, "Deprovision should fail")
 }
+
+func TestValidateShootSettings(t *testing.T) {
+	require.Empty(t, validateShootSettings(map[string]interface{}{}), "No shoot settings should keep the defaults")
+
+	cfg := map[string]interface{}{
+		"maintenance_window_begin":          "220000+0100",
+		"maintenance_window_end":            "230000+0100",
+		"auto_update_kubernetes_version":    false,
+		"auto_update_machine_image_version": true,
+		"allow_privileged_containers":       false,
+		"kube_api_server_admission_plugins": []string{"PodNodeSelector"},
+		"kubelet_pod_pids_limit":            1024,
+		"kubelet_cpu_cfs_quota":             true,
+		"kubelet_cpu_manager_policy":        "static",
+		"hibernation_schedules":             []map[string]string{{"start": "00 20 * * 1,2,3,4,5", "end": "00 08 * * 1,2,3,4,5"}},
+		"hibernation_location":              "Europe/Berlin",
+	}
+	require.Empty(t, validateShootSettings(cfg), "Validation should pass")
+
+	cfg["maintenance_window_begin"] = "22:00"
+	require.NotEmpty(t, validateShootSettings(cfg), "Validation should fail for an invalid maintenance window")
+	cfg["maintenance_window_begin"] = "220000+0100"
+
+	cfg["auto_update_kubernetes_version"] = "false"
+	require.NotEmpty(t, validateShootSettings(cfg), "Validation should fail when auto update is not a boolean")
+	cfg["auto_update_kubernetes_version"] = false
+
+	cfg["kube_api_server_admission_plugins"] = []string{`PodNodeSelector" }`}
+	require.NotEmpty(t, validateShootSettings(cfg), "Validation should fail for an invalid admission plugin name")
+	cfg["kube_api_server_admission_plugins"] = []string{"PodNodeSelector"}
+
+	cfg["kubelet_pod_pids_limit"] = 10
+	require.NotEmpty(t, validateShootSettings(cfg), "Validation should fail when the PID limit is too low")
+	cfg["kube_api_server_admission_plugins"] = []string{`PodNodeSelector" }`}
+	require.NotEmpty(t, validateShootSettings(cfg), "Validation should fail for an invalid admission plugin name")
+	cfg["kube_api_server_admission_plugins"] = []string{"PodNodeSelector"}
+
+	cfg["kubelet_pod_pids_limit"] = 1024
+
+	cfg["kubelet_cpu_manager_policy"] = "dynamic"
+	require.NotEmpty(t, validateShootSettings(cfg), "Validation should fail for an unknown CPU manager policy")
+	cfg["kubelet_cpu_manager_policy"] = "none"
+
+	cfg["hibernation_schedules"] = []map[string]string{{"start": "every evening"}}
+	require.NotEmpty(t, validateShootSettings(cfg), "Validation should fail for an invalid cron expression")
+	cfg["hibernation_schedules"] = []map[string]string{{"start": `00 20 * * "}${x}`}}
+	require.NotEmpty(t, validateShootSettings(cfg), "Validation should fail for a cron expression with invalid characters")
+	cfg["hibernation_schedules"] = []map[string]string{{}}
+	require.NotEmpty(t, validateShootSettings(cfg), "Validation should fail for a schedule without start and end")
+	cfg["hibernation_schedules"] = []map[string]string{{"start": "00 20 * * *"}}
+
+	cfg["hibernation_location"] = `Europe/Berlin"`
+	require.NotEmpty(t, validateShootSettings(cfg), "Validation should fail for a time zone with invalid characters")
+	cfg["hibernation_location"] = "Middle/Earth"
+	require.NotEmpty(t, validateShootSettings(cfg), "Validation should fail for an unknown time zone")
+	cfg["hibernation_location"] = "UTC"
+
+	require.Empty(t, validateShootSettings(cfg), "Validation should pass")
+}
@@ -0,0 +1,229 @@
+package gardener
+
+import (
+	"fmt"
+
+	"github.com/kyma-incubator/hydroform/provision/internal/errs"
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/pkg/errors"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	secretBindingResource = schema.GroupVersionResource{Group: "core.gardener.cloud", Version: "v1beta1", Resource: "secretbindings"}
+	cloudProfileResource  = schema.GroupVersionResource{Group: "core.gardener.cloud", Version: "v1beta1", Resource: "cloudprofiles"}
+)
+
+// cloudProfile contains the parts of a Gardener cloud profile the pre-flight checks need.
+type cloudProfile struct {
+	// Regions maps the regions of the profile to their zones.
+	Regions      map[string][]string
+	MachineTypes []string
+}
+
+// garden is the part of the garden cluster API the pre-flight checks need. It can be stubbed in tests.
+type garden interface {
+	// NamespaceExists checks if the namespace of the project exists.
+	NamespaceExists(namespace string) (bool, error)
+	// CanCreateShoots checks if the service account can create shoots in the namespace.
+	CanCreateShoots(namespace string) (bool, error)
+	// SecretBindingExists checks if the secret binding to the infrastructure exists in the namespace.
+	SecretBindingExists(namespace, name string) (bool, error)
+	// CloudProfile returns the cloud profile with the given name or nil if it does not exist.
+	CloudProfile(name string) (*cloudProfile, error)
+}
+
+// Validate runs the input validation and checks against the garden cluster that the cluster can be provisioned.
+func (g *gardenerProvisioner) Validate(cluster *types.Cluster, provider *types.Provider) error {
+	if err := g.validate(cluster, provider); err != nil {
+		return err
+	}
+
+	gc, err := g.newGarden(provider)
+	if err != nil {
+		return types.Classify(types.ErrorClassCredentials, errors.Wrap(err, "unable to connect to the garden cluster"))
+	}
+	return preflight(gc, cluster, g.loadConfigurations(cluster, provider))
+}
+
+// preflight checks the project namespace, the permissions of the service account, the secret binding, and the region, zones and machine type of the cluster against the cloud profile.
+func preflight(gc garden, cluster *types.Cluster, cfg map[string]interface{}) error {
+	var errMessage string
+	namespace := cfg["namespace"].(string)
+
+	exists, err := gc.NamespaceExists(namespace)
+	if err != nil {
+		return types.Classify(types.ErrorClassValidation, errors.New("pre-flight checks failed with the following information: "+fmt.Sprintf(errs.Custom, err.Error())))
+	}
+	if !exists {
+		// all other checks are scoped to the namespace of the project
+		return types.Classify(types.ErrorClassValidation, errors.New("pre-flight checks failed with the following information: "+fmt.Sprintf(errs.Custom, fmt.Sprintf("project namespace %s does not exist", namespace))))
+	}
+
+	if ok, err := gc.CanCreateShoots(namespace); err != nil {
+		errMessage += fmt.Sprintf(errs.Custom, err.Error())
+	} else if !ok {
+		errMessage += fmt.Sprintf(errs.Custom, fmt.Sprintf("the service account is not allowed to create shoots in namespace %s", namespace))
+	}
+
+	secret := fmt.Sprintf("%v", cfg["target_secret"])
+	if ok, err := gc.SecretBindingExists(namespace, secret); err != nil {
+		errMessage += fmt.Sprintf(errs.Custom, err.Error())
+	} else if !ok {
+		errMessage += fmt.Sprintf(errs.Custom, fmt.Sprintf("secret binding %s does not exist in namespace %s", secret, namespace))
+	}
+
+	profileName := fmt.Sprintf("%v", cfg["target_profile"])
+	profile, err := gc.CloudProfile(profileName)
+	switch {
+	case err != nil:
+		errMessage += fmt.Sprintf(errs.Custom, err.Error())
+	case profile == nil:
+		errMessage += fmt.Sprintf(errs.Custom, fmt.Sprintf("cloud profile %s does not exist", profileName))
+	default:
+		errMessage += checkProfile(profile, profileName, cluster, cfg)
+	}
+
+	if errMessage != "" {
+		return types.Classify(types.ErrorClassValidation, errors.New("pre-flight checks failed with the following information: "+errMessage))
+	}
+	return nil
+}
+
+func checkProfile(profile *cloudProfile, name string, cluster *types.Cluster, cfg map[string]interface{}) string {
+	var errMessage string
+
+	if zones, ok := profile.Regions[cluster.Location]; !ok {
+		errMessage += fmt.Sprintf(errs.Custom, fmt.Sprintf("region %s is not offered by cloud profile %s", cluster.Location, name))
+	} else if requested, ok := cfg["zones"].([]string); ok {
+		for _, z := range requested {
+			if !contains(zones, z) {
+				errMessage += fmt.Sprintf(errs.Custom, fmt.Sprintf("zone %s is not offered in region %s by cloud profile %s", z, cluster.Location, name))
+			}
+		}
+	}
+
+	if !contains(profile.MachineTypes, cluster.MachineType) {
+		errMessage += fmt.Sprintf(errs.Custom, fmt.Sprintf("machine type %s is not offered by cloud profile %s", cluster.MachineType, name))
+	}
+	return errMessage
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+// gardenCluster is the garden implementation using the Kubernetes API of the garden cluster.
+type gardenCluster struct {
+	k8s     kubernetes.Interface
+	dynamic dynamic.Interface
+}
+
+// newGardenCluster creates clients for the garden cluster from the kubeconfig of the Gardener service account.
+func newGardenCluster(p *types.Provider) (garden, error) {
+	config, err := gardenConfig(p)
+	if err != nil {
+		return nil, err
+	}
+	k8s, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	d, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &gardenCluster{k8s: k8s, dynamic: d}, nil
+}
+
+func (g *gardenCluster) NamespaceExists(namespace string) (bool, error) {
+	if _, err := g.k8s.CoreV1().Namespaces().Get(namespace, metav1.GetOptions{}); err != nil {
+		// service accounts of a project are usually not allowed to read their own namespace, so forbidden means it exists
+		if k8serrors.IsForbidden(err) {
+			return true, nil
+		}
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "could not get namespace %s", namespace)
+	}
+	return true, nil
+}
+
+func (g *gardenCluster) CanCreateShoots(namespace string) (bool, error) {
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      "create",
+				Group:     shootResource.Group,
+				Resource:  shootResource.Resource,
+			},
+		},
+	}
+	res, err := g.k8s.AuthorizationV1().SelfSubjectAccessReviews().Create(review)
+	if err != nil {
+		return false, errors.Wrap(err, "could not check the permissions of the service account")
+	}
+	return res.Status.Allowed, nil
+}
+
+func (g *gardenCluster) SecretBindingExists(namespace, name string) (bool, error) {
+	if _, err := g.dynamic.Resource(secretBindingResource).Namespace(namespace).Get(name, metav1.GetOptions{}); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "could not get secret binding %s", name)
+	}
+	return true, nil
+}
+
+func (g *gardenCluster) CloudProfile(name string) (*cloudProfile, error) {
+	obj, err := g.dynamic.Resource(cloudProfileResource).Get(name, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "could not get cloud profile %s", name)
+	}
+	return parseCloudProfile(obj), nil
+}
+
+func parseCloudProfile(obj *unstructured.Unstructured) *cloudProfile {
+	profile := &cloudProfile{Regions: map[string][]string{}}
+
+	regions, _, _ := unstructured.NestedSlice(obj.Object, "spec", "regions")
+	for _, r := range regions {
+		region, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var zones []string
+		zs, _, _ := unstructured.NestedSlice(region, "zones")
+		for _, z := range zs {
+			if zone, ok := z.(map[string]interface{}); ok {
+				zones = append(zones, stringField(zone, "name"))
+			}
+		}
+		profile.Regions[stringField(region, "name")] = zones
+	}
+
+	machineTypes, _, _ := unstructured.NestedSlice(obj.Object, "spec", "machineTypes")
+	for _, m := range machineTypes {
+		if mt, ok := m.(map[string]interface{}); ok {
+			profile.MachineTypes = append(profile.MachineTypes, stringField(mt, "name"))
+		}
+	}
+	return profile
+}
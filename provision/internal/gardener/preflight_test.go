@@ -0,0 +1,116 @@
+package gardener
+
+import (
+	"testing"
+
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// fakeGarden is a garden cluster with a single project.
+type fakeGarden struct {
+	allowed bool
+}
+
+func (f *fakeGarden) NamespaceExists(namespace string) (bool, error) {
+	return namespace == "garden-my-project", nil
+}
+
+func (f *fakeGarden) CanCreateShoots(namespace string) (bool, error) {
+	return f.allowed, nil
+}
+
+func (f *fakeGarden) SecretBindingExists(namespace, name string) (bool, error) {
+	return name == "secret-name", nil
+}
+
+func (f *fakeGarden) CloudProfile(name string) (*cloudProfile, error) {
+	if name != gcpProfile {
+		return nil, nil
+	}
+	return &cloudProfile{
+		Regions:      map[string][]string{"europe-west3": {"europe-west3-a", "europe-west3-b"}},
+		MachineTypes: []string{"n1-standard-4"},
+	}, nil
+}
+
+func TestValidatePreflight(t *testing.T) {
+	gc := &fakeGarden{allowed: true}
+	g := gardenerProvisioner{
+		newGarden: func(p *types.Provider) (garden, error) {
+			return gc, nil
+		},
+	}
+
+	cluster := &types.Cluster{
+		KubernetesVersion: "1.15",
+		Name:              "hydro-cluster",
+		DiskSizeGB:        30,
+		NodeCount:         2,
+		Location:          "europe-west3",
+		MachineType:       "n1-standard-4",
+	}
+	provider := &types.Provider{
+		Type:                types.Gardener,
+		ProjectName:         "my-project",
+		CredentialsFilePath: "testdata/kubeconfig.yaml",
+		CustomConfigurations: map[string]interface{}{
+			"target_provider":        "gcp",
+			"target_secret":          "secret-name",
+			"disk_type":              "pd-standard",
+			"workercidr":             "10.250.0.0/19",
+			"worker_max_surge":       4,
+			"worker_max_unavailable": 1,
+			"worker_maximum":         4,
+			"worker_minimum":         2,
+			"zones":                  []string{"europe-west3-b"},
+			"gcp_control_plane_zone": "europe-west3-b",
+			"networking_type":        "calico",
+		},
+	}
+	require.NoError(t, g.Validate(cluster, provider), "Pre-flight checks should pass")
+
+	provider.CustomConfigurations["zones"] = []string{"europe-west3-c"}
+	require.Error(t, g.Validate(cluster, provider), "Zones missing in the cloud profile should be reported")
+	provider.CustomConfigurations["zones"] = []string{"europe-west3-b"}
+
+	cluster.MachineType = "n1-standard-96"
+	require.Error(t, g.Validate(cluster, provider), "Machine types missing in the cloud profile should be reported")
+	cluster.MachineType = "n1-standard-4"
+
+	provider.CustomConfigurations["target_profile"] = "my-gcp"
+	require.Error(t, g.Validate(cluster, provider), "A missing cloud profile should be reported")
+	delete(provider.CustomConfigurations, "target_profile")
+
+	provider.CustomConfigurations["target_secret"] = "other-secret"
+	require.Error(t, g.Validate(cluster, provider), "A missing secret binding should be reported")
+	provider.CustomConfigurations["target_secret"] = "secret-name"
+
+	gc.allowed = false
+	require.Error(t, g.Validate(cluster, provider), "Missing permissions should be reported")
+	gc.allowed = true
+
+	provider.ProjectName = "other-project"
+	require.Error(t, g.Validate(cluster, provider), "A missing project should be reported")
+}
+
+func TestParseCloudProfile(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"regions": []interface{}{
+				map[string]interface{}{
+					"name":  "europe-west3",
+					"zones": []interface{}{map[string]interface{}{"name": "europe-west3-a"}},
+				},
+			},
+			"machineTypes": []interface{}{
+				map[string]interface{}{"name": "n1-standard-4", "cpu": "4"},
+			},
+		},
+	}}
+
+	p := parseCloudProfile(obj)
+	require.Equal(t, map[string][]string{"europe-west3": {"europe-west3-a"}}, p.Regions)
+	require.Equal(t, []string{"n1-standard-4"}, p.MachineTypes)
+}
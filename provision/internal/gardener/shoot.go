@@ -0,0 +1,202 @@
+package gardener
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kyma-incubator/hydroform/provision/internal/credentials"
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/pkg/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// shootResource is the Gardener resource representing a cluster.
+var shootResource = schema.GroupVersionResource{Group: "core.gardener.cloud", Version: "v1beta1", Resource: "shoots"}
+
+// pollInterval is the time between two checks of the shoot while waiting for an operation to finish.
+var pollInterval = 15 * time.Second
+
+// healthConditions are the shoot conditions which must all be true for a cluster to be usable.
+var healthConditions = []string{"APIServerAvailable", "EveryNodeReady", "SystemComponentsHealthy"}
+
+// shootNamespace returns the namespace of the shoots in the garden cluster.
+// It is the namespace of the Gardener project, unless overridden with the namespace custom configuration.
+func shootNamespace(p *types.Provider) string {
+	if ns, ok := p.CustomConfigurations["namespace"].(string); ok && ns != "" {
+		return ns
+	}
+	return fmt.Sprintf("garden-%s", p.ProjectName)
+}
+
+// gardenConfig creates the client configuration for the garden cluster from the kubeconfig of the Gardener service account.
+func gardenConfig(p *types.Provider) (*rest.Config, error) {
+	kubeconfig, err := credentials.Gardener(p)
+	if err != nil {
+		return nil, err
+	}
+	return clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+}
+
+// gardenClient creates a client for the garden cluster from the kubeconfig of the Gardener service account.
+func gardenClient(p *types.Provider) (dynamic.Interface, error) {
+	config, err := gardenConfig(p)
+	if err != nil {
+		return nil, err
+	}
+	return dynamic.NewForConfig(config)
+}
+
+// shootStatus reads the status of the shoot from the garden cluster.
+func shootStatus(garden dynamic.Interface, namespace, name string) (*types.ClusterStatus, error) {
+	cs := &types.ClusterStatus{
+		Phase: types.Unknown,
+	}
+
+	shoot, err := getShoot(garden, namespace, name)
+	if err != nil {
+		return cs, err
+	}
+
+	if op, found, _ := unstructured.NestedMap(shoot.Object, "status", "lastOperation"); found {
+		cs.LastOperation = &types.Operation{
+			Type:        stringField(op, "type"),
+			State:       stringField(op, "state"),
+			Progress:    intField(op, "progress"),
+			Description: stringField(op, "description"),
+		}
+		if t, err := time.Parse(time.RFC3339, stringField(op, "lastUpdateTime")); err == nil {
+			cs.LastOperation.LastUpdateTime = t
+		}
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(shoot.Object, "status", "conditions")
+	for _, c := range conditions {
+		if m, ok := c.(map[string]interface{}); ok {
+			cs.Conditions = append(cs.Conditions, types.Condition{
+				Type:    stringField(m, "type"),
+				Status:  stringField(m, "status"),
+				Reason:  stringField(m, "reason"),
+				Message: stringField(m, "message"),
+			})
+		}
+	}
+
+	cs.Phase = shootPhase(cs)
+	if hibernated, _, _ := unstructured.NestedBool(shoot.Object, "status", "hibernated"); hibernated && cs.Phase != types.Errored && cs.Phase != types.Deprovisioning {
+		cs.Phase = types.Hibernated
+	}
+	return cs, nil
+}
+
+// setHibernation hibernates or wakes up the shoot and waits until Gardener finished the operation or the timeout is reached.
+func setHibernation(garden dynamic.Interface, namespace, name string, hibernated bool, timeout time.Duration) error {
+	patch := []byte(fmt.Sprintf(`{"spec":{"hibernation":{"enabled":%t}}}`, hibernated))
+	if _, err := garden.Resource(shootResource).Namespace(namespace).Patch(name, k8stypes.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return types.Classify(types.ErrorClassNotFound, errors.Errorf("shoot %s not found in namespace %s", name, namespace))
+		}
+		return errors.Wrap(err, "could not update the hibernation of the shoot")
+	}
+
+	err := wait.PollImmediate(pollInterval, timeout, func() (bool, error) {
+		shoot, err := getShoot(garden, namespace, name)
+		if err != nil {
+			return false, err
+		}
+
+		// the shoot reports the hibernation only once all its components are scaled accordingly
+		if done, _, _ := unstructured.NestedBool(shoot.Object, "status", "hibernated"); done != hibernated {
+			return false, nil
+		}
+
+		op, _, _ := unstructured.NestedMap(shoot.Object, "status", "lastOperation")
+		switch stringField(op, "state") {
+		case "Pending", "Processing":
+			return false, nil
+		case "Error", "Failed", "Aborted":
+			return false, errors.Errorf("%s of shoot %s failed: %s", stringField(op, "type"), name, stringField(op, "description"))
+		}
+		return true, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return &types.TimeoutError{
+			Phase:   "hibernation",
+			Timeout: timeout,
+			Err:     errors.Errorf("shoot %s did not finish the hibernation change", name),
+		}
+	}
+	return err
+}
+
+// getShoot reads the shoot from the given namespace in the garden cluster.
+func getShoot(garden dynamic.Interface, namespace, name string) (*unstructured.Unstructured, error) {
+	shoot, err := garden.Resource(shootResource).Namespace(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, types.Classify(types.ErrorClassNotFound, errors.Errorf("shoot %s not found in namespace %s", name, namespace))
+		}
+		return nil, errors.Wrap(err, "could not get the shoot from the garden cluster")
+	}
+	return shoot, nil
+}
+
+// shootPhase derives the phase of the cluster from the last operation and the health conditions of the shoot.
+func shootPhase(cs *types.ClusterStatus) types.Phase {
+	if cs.LastOperation == nil {
+		return types.Unknown
+	}
+
+	switch cs.LastOperation.State {
+	case "Error", "Failed", "Aborted":
+		return types.Errored
+	case "Pending", "Processing":
+		switch cs.LastOperation.Type {
+		case "Create":
+			return types.Provisioning
+		case "Delete":
+			return types.Deprovisioning
+		}
+	}
+
+	for _, h := range healthConditions {
+		healthy := false
+		for _, c := range cs.Conditions {
+			if c.Type == h {
+				if c.Status == "False" {
+					return types.Errored
+				}
+				healthy = c.Status == "True"
+			}
+		}
+		if !healthy {
+			// conditions are still being checked, for example during a reconciliation
+			return types.Unknown
+		}
+	}
+	return types.Provisioned
+}
+
+func stringField(m map[string]interface{}, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+func intField(m map[string]interface{}, key string) int {
+	switch v := m[key].(type) {
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
@@ -0,0 +1,145 @@
+package gardener
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestShootStatus(t *testing.T) {
+	healthy := []interface{}{
+		map[string]interface{}{"type": "APIServerAvailable", "status": "True"},
+		map[string]interface{}{"type": "EveryNodeReady", "status": "True"},
+		map[string]interface{}{"type": "SystemComponentsHealthy", "status": "True"},
+	}
+	unhealthy := []interface{}{
+		map[string]interface{}{"type": "APIServerAvailable", "status": "True"},
+		map[string]interface{}{"type": "EveryNodeReady", "status": "False", "reason": "NodesUnhealthy", "message": "Node worker-1 is not ready"},
+		map[string]interface{}{"type": "SystemComponentsHealthy", "status": "True"},
+	}
+
+	tests := []struct {
+		name       string
+		opType     string
+		opState    string
+		conditions []interface{}
+		phase      types.Phase
+	}{
+		{name: "creating", opType: "Create", opState: "Processing", phase: types.Provisioning},
+		{name: "created", opType: "Create", opState: "Succeeded", conditions: healthy, phase: types.Provisioned},
+		{name: "unhealthy", opType: "Reconcile", opState: "Succeeded", conditions: unhealthy, phase: types.Errored},
+		{name: "failed", opType: "Create", opState: "Error", phase: types.Errored},
+		{name: "deleting", opType: "Delete", opState: "Processing", conditions: healthy, phase: types.Deprovisioning},
+		{name: "checking", opType: "Reconcile", opState: "Processing", phase: types.Unknown},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			shoot := &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "core.gardener.cloud/v1beta1",
+				"kind":       "Shoot",
+				"metadata": map[string]interface{}{
+					"name":      "hydro-cluster",
+					"namespace": "garden-my-project",
+				},
+				"status": map[string]interface{}{
+					"lastOperation": map[string]interface{}{
+						"type":           tc.opType,
+						"state":          tc.opState,
+						"progress":       int64(42),
+						"description":    "Waiting for the shoot",
+						"lastUpdateTime": "2020-01-02T15:04:05Z",
+					},
+					"conditions": tc.conditions,
+				},
+			}}
+			garden := fake.NewSimpleDynamicClient(runtime.NewScheme(), shoot)
+
+			cs, err := shootStatus(garden, "garden-my-project", "hydro-cluster")
+			require.NoError(t, err)
+			require.Equal(t, tc.phase, cs.Phase)
+			require.Equal(t, tc.opType, cs.LastOperation.Type)
+			require.Equal(t, 42, cs.LastOperation.Progress)
+			require.Equal(t, 2020, cs.LastOperation.LastUpdateTime.Year())
+			require.Len(t, cs.Conditions, len(tc.conditions))
+		})
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		garden := fake.NewSimpleDynamicClient(runtime.NewScheme())
+
+		cs, err := shootStatus(garden, "garden-my-project", "hydro-cluster")
+		require.Error(t, err)
+		require.Equal(t, types.Unknown, cs.Phase)
+	})
+}
+
+func TestSetHibernation(t *testing.T) {
+	defer func(i time.Duration) { pollInterval = i }(pollInterval)
+	pollInterval = 10 * time.Millisecond
+
+	newShoot := func(hibernated bool, state string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "core.gardener.cloud/v1beta1",
+			"kind":       "Shoot",
+			"metadata": map[string]interface{}{
+				"name":      "hydro-cluster",
+				"namespace": "garden-my-project",
+			},
+			"status": map[string]interface{}{
+				"hibernated": hibernated,
+				"lastOperation": map[string]interface{}{
+					"type":        "Reconcile",
+					"state":       state,
+					"description": "Hibernating the cluster",
+				},
+			},
+		}}
+	}
+
+	t.Run("hibernated", func(t *testing.T) {
+		garden := fake.NewSimpleDynamicClient(runtime.NewScheme(), newShoot(true, "Succeeded"))
+
+		require.NoError(t, setHibernation(garden, "garden-my-project", "hydro-cluster", true, time.Second))
+
+		shoot, err := getShoot(garden, "garden-my-project", "hydro-cluster")
+		require.NoError(t, err)
+		enabled, _, _ := unstructured.NestedBool(shoot.Object, "spec", "hibernation", "enabled")
+		require.True(t, enabled, "The hibernation should be enabled in the shoot spec")
+
+		cs, err := shootStatus(garden, "garden-my-project", "hydro-cluster")
+		require.NoError(t, err)
+		require.Equal(t, types.Hibernated, cs.Phase)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		garden := fake.NewSimpleDynamicClient(runtime.NewScheme(), newShoot(true, "Processing"))
+
+		require.Error(t, setHibernation(garden, "garden-my-project", "hydro-cluster", false, 50*time.Millisecond), "Waking up should time out while the shoot is hibernated")
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		garden := fake.NewSimpleDynamicClient(runtime.NewScheme(), newShoot(false, "Error"))
+
+		require.Error(t, setHibernation(garden, "garden-my-project", "hydro-cluster", false, time.Second), "A failed operation should be reported")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		garden := fake.NewSimpleDynamicClient(runtime.NewScheme())
+
+		require.Error(t, setHibernation(garden, "garden-my-project", "hydro-cluster", true, time.Second))
+	})
+}
+
+func TestShootNamespace(t *testing.T) {
+	p := &types.Provider{ProjectName: "my-project"}
+	require.Equal(t, "garden-my-project", shootNamespace(p))
+
+	p.CustomConfigurations = map[string]interface{}{"namespace": "garden-other"}
+	require.Equal(t, "garden-other", shootNamespace(p), "The namespace custom configuration should take precedence")
+}
@@ -2,13 +2,18 @@ package gcp
 
 import (
 	"fmt"
+	"net"
 	"regexp"
+	"strings"
 
-	"github.com/hashicorp/terraform/states/statefile"
+	"github.com/kyma-incubator/hydroform/provision/internal/credentials"
 	"github.com/kyma-incubator/hydroform/provision/internal/errs"
+	"github.com/kyma-incubator/hydroform/provision/internal/labels"
 	terraform_operator "github.com/kyma-incubator/hydroform/provision/internal/operator/terraform"
 
 	"github.com/kyma-incubator/hydroform/provision/internal/operator"
+	"github.com/kyma-incubator/hydroform/provision/internal/tracing"
+	"github.com/kyma-incubator/hydroform/provision/internal/versions"
 	"github.com/kyma-incubator/hydroform/provision/types"
 	"github.com/pkg/errors"
 	"k8s.io/client-go/tools/clientcmd"
@@ -18,6 +23,12 @@ import (
 // gcpProvisioner implements Provisioner
 type gcpProvisioner struct {
 	provisionOperator operator.Operator
+	// newCloud creates the GCP client used by the pre-flight checks.
+	newCloud func(key []byte) (cloud, error)
+	// versions provides the Kubernetes versions the requested version is resolved against.
+	versions types.VersionSource
+	// tracer traces the validation and the generation of kubeconfigs.
+	tracer *tracing.Tracer
 }
 
 // Provision requests provisioning of a new Kubernetes cluster on GCP with the given configurations.
@@ -37,11 +48,65 @@ func (g *gcpProvisioner) Provision(cluster *types.Cluster, provider *types.Provi
 	return cluster, nil
 }
 
+// Import brings an existing GCP cluster under management, as if it was provisioned with the given configurations.
+// It returns the cluster with its ClusterInfo and the settings in which the existing cluster differs from the configurations.
+func (g *gcpProvisioner) Import(cluster *types.Cluster, provider *types.Provider, id string) (*types.Cluster, []types.Difference, error) {
+	if err := g.validateInputs(cluster, provider); err != nil {
+		return cluster, nil, err
+	}
+
+	config := g.loadConfigurations(cluster, provider)
+
+	clusterInfo, diffs, err := g.provisionOperator.Import(provider.Type, config, id)
+	if err != nil {
+		return cluster, nil, errors.Wrap(err, "unable to import gcp cluster")
+	}
+
+	cluster.ClusterInfo = clusterInfo
+	return cluster, diffs, nil
+}
+
+// Eject writes a standalone terraform project for the requested cluster into the given directory, so that it can be managed without Hydroform.
+func (g *gcpProvisioner) Eject(cluster *types.Cluster, p *types.Provider, dir string) error {
+	if err := g.validateInputs(cluster, p); err != nil {
+		return err
+	}
+
+	config := g.loadConfigurations(cluster, p)
+
+	var state *types.InternalState
+	if cluster.ClusterInfo != nil {
+		state = cluster.ClusterInfo.InternalState
+	}
+
+	if err := g.provisionOperator.Eject(state, p.Type, config, dir); err != nil {
+		return errors.Wrap(err, "unable to eject gcp cluster")
+	}
+	return nil
+}
+
+// Rollback restores the version of the state of the requested cluster with the given serial and applies the configuration the GCP cluster had at that version.
+func (g *gcpProvisioner) Rollback(cluster *types.Cluster, p *types.Provider, serial uint64) (*types.Cluster, error) {
+	if err := g.validateInputs(cluster, p); err != nil {
+		return cluster, err
+	}
+
+	config := g.loadConfigurations(cluster, p)
+
+	clusterInfo, err := g.provisionOperator.Rollback(p.Type, config, serial)
+	if err != nil {
+		return cluster, errors.Wrap(err, "unable to roll back gcp cluster")
+	}
+
+	cluster.ClusterInfo = clusterInfo
+	return cluster, nil
+}
+
 // Status returns the ClusterStatus for the requested cluster.
 func (g *gcpProvisioner) Status(cluster *types.Cluster, p *types.Provider) (*types.ClusterStatus, error) {
-	var state *statefile.File
-	if cluster.ClusterInfo != nil && cluster.ClusterInfo.InternalState != nil {
-		state = cluster.ClusterInfo.InternalState.TerraformState
+	var state *types.InternalState
+	if cluster.ClusterInfo != nil {
+		state = cluster.ClusterInfo.InternalState
 	}
 
 	if err := g.validateInputs(cluster, p); err != nil {
@@ -54,10 +119,14 @@ func (g *gcpProvisioner) Status(cluster *types.Cluster, p *types.Provider) (*typ
 }
 
 // Credentials returns the Kubeconfig file as a byte array for the requested cluster.
-func (g *gcpProvisioner) Credentials(cluster *types.Cluster, p *types.Provider) ([]byte, error) {
+func (g *gcpProvisioner) Credentials(cluster *types.Cluster, p *types.Provider) (data []byte, err error) {
 	if err := g.validateInputs(cluster, p); err != nil {
 		return nil, err
 	}
+
+	_, span := g.tracer.Start("kubeconfig")
+	defer func() { tracing.End(span, err) }()
+
 	if cluster.ClusterInfo == nil || cluster.ClusterInfo.Endpoint == "" || cluster.ClusterInfo.CertificateAuthorityData == nil {
 		// TODO add a way to get endpoint and CA from the state file if possible
 		return nil, errors.New(errs.EmptyClusterInfo)
@@ -89,15 +158,17 @@ func (g *gcpProvisioner) Credentials(cluster *types.Cluster, p *types.Provider)
 
 // Deprovision requests deprovisioning of an existing cluster on GCP with the given configurations.
 func (g *gcpProvisioner) Deprovision(cluster *types.Cluster, p *types.Provider) error {
+	// the version is not resolved, the cluster is deleted whichever version it runs
+	cluster = versions.Unresolved(cluster)
 	if err := g.validateInputs(cluster, p); err != nil {
 		return err
 	}
 
 	config := g.loadConfigurations(cluster, p)
 
-	var state *statefile.File
-	if cluster.ClusterInfo != nil && cluster.ClusterInfo.InternalState != nil {
-		state = cluster.ClusterInfo.InternalState.TerraformState
+	var state *types.InternalState
+	if cluster.ClusterInfo != nil {
+		state = cluster.ClusterInfo.InternalState
 	}
 
 	err := g.provisionOperator.Delete(state, p.Type, config)
@@ -127,10 +198,16 @@ func New(operatorType operator.Type, ops ...types.Option) *gcpProvisioner {
 
 	return &gcpProvisioner{
 		provisionOperator: op,
+		newCloud:          newGCPCloud,
+		versions:          os.VersionSource,
+		tracer:            tracing.New(os),
 	}
 }
 
-func (g *gcpProvisioner) validateInputs(cluster *types.Cluster, provider *types.Provider) error {
+func (g *gcpProvisioner) validateInputs(cluster *types.Cluster, provider *types.Provider) (err error) {
+	_, span := g.tracer.Start("validate")
+	defer func() { tracing.End(span, err) }()
+
 	var errMessage string
 	if cluster.NodeCount < 1 {
 		errMessage += fmt.Sprintf(errs.CannotBeLess, "Cluster.NodeCount", 1)
@@ -148,20 +225,25 @@ func (g *gcpProvisioner) validateInputs(cluster *types.Cluster, provider *types.
 	}
 	if cluster.KubernetesVersion == "" {
 		errMessage += fmt.Sprintf(errs.CannotBeEmpty, "Cluster.KubernetesVersion")
+	} else if _, err := versions.ForCluster(g.versions, types.GCP, cluster); err != nil {
+		errMessage += fmt.Sprintf(errs.Custom, err.Error())
 	}
 	if cluster.DiskSizeGB < 0 {
 		errMessage += fmt.Sprintf(errs.CannotBeLess, "Cluster.DiskSizeGB", 0)
 	}
 
-	if provider.CredentialsFilePath == "" {
-		errMessage += fmt.Sprintf(errs.CannotBeEmpty, "Provider.CredentialsFilePath")
+	if _, err := credentials.GCP(provider); err != nil {
+		errMessage += fmt.Sprintf(errs.Custom, err.Error())
 	}
 	if provider.ProjectName == "" {
 		errMessage += fmt.Sprintf(errs.CannotBeEmpty, "Provider.ProjectName")
 	}
 
+	errMessage += validateNetworking(provider.CustomConfigurations)
+	errMessage += labels.Validate(types.GCP, labels.ForCluster(cluster))
+
 	if errMessage != "" {
-		return errors.New("input validation failed with the following information: " + errMessage)
+		return types.Classify(types.ErrorClassValidation, errors.New("input validation failed with the following information: "+errMessage))
 	}
 
 	return nil
@@ -173,12 +255,105 @@ func (g *gcpProvisioner) loadConfigurations(cluster *types.Cluster, provider *ty
 	config["node_count"] = cluster.NodeCount
 	config["machine_type"] = cluster.MachineType
 	config["disk_size"] = cluster.DiskSizeGB
-	config["kubernetes_version"] = cluster.KubernetesVersion
+	// the version is validated already
+	version, _ := versions.ForCluster(g.versions, types.GCP, cluster)
+	config["kubernetes_version"] = version
 	config["location"] = cluster.Location
 	config["project"] = provider.ProjectName
-	config["credentials_file_path"] = provider.CredentialsFilePath
+	// the credentials are validated already, they are passed to terraform in a private vars file of the operation
+	key, _ := credentials.GCP(provider)
+	config["credentials"] = operator.Sensitive(key)
+	config["labels"] = labels.ForCluster(cluster)
 	for k, v := range provider.CustomConfigurations {
 		config[k] = v
 	}
 	return config
 }
+
+// validateNetworking checks the optional networking configuration of the cluster.
+// Without any of it, the cluster is created on the default network with a public endpoint.
+func validateNetworking(cfg map[string]interface{}) string {
+	var errMessage string
+
+	flags := map[string]bool{}
+	for _, k := range []string{"vpc_native", "private_nodes", "private_endpoint"} {
+		v, ok := cfg[k]
+		if !ok {
+			continue
+		}
+		b, ok := v.(bool)
+		if !ok {
+			errMessage += fmt.Sprintf(errs.Custom, fmt.Sprintf("Provider.CustomConfigurations['%s'] must be a boolean", k))
+		}
+		flags[k] = b
+	}
+
+	for _, k := range []string{"network", "subnetwork", "cluster_secondary_range_name", "services_secondary_range_name"} {
+		if v, ok := cfg[k]; ok {
+			if _, ok := v.(string); !ok {
+				errMessage += fmt.Sprintf(errs.Custom, fmt.Sprintf("Provider.CustomConfigurations['%s'] must be a string", k))
+			}
+		}
+	}
+	if _, ok := cfg["subnetwork"]; ok {
+		if _, ok := cfg["network"]; !ok {
+			errMessage += fmt.Sprintf(errs.Custom, "Provider.CustomConfigurations['network'] is required when a subnetwork is set")
+		}
+	}
+
+	for _, k := range []string{"cluster_ipv4_cidr_block", "services_ipv4_cidr_block", "cluster_secondary_range_name", "services_secondary_range_name"} {
+		if _, ok := cfg[k]; ok && !flags["vpc_native"] {
+			errMessage += fmt.Sprintf(errs.Custom, fmt.Sprintf("Provider.CustomConfigurations['%s'] requires a VPC-native cluster, set 'vpc_native' to true", k))
+		}
+	}
+	for _, k := range []string{"cluster_ipv4_cidr_block", "services_ipv4_cidr_block"} {
+		if v, ok := cfg[k]; ok {
+			if !isCIDR(v) {
+				errMessage += fmt.Sprintf(errs.Custom, fmt.Sprintf("Provider.CustomConfigurations['%s'] must be a CIDR block", k))
+			}
+		}
+	}
+
+	if flags["private_nodes"] {
+		if !flags["vpc_native"] {
+			errMessage += fmt.Sprintf(errs.Custom, "Private clusters must be VPC-native, set Provider.CustomConfigurations['vpc_native'] to true")
+		}
+		if v, ok := cfg["master_ipv4_cidr_block"]; !ok {
+			errMessage += fmt.Sprintf(errs.CannotBeEmpty, "Provider.CustomConfigurations['master_ipv4_cidr_block']")
+		} else if !isCIDR(v) || !strings.HasSuffix(v.(string), "/28") {
+			errMessage += fmt.Sprintf(errs.Custom, "Provider.CustomConfigurations['master_ipv4_cidr_block'] must be a /28 CIDR block")
+		}
+	}
+	if flags["private_endpoint"] {
+		if !flags["private_nodes"] {
+			errMessage += fmt.Sprintf(errs.Custom, "A private endpoint requires private nodes, set Provider.CustomConfigurations['private_nodes'] to true")
+		}
+		if _, ok := cfg["master_authorized_networks"]; !ok {
+			errMessage += fmt.Sprintf(errs.CannotBeEmpty, "Provider.CustomConfigurations['master_authorized_networks'] for a private endpoint")
+		}
+	}
+
+	if v, ok := cfg["master_authorized_networks"]; ok {
+		networks, ok := v.([]string)
+		if !ok {
+			errMessage += fmt.Sprintf(errs.Custom, "Provider.CustomConfigurations['master_authorized_networks'] must be a list of CIDR blocks")
+		}
+		for _, n := range networks {
+			if !isCIDR(n) {
+				errMessage += fmt.Sprintf(errs.Custom, fmt.Sprintf("Provider.CustomConfigurations['master_authorized_networks'] contains an invalid CIDR block: %s", n))
+			}
+		}
+	}
+
+	return errMessage
+}
+
+// isCIDR returns true if the given value is a string in CIDR notation.
+func isCIDR(v interface{}) bool {
+	s, ok := v.(string)
+	if !ok {
+		return false
+	}
+	_, _, err := net.ParseCIDR(s)
+	return err == nil
+}
@@ -2,13 +2,15 @@ package gcp
 
 import (
 	"fmt"
+	"io/ioutil"
 	"testing"
 
-	"github.com/hashicorp/terraform/states/statefile"
+	"github.com/kyma-incubator/hydroform/provision/internal/operator"
 	"github.com/kyma-incubator/hydroform/provision/internal/operator/mocks"
 	"github.com/pkg/errors"
 
 	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -27,7 +29,7 @@ func TestValidateInputs(t *testing.T) {
 	provider := &types.Provider{
 		Type:                types.GCP,
 		ProjectName:         "my-project",
-		CredentialsFilePath: "/path/to/credentials",
+		CredentialsFilePath: "testdata/service-account.json",
 		CustomConfigurations: map[string]interface{}{
 			"target_provider":        "gcp",
 			"target_secret":          "secret-name",
@@ -62,7 +64,9 @@ func TestValidateInputs(t *testing.T) {
 	cluster.Location = "type1"
 
 	cluster.KubernetesVersion = ""
-	require.Error(t, g.validateInputs(cluster, provider), "Validation should fail when Kubernetes version is empty")
+	err := g.validateInputs(cluster, provider)
+	require.Error(t, err, "Validation should fail when Kubernetes version is empty")
+	require.Equal(t, types.ErrorClassValidation, types.ErrorClass(err), "Validation errors should be classified")
 	cluster.KubernetesVersion = "1.12"
 
 	cluster.DiskSizeGB = 0
@@ -71,7 +75,7 @@ func TestValidateInputs(t *testing.T) {
 
 	provider.CredentialsFilePath = ""
 	require.Error(t, g.validateInputs(cluster, provider), "Validation should fail when credentials file path is empty")
-	provider.CredentialsFilePath = "/path/to/credentials"
+	provider.CredentialsFilePath = "testdata/service-account.json"
 
 	provider.ProjectName = ""
 	require.Error(t, g.validateInputs(cluster, provider), "Validation should fail when project name is empty")
@@ -106,7 +110,7 @@ func TestLoadConfigurations(t *testing.T) {
 	provider := &types.Provider{
 		Type:                types.GCP,
 		ProjectName:         "my-project",
-		CredentialsFilePath: "/path/to/credentials",
+		CredentialsFilePath: "testdata/service-account.json",
 		CustomConfigurations: map[string]interface{}{
 			"target_provider":        "gcp",
 			"target_secret":          "secret-name",
@@ -119,7 +123,9 @@ func TestLoadConfigurations(t *testing.T) {
 	config := g.loadConfigurations(cluster, provider)
 
 	require.Equal(t, cluster.Name, config["cluster_name"])
-	require.Equal(t, provider.CredentialsFilePath, config["credentials_file_path"])
+	credentials, err := ioutil.ReadFile(provider.CredentialsFilePath)
+	require.NoError(t, err)
+	require.Equal(t, operator.Sensitive(credentials), config["credentials"], "The credentials should be passed as sensitive value")
 	require.Equal(t, cluster.NodeCount, config["node_count"])
 	require.Equal(t, cluster.MachineType, config["machine_type"])
 	require.Equal(t, cluster.DiskSizeGB, config["disk_size"])
@@ -150,7 +156,7 @@ func TestProvision(t *testing.T) {
 	provider := &types.Provider{
 		Type:                types.GCP,
 		ProjectName:         "my-project",
-		CredentialsFilePath: "/path/to/credentials",
+		CredentialsFilePath: "testdata/service-account.json",
 		CustomConfigurations: map[string]interface{}{
 			"target_provider": "gcp",
 			"target_secret":   "secret-name",
@@ -174,6 +180,7 @@ func TestProvision(t *testing.T) {
 	cluster, err := g.Provision(cluster, provider)
 	require.NoError(t, err, "Provision should succeed")
 	require.Equal(t, result, cluster.ClusterInfo, "The cluster info returned from the operator should be in the cluster returned by Provision")
+	require.Empty(t, cluster.ClusterInfo.KubernetesVersion, "The requested Kubernetes version should not be reported as the running one")
 
 	badCluster := &types.Cluster{
 		CPU: 1,
@@ -203,7 +210,7 @@ func TestDeprovision(t *testing.T) {
 	provider := &types.Provider{
 		Type:                types.GCP,
 		ProjectName:         "my-project",
-		CredentialsFilePath: "/path/to/credentials",
+		CredentialsFilePath: "testdata/service-account.json",
 		CustomConfigurations: map[string]interface{}{
 			"target_provider":        "gcp",
 			"target_secret":          "secret-name",
@@ -213,7 +220,7 @@ func TestDeprovision(t *testing.T) {
 		},
 	}
 
-	var state *statefile.File
+	var state *types.InternalState
 	mockOp.On("Delete", state, types.GCP, g.loadConfigurations(cluster, provider)).Return(nil)
 
 	err := g.Deprovision(cluster, provider)
@@ -225,3 +232,189 @@ func TestDeprovision(t *testing.T) {
 	err = g.Deprovision(cluster, provider)
 	require.Error(t, err, "Deprovision should fail")
 }
+
+type failingSource struct{}
+
+func (failingSource) Versions(p types.ProviderType, location string) (*types.VersionCatalog, error) {
+	return nil, errors.New("version source not reachable")
+}
+
+func TestDeprovisionDoesNotResolveVersions(t *testing.T) {
+	mockOp := &mocks.Operator{}
+	g := gcpProvisioner{
+		provisionOperator: mockOp,
+		versions:          failingSource{},
+	}
+
+	cluster := &types.Cluster{
+		KubernetesVersion: "latest",
+		Name:              "hydro-cluster",
+		NodeCount:         2,
+		Location:          "europe-west3",
+		MachineType:       "type1",
+	}
+	provider := &types.Provider{
+		Type:                types.GCP,
+		ProjectName:         "my-project",
+		CredentialsFilePath: "testdata/service-account.json",
+	}
+
+	require.Error(t, g.validateInputs(cluster, provider), "Provisioning should need the version source")
+
+	var state *types.InternalState
+	mockOp.On("Delete", state, types.GCP, mock.MatchedBy(func(cfg map[string]interface{}) bool {
+		return cfg["kubernetes_version"] == "latest"
+	})).Return(nil)
+
+	require.NoError(t, g.Deprovision(cluster, provider), "Deprovision should not resolve the version of a cluster without cluster info")
+	require.Nil(t, cluster.ClusterInfo, "The cluster should not be changed")
+}
+
+func TestValidateNetworking(t *testing.T) {
+	require.Empty(t, validateNetworking(map[string]interface{}{}), "No networking configuration should keep the defaults")
+
+	cfg := map[string]interface{}{
+		"network":                    "my-vpc",
+		"subnetwork":                 "my-subnet",
+		"vpc_native":                 true,
+		"cluster_ipv4_cidr_block":    "10.4.0.0/14",
+		"services_ipv4_cidr_block":   "10.0.32.0/20",
+		"private_nodes":              true,
+		"private_endpoint":           true,
+		"master_ipv4_cidr_block":     "172.16.0.0/28",
+		"master_authorized_networks": []string{"10.0.0.0/8"},
+	}
+	require.Empty(t, validateNetworking(cfg), "Validation should pass")
+
+	cfg["vpc_native"] = "yes"
+	require.NotEmpty(t, validateNetworking(cfg), "Validation should fail when vpc_native is not a boolean")
+	cfg["vpc_native"] = false
+	require.NotEmpty(t, validateNetworking(cfg), "Validation should fail for private clusters that are not VPC-native")
+	cfg["vpc_native"] = true
+
+	delete(cfg, "network")
+	require.NotEmpty(t, validateNetworking(cfg), "Validation should fail when a subnetwork is set without network")
+	cfg["network"] = "my-vpc"
+
+	cfg["cluster_ipv4_cidr_block"] = "10.4.0.0"
+	require.NotEmpty(t, validateNetworking(cfg), "Validation should fail when the pods range is not a CIDR block")
+	cfg["cluster_ipv4_cidr_block"] = "10.4.0.0/14"
+
+	cfg["master_ipv4_cidr_block"] = "172.16.0.0/24"
+	require.NotEmpty(t, validateNetworking(cfg), "Validation should fail when the master range is not a /28")
+	delete(cfg, "master_ipv4_cidr_block")
+	require.NotEmpty(t, validateNetworking(cfg), "Validation should fail when the master range is missing for private nodes")
+	cfg["master_ipv4_cidr_block"] = "172.16.0.0/28"
+
+	cfg["master_authorized_networks"] = []string{"everyone"}
+	require.NotEmpty(t, validateNetworking(cfg), "Validation should fail for invalid authorized networks")
+	delete(cfg, "master_authorized_networks")
+	require.NotEmpty(t, validateNetworking(cfg), "Validation should fail for a private endpoint without authorized networks")
+	cfg["master_authorized_networks"] = []string{"10.0.0.0/8"}
+
+	cfg["private_nodes"] = false
+	require.NotEmpty(t, validateNetworking(cfg), "Validation should fail for a private endpoint without private nodes")
+}
+
+func TestImport(t *testing.T) {
+	mockOp := &mocks.Operator{}
+	g := gcpProvisioner{
+		provisionOperator: mockOp,
+	}
+
+	cluster := &types.Cluster{
+		KubernetesVersion: "1.15",
+		Name:              "hydro-cluster",
+		DiskSizeGB:        30,
+		NodeCount:         2,
+		Location:          "europe-west3",
+		MachineType:       "type1",
+	}
+	provider := &types.Provider{
+		Type:                types.GCP,
+		ProjectName:         "my-project",
+		CredentialsFilePath: "testdata/service-account.json",
+	}
+
+	result := &types.ClusterInfo{
+		Endpoint:          "https://cluster-url.fake",
+		KubernetesVersion: "1.15.9-gke.10",
+		Status: &types.ClusterStatus{
+			Phase: types.Provisioned,
+		},
+	}
+	diffs := []types.Difference{{Setting: "Cluster.MachineType", Requested: "type1", Actual: "type2"}}
+	mockOp.On("Import", types.GCP, g.loadConfigurations(cluster, provider), "my-project/europe-west3/hydro-cluster").Return(result, diffs, nil)
+
+	cluster, d, err := g.Import(cluster, provider, "my-project/europe-west3/hydro-cluster")
+	require.NoError(t, err, "Import should succeed")
+	require.Equal(t, result, cluster.ClusterInfo, "The cluster info returned from the operator should be in the cluster returned by Import")
+	require.Equal(t, "1.15.9-gke.10", cluster.ClusterInfo.KubernetesVersion, "The running Kubernetes version should be kept")
+	require.Equal(t, diffs, d, "The differences found by the operator should be returned")
+
+	cluster.Name = ""
+	_, _, err = g.Import(cluster, provider, "my-project/europe-west3/hydro-cluster")
+	require.Error(t, err, "Import should fail with invalid inputs")
+}
+
+func TestEject(t *testing.T) {
+	mockOp := &mocks.Operator{}
+	g := gcpProvisioner{
+		provisionOperator: mockOp,
+	}
+
+	state := &types.InternalState{}
+	cluster := &types.Cluster{
+		KubernetesVersion: "1.15",
+		Name:              "hydro-cluster",
+		DiskSizeGB:        30,
+		NodeCount:         2,
+		Location:          "europe-west3",
+		MachineType:       "type1",
+		ClusterInfo: &types.ClusterInfo{
+			InternalState: state,
+		},
+	}
+	provider := &types.Provider{
+		Type:                types.GCP,
+		ProjectName:         "my-project",
+		CredentialsFilePath: "testdata/service-account.json",
+	}
+
+	mockOp.On("Eject", state, types.GCP, g.loadConfigurations(cluster, provider), "ejected").Return(nil)
+	require.NoError(t, g.Eject(cluster, provider, "ejected"), "Eject should hand the cluster state to the operator")
+
+	mockOp.On("Eject", state, types.GCP, g.loadConfigurations(cluster, provider), "not-empty").Return(errors.New("the directory is not empty"))
+	require.Error(t, g.Eject(cluster, provider, "not-empty"), "Eject should fail if the operator fails")
+}
+
+func TestRollback(t *testing.T) {
+	mockOp := &mocks.Operator{}
+	g := gcpProvisioner{
+		provisionOperator: mockOp,
+	}
+
+	cluster := &types.Cluster{
+		KubernetesVersion: "1.15",
+		Name:              "hydro-cluster",
+		DiskSizeGB:        30,
+		NodeCount:         2,
+		Location:          "europe-west3",
+		MachineType:       "type1",
+	}
+	provider := &types.Provider{
+		Type:                types.GCP,
+		ProjectName:         "my-project",
+		CredentialsFilePath: "testdata/service-account.json",
+	}
+
+	info := &types.ClusterInfo{Endpoint: "1.2.3.4"}
+	mockOp.On("Rollback", types.GCP, g.loadConfigurations(cluster, provider), uint64(3)).Return(info, nil)
+	cl, err := g.Rollback(cluster, provider, 3)
+	require.NoError(t, err, "Rollback should hand the serial to the operator")
+	require.Equal(t, info, cl.ClusterInfo, "Rollback should return the information of the restored state")
+
+	mockOp.On("Rollback", types.GCP, g.loadConfigurations(cluster, provider), uint64(42)).Return(nil, errors.New("state version 42 not found"))
+	_, err = g.Rollback(cluster, provider, 42)
+	require.Error(t, err, "Rollback should fail if the operator fails")
+}
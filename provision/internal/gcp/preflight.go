@@ -0,0 +1,195 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/kyma-incubator/hydroform/provision/internal/credentials"
+	"github.com/kyma-incubator/hydroform/provision/internal/errs"
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/pkg/errors"
+	"google.golang.org/api/cloudresourcemanager/v1"
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+)
+
+// requiredPermissions are the permissions the service account needs on the project to manage GKE clusters.
+var requiredPermissions = []string{
+	"container.clusters.create",
+	"container.clusters.delete",
+	"container.clusters.get",
+	"container.clusters.update",
+	"container.operations.get",
+	"iam.serviceAccounts.actAs",
+}
+
+// zoneRegexp matches GCP zones, which are regions with a zone suffix, for example europe-west3-b.
+var zoneRegexp = regexp.MustCompile(`^[a-z]+-[a-z]+[0-9]+-[a-z]$`)
+
+// cloud is the part of the GCP API the pre-flight checks need. It can be stubbed in tests.
+type cloud interface {
+	// Project returns an error if the project does not exist or cannot be accessed.
+	Project(project string) error
+	// Permissions returns which of the given permissions the account has on the project.
+	Permissions(project string, permissions []string) ([]string, error)
+	// Zones returns the zones of a region, or the zone itself if the location is a zone.
+	// It returns no zones if the location does not exist.
+	Zones(project, location string) ([]string, error)
+	// MachineTypeExists checks if the machine type is available in the zone.
+	MachineTypeExists(project, zone, machineType string) (bool, error)
+}
+
+// Validate runs the input validation and checks against GCP that the cluster can be provisioned.
+func (g *gcpProvisioner) Validate(cluster *types.Cluster, provider *types.Provider) error {
+	if err := g.validateInputs(cluster, provider); err != nil {
+		return err
+	}
+
+	key, _ := credentials.GCP(provider)
+	c, err := g.newCloud(key)
+	if err != nil {
+		return types.Classify(types.ErrorClassCredentials, errors.Wrap(err, "unable to connect to gcp"))
+	}
+	return preflight(c, cluster, provider)
+}
+
+// preflight checks the project, the permissions of the account, the location and the machine type of the cluster.
+func preflight(c cloud, cluster *types.Cluster, provider *types.Provider) error {
+	var errMessage string
+
+	if err := c.Project(provider.ProjectName); err != nil {
+		// without access to the project, all other checks fail as well
+		return types.Classify(types.ErrorClassValidation, errors.New("pre-flight checks failed with the following information: "+fmt.Sprintf(errs.Custom, err.Error())))
+	}
+
+	granted, err := c.Permissions(provider.ProjectName, requiredPermissions)
+	if err != nil {
+		errMessage += fmt.Sprintf(errs.Custom, err.Error())
+	} else if missing := missingPermissions(requiredPermissions, granted); len(missing) > 0 {
+		errMessage += fmt.Sprintf(errs.Custom, fmt.Sprintf("the account is missing the permissions %s on project %s", strings.Join(missing, ", "), provider.ProjectName))
+	}
+
+	zones, err := c.Zones(provider.ProjectName, cluster.Location)
+	switch {
+	case err != nil:
+		errMessage += fmt.Sprintf(errs.Custom, err.Error())
+	case len(zones) == 0:
+		errMessage += fmt.Sprintf(errs.Custom, fmt.Sprintf("location %s does not exist", cluster.Location))
+	default:
+		// the nodes of a regional cluster are spread over its zones, the machine type has to be available in all of them
+		for _, z := range zones {
+			ok, err := c.MachineTypeExists(provider.ProjectName, z, cluster.MachineType)
+			if err != nil {
+				errMessage += fmt.Sprintf(errs.Custom, err.Error())
+				break
+			}
+			if !ok {
+				errMessage += fmt.Sprintf(errs.Custom, fmt.Sprintf("machine type %s is not available in zone %s", cluster.MachineType, z))
+			}
+		}
+	}
+
+	if errMessage != "" {
+		return types.Classify(types.ErrorClassValidation, errors.New("pre-flight checks failed with the following information: "+errMessage))
+	}
+	return nil
+}
+
+func missingPermissions(required, granted []string) []string {
+	has := make(map[string]bool, len(granted))
+	for _, p := range granted {
+		has[p] = true
+	}
+
+	var missing []string
+	for _, p := range required {
+		if !has[p] {
+			missing = append(missing, p)
+		}
+	}
+	return missing
+}
+
+// gcpCloud is the cloud implementation using the GCP APIs.
+type gcpCloud struct {
+	resources *cloudresourcemanager.Service
+	compute   *compute.Service
+}
+
+// newGCPCloud creates clients for the GCP APIs authenticated with the given service account key.
+func newGCPCloud(key []byte) (cloud, error) {
+	ctx := context.Background()
+	resources, err := cloudresourcemanager.NewService(ctx, option.WithCredentialsJSON(key))
+	if err != nil {
+		return nil, err
+	}
+	cs, err := compute.NewService(ctx, option.WithCredentialsJSON(key))
+	if err != nil {
+		return nil, err
+	}
+	return &gcpCloud{resources: resources, compute: cs}, nil
+}
+
+func (c *gcpCloud) Project(project string) error {
+	if _, err := c.resources.Projects.Get(project).Do(); err != nil {
+		if isCode(err, http.StatusForbidden) || isCode(err, http.StatusNotFound) {
+			return errors.Errorf("project %s does not exist or the account has no access to it", project)
+		}
+		return errors.Wrapf(err, "could not get project %s", project)
+	}
+	return nil
+}
+
+func (c *gcpCloud) Permissions(project string, permissions []string) ([]string, error) {
+	res, err := c.resources.Projects.TestIamPermissions(project, &cloudresourcemanager.TestIamPermissionsRequest{Permissions: permissions}).Do()
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not check the permissions on project %s", project)
+	}
+	return res.Permissions, nil
+}
+
+func (c *gcpCloud) Zones(project, location string) ([]string, error) {
+	if zoneRegexp.MatchString(location) {
+		if _, err := c.compute.Zones.Get(project, location).Do(); err != nil {
+			if isCode(err, http.StatusNotFound) {
+				return nil, nil
+			}
+			return nil, errors.Wrapf(err, "could not get zone %s", location)
+		}
+		return []string{location}, nil
+	}
+
+	region, err := c.compute.Regions.Get(project, location).Do()
+	if err != nil {
+		if isCode(err, http.StatusNotFound) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "could not get region %s", location)
+	}
+	zones := make([]string, 0, len(region.Zones))
+	for _, z := range region.Zones {
+		// zones are returned as URLs
+		zones = append(zones, path.Base(z))
+	}
+	return zones, nil
+}
+
+func (c *gcpCloud) MachineTypeExists(project, zone, machineType string) (bool, error) {
+	if _, err := c.compute.MachineTypes.Get(project, zone, machineType).Do(); err != nil {
+		if isCode(err, http.StatusNotFound) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "could not get machine type %s in zone %s", machineType, zone)
+	}
+	return true, nil
+}
+
+func isCode(err error, code int) bool {
+	gErr, ok := err.(*googleapi.Error)
+	return ok && gErr.Code == code
+}
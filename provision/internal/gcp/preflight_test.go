@@ -0,0 +1,96 @@
+package gcp
+
+import (
+	"testing"
+
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCloud is a GCP project with a single region.
+type fakeCloud struct {
+	projectErr   error
+	permissions  []string
+	machineTypes map[string][]string
+}
+
+func (f *fakeCloud) Project(project string) error {
+	return f.projectErr
+}
+
+func (f *fakeCloud) Permissions(project string, permissions []string) ([]string, error) {
+	return f.permissions, nil
+}
+
+func (f *fakeCloud) Zones(project, location string) ([]string, error) {
+	switch location {
+	case "europe-west3":
+		return []string{"europe-west3-a", "europe-west3-b"}, nil
+	case "europe-west3-a", "europe-west3-b":
+		return []string{location}, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeCloud) MachineTypeExists(project, zone, machineType string) (bool, error) {
+	for _, mt := range f.machineTypes[zone] {
+		if mt == machineType {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestValidatePreflight(t *testing.T) {
+	c := &fakeCloud{
+		permissions: requiredPermissions,
+		machineTypes: map[string][]string{
+			"europe-west3-a": {"n1-standard-4", "n1-standard-8"},
+			"europe-west3-b": {"n1-standard-4"},
+		},
+	}
+	g := gcpProvisioner{
+		newCloud: func(key []byte) (cloud, error) {
+			require.NotEmpty(t, key, "The cloud should be created with the service account key")
+			return c, nil
+		},
+	}
+
+	cluster := &types.Cluster{
+		KubernetesVersion: "1.15",
+		Name:              "hydro-cluster",
+		DiskSizeGB:        30,
+		NodeCount:         2,
+		Location:          "europe-west3",
+		MachineType:       "n1-standard-4",
+	}
+	provider := &types.Provider{
+		Type:                types.GCP,
+		ProjectName:         "my-project",
+		CredentialsFilePath: "testdata/service-account.json",
+	}
+	require.NoError(t, g.Validate(cluster, provider), "Pre-flight checks should pass")
+
+	cluster.MachineType = "n1-standard-8"
+	require.Error(t, g.Validate(cluster, provider), "The machine type has to be available in all zones of a region")
+	cluster.Location = "europe-west3-a"
+	require.NoError(t, g.Validate(cluster, provider), "The machine type is available in the zone")
+	cluster.Location = "mars-north1"
+	require.Error(t, g.Validate(cluster, provider), "Unknown locations should be reported")
+	cluster.Location = "europe-west3"
+	cluster.MachineType = "n1-standard-4"
+
+	c.permissions = []string{"container.clusters.get"}
+	err := g.Validate(cluster, provider)
+	require.Error(t, err, "Missing permissions should be reported")
+	require.Contains(t, err.Error(), "container.clusters.create")
+	c.permissions = requiredPermissions
+
+	c.projectErr = errors.New("project my-project does not exist or the account has no access to it")
+	require.Error(t, g.Validate(cluster, provider), "An inaccessible project should be reported")
+	c.projectErr = nil
+
+	provider.CredentialsFilePath = ""
+	require.Error(t, g.Validate(cluster, provider), "Input validation should run first")
+}
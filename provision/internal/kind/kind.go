@@ -4,20 +4,36 @@ import (
 	"fmt"
 	"regexp"
 
-	"github.com/hashicorp/terraform/states/statefile"
 	"github.com/kyma-incubator/hydroform/provision/internal/errs"
+	"github.com/kyma-incubator/hydroform/provision/internal/labels"
 	"github.com/kyma-incubator/hydroform/provision/internal/operator"
 	terraform_operator "github.com/kyma-incubator/hydroform/provision/internal/operator/terraform"
+	"github.com/kyma-incubator/hydroform/provision/internal/tracing"
+	"github.com/kyma-incubator/hydroform/provision/internal/versions"
 	"github.com/kyma-incubator/hydroform/provision/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/clientcmd/api"
 
 	"github.com/pkg/errors"
 )
 
+// nodeImage is the repository of the kind node images, which are tagged with the Kubernetes version they run.
+const nodeImage = "kindest/node"
+
+// conditionNodesLabeled reports whether the labels of the cluster were put on its nodes.
+const conditionNodesLabeled = "NodesLabeled"
+
 // kindProvisioner implements Provisioner
 type kindProvisioner struct {
 	provisionOperator operator.Operator
+	// versions provides the Kubernetes versions the requested version is resolved against.
+	versions types.VersionSource
+	// tracer traces the validation and the generation of kubeconfigs.
+	tracer *tracing.Tracer
+	// newClient creates the Kubernetes client used to label the nodes of a cluster.
+	newClient func(clusterName string) (kubernetes.Interface, error)
 }
 
 // Provision requests provisioning of a new Kubernetes cluster on Kind with the given configurations.
@@ -32,6 +48,76 @@ func (k *kindProvisioner) Provision(cluster *types.Cluster, p *types.Provider) (
 	if err != nil {
 		return cluster, errors.Wrap(err, "unable to provision kind cluster")
 	}
+	cluster.ClusterInfo = clusterInfo
+
+	// the cluster exists already, failing to label its nodes is reported in its status instead of failing the provisioning
+	if l := labels.ForCluster(cluster); len(l) > 0 {
+		err := k.tracer.Trace("label", func() error {
+			k8s, err := k.newClient(cluster.Name)
+			if err != nil {
+				return errors.Wrap(err, "unable to connect to kind cluster")
+			}
+			return errors.Wrap(labelNodes(k8s, l), "unable to label kind cluster nodes")
+		})
+		if err != nil {
+			if clusterInfo.Status == nil {
+				clusterInfo.Status = &types.ClusterStatus{Phase: types.Provisioned}
+			}
+			clusterInfo.Status.Conditions = append(clusterInfo.Status.Conditions, types.Condition{Type: conditionNodesLabeled, Status: "False", Reason: "LabelingFailed", Message: err.Error()})
+		}
+	}
+	return cluster, nil
+}
+
+// Import brings an existing Kind cluster under management, as if it was provisioned with the given configurations.
+// It returns the cluster with its ClusterInfo and the settings in which the existing cluster differs from the configurations.
+func (k *kindProvisioner) Import(cluster *types.Cluster, p *types.Provider, id string) (*types.Cluster, []types.Difference, error) {
+	if err := k.validateInputs(cluster, p); err != nil {
+		return cluster, nil, err
+	}
+
+	config := k.loadConfigurations(cluster, p)
+
+	clusterInfo, diffs, err := k.provisionOperator.Import(p.Type, config, id)
+	if err != nil {
+		return cluster, nil, errors.Wrap(err, "unable to import kind cluster")
+	}
+
+	cluster.ClusterInfo = clusterInfo
+	return cluster, diffs, nil
+}
+
+// Eject writes a standalone terraform project for the requested cluster into the given directory, so that it can be managed without Hydroform.
+func (k *kindProvisioner) Eject(cluster *types.Cluster, p *types.Provider, dir string) error {
+	if err := k.validateInputs(cluster, p); err != nil {
+		return err
+	}
+
+	config := k.loadConfigurations(cluster, p)
+
+	var state *types.InternalState
+	if cluster.ClusterInfo != nil {
+		state = cluster.ClusterInfo.InternalState
+	}
+
+	if err := k.provisionOperator.Eject(state, p.Type, config, dir); err != nil {
+		return errors.Wrap(err, "unable to eject kind cluster")
+	}
+	return nil
+}
+
+// Rollback restores the version of the state of the requested cluster with the given serial and applies the configuration the Kind cluster had at that version.
+func (k *kindProvisioner) Rollback(cluster *types.Cluster, p *types.Provider, serial uint64) (*types.Cluster, error) {
+	if err := k.validateInputs(cluster, p); err != nil {
+		return cluster, err
+	}
+
+	config := k.loadConfigurations(cluster, p)
+
+	clusterInfo, err := k.provisionOperator.Rollback(p.Type, config, serial)
+	if err != nil {
+		return cluster, errors.Wrap(err, "unable to roll back kind cluster")
+	}
 
 	cluster.ClusterInfo = clusterInfo
 	return cluster, nil
@@ -39,9 +125,9 @@ func (k *kindProvisioner) Provision(cluster *types.Cluster, p *types.Provider) (
 
 // Status returns the ClusterStatus for the requested cluster.
 func (k *kindProvisioner) Status(cluster *types.Cluster, p *types.Provider) (*types.ClusterStatus, error) {
-	var state *statefile.File
-	if cluster.ClusterInfo != nil && cluster.ClusterInfo.InternalState != nil {
-		state = cluster.ClusterInfo.InternalState.TerraformState
+	var state *types.InternalState
+	if cluster.ClusterInfo != nil {
+		state = cluster.ClusterInfo.InternalState
 	}
 
 	if err := k.validateInputs(cluster, p); err != nil {
@@ -54,11 +140,23 @@ func (k *kindProvisioner) Status(cluster *types.Cluster, p *types.Provider) (*ty
 }
 
 // Credentials returns the Kubeconfig file as a byte array for the requested cluster.
-func (k *kindProvisioner) Credentials(cluster *types.Cluster, p *types.Provider) ([]byte, error) {
+func (k *kindProvisioner) Credentials(cluster *types.Cluster, p *types.Provider) (data []byte, err error) {
 	if err := k.validateInputs(cluster, p); err != nil {
 		return nil, err
 	}
-	if cluster.ClusterInfo == nil || cluster.ClusterInfo.InternalState == nil || cluster.ClusterInfo.InternalState.TerraformState == nil {
+
+	_, span := k.tracer.Start("kubeconfig")
+	defer func() { tracing.End(span, err) }()
+
+	if cluster.ClusterInfo == nil {
+		return nil, errors.New(errs.EmptyClusterInfo)
+	}
+	// the state may only be kept encrypted, for example after the cluster was serialized
+	sf, err := k.provisionOperator.State(cluster.ClusterInfo.InternalState)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read the cluster state")
+	}
+	if sf == nil {
 		// TODO add a way to get the kubeconfig from the state file if possible
 		return nil, errors.New(errs.EmptyClusterInfo)
 	}
@@ -93,15 +191,17 @@ func (k *kindProvisioner) Credentials(cluster *types.Cluster, p *types.Provider)
 
 // Deprovision requests deprovisioning of an existing cluster on Kind with the given configurations.
 func (k *kindProvisioner) Deprovision(cluster *types.Cluster, p *types.Provider) error {
+	// the version is not resolved, the cluster is deleted whichever version it runs
+	cluster = versions.Unresolved(cluster)
 	if err := k.validateInputs(cluster, p); err != nil {
 		return err
 	}
 
 	config := k.loadConfigurations(cluster, p)
 
-	var state *statefile.File
-	if cluster.ClusterInfo != nil && cluster.ClusterInfo.InternalState != nil {
-		state = cluster.ClusterInfo.InternalState.TerraformState
+	var state *types.InternalState
+	if cluster.ClusterInfo != nil {
+		state = cluster.ClusterInfo.InternalState
 	}
 
 	err := k.provisionOperator.Delete(state, p.Type, config)
@@ -131,10 +231,20 @@ func New(operatorType operator.Type, ops ...types.Option) *kindProvisioner {
 
 	return &kindProvisioner{
 		provisionOperator: op,
+		versions:          os.VersionSource,
+		tracer:            tracing.New(os),
+		newClient:         kindClient,
 	}
 }
 
-func (k *kindProvisioner) validateInputs(cluster *types.Cluster, provider *types.Provider) error {
+// Validate runs the input validation. Kind clusters run locally, so there is no account or quota to check.
+func (k *kindProvisioner) Validate(cluster *types.Cluster, p *types.Provider) error {
+	return k.validateInputs(cluster, p)
+}
+
+func (k *kindProvisioner) validateInputs(cluster *types.Cluster, provider *types.Provider) (err error) {
+	_, span := k.tracer.Start("validate")
+	defer func() { tracing.End(span, err) }()
 
 	var errMessage string
 	// Matches the regex for a GCP cluster name.
@@ -146,14 +256,21 @@ func (k *kindProvisioner) validateInputs(cluster *types.Cluster, provider *types
 		errMessage += fmt.Sprintf(errs.CannotBeEmpty, "Provider.ProjectName")
 	}
 
-	if provider.CustomConfigurations != nil {
+	if cluster.KubernetesVersion != "" {
+		if _, err := versions.ForCluster(k.versions, types.Kind, cluster); err != nil {
+			errMessage += fmt.Sprintf(errs.Custom, err.Error())
+		}
+	} else if provider.CustomConfigurations != nil {
+		// without a version, the node image decides which Kubernetes version runs
 		if _, ok := provider.CustomConfigurations["node_image"]; !ok {
 			errMessage += fmt.Sprintf(errs.CannotBeEmpty, "Provider.CustomConfiguration.node_image")
 		}
 	}
 
+	errMessage += labels.Validate(types.Kind, labels.ForCluster(cluster))
+
 	if errMessage != "" {
-		return errors.New("input validation failed with the following information: " + errMessage)
+		return types.Classify(types.ErrorClassValidation, errors.New("input validation failed with the following information: "+errMessage))
 	}
 
 	return nil
@@ -163,8 +280,49 @@ func (k *kindProvisioner) loadConfigurations(cluster *types.Cluster, p *types.Pr
 	config := map[string]interface{}{}
 	config["cluster_name"] = cluster.Name
 	config["project"] = p.ProjectName
+	if _, ok := p.CustomConfigurations["node_image"]; !ok && cluster.KubernetesVersion != "" {
+		// the version is validated already, an explicit node image takes precedence over it
+		version, _ := versions.ForCluster(k.versions, types.Kind, cluster)
+		config["kubernetes_version"] = version
+		config["node_image"] = fmt.Sprintf("%s:v%s", nodeImage, version)
+	}
 	for k, v := range p.CustomConfigurations {
 		config[k] = v
 	}
 	return config
 }
+
+// kindClient creates a kubernetes client for the given kind cluster from the context kind adds to the default kubeconfig.
+func kindClient(clusterName string) (kubernetes.Interface, error) {
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{CurrentContext: fmt.Sprintf("kind-%s", clusterName)},
+	).ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	return kubernetes.NewForConfig(config)
+}
+
+// labelNodes puts the given labels on all nodes of the cluster.
+// The kind terraform provider does not support node labels, so they are set through the Kubernetes API instead.
+func labelNodes(k8s kubernetes.Interface, l map[string]string) error {
+	nodes, err := k8s.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		if node.Labels == nil {
+			node.Labels = make(map[string]string)
+		}
+		for k, v := range l {
+			node.Labels[k] = v
+		}
+		if _, err := k8s.CoreV1().Nodes().Update(node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
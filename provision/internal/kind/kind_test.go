@@ -1,14 +1,24 @@
 package kind
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"testing"
 
+	"github.com/hashicorp/terraform/states"
 	"github.com/hashicorp/terraform/states/statefile"
+	"github.com/kyma-incubator/hydroform/provision/internal/encryption"
+	"github.com/kyma-incubator/hydroform/provision/internal/operator"
 	"github.com/kyma-incubator/hydroform/provision/internal/operator/mocks"
 	"github.com/kyma-incubator/hydroform/provision/types"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/clientcmd"
 )
 
 func TestValidateInputs(t *testing.T) {
@@ -43,6 +53,11 @@ func TestValidateInputs(t *testing.T) {
 
 	delete(provider.CustomConfigurations, "node_image")
 	require.Error(t, k.validateInputs(cluster, provider), "Validation should fail when target provider is empty")
+	cluster.KubernetesVersion = "1.16"
+	require.NoError(t, k.validateInputs(cluster, provider), "Validation should pass when the node image is derived from the Kubernetes version")
+	cluster.KubernetesVersion = "stable"
+	require.Error(t, k.validateInputs(cluster, provider), "Validation should fail when the Kubernetes version is neither a version nor an alias")
+	cluster.KubernetesVersion = ""
 	provider.CustomConfigurations["target_provider"] = "somerepo/image:v0.0.0"
 }
 
@@ -68,6 +83,15 @@ func TestLoadConfigurations(t *testing.T) {
 	for k, v := range provider.CustomConfigurations {
 		require.Equal(t, v, config[k], fmt.Sprintf("Custom config %s is incorrect", k))
 	}
+
+	cluster.KubernetesVersion = "1.16"
+	config = k.loadConfigurations(cluster, provider)
+	require.Equal(t, "somerepo/image:v0.0.0", config["node_image"], "An explicit node image should take precedence over the Kubernetes version")
+
+	delete(provider.CustomConfigurations, "node_image")
+	config = k.loadConfigurations(cluster, provider)
+	require.Equal(t, "kindest/node:v1.16.4", config["node_image"], "The node image should be derived from the resolved Kubernetes version")
+	require.Equal(t, "1.16.4", config["kubernetes_version"])
 }
 
 func TestProvision(t *testing.T) {
@@ -129,7 +153,7 @@ func TestDeprovision(t *testing.T) {
 		},
 	}
 
-	var state *statefile.File
+	var state *types.InternalState
 	mockOp.On("Delete", state, types.Kind, k.loadConfigurations(cluster, provider)).Return(nil)
 
 	err := k.Deprovision(cluster, provider)
@@ -141,3 +165,123 @@ func TestDeprovision(t *testing.T) {
 	err = k.Deprovision(cluster, provider)
 	require.Error(t, err, "Deprovision should fail")
 }
+
+func TestProvisionLabelingFails(t *testing.T) {
+	mockOp := &mocks.Operator{}
+	k := kindProvisioner{
+		provisionOperator: mockOp,
+		newClient: func(string) (kubernetes.Interface, error) {
+			return nil, errors.New("context kind-test-cluster does not exist")
+		},
+	}
+
+	cluster := &types.Cluster{
+		Name:   "test-cluster",
+		Labels: map[string]string{"team": "hydro"},
+	}
+	provider := &types.Provider{
+		Type:        types.Kind,
+		ProjectName: "my-project",
+	}
+	result := &types.ClusterInfo{Status: &types.ClusterStatus{Phase: types.Provisioned}}
+	mockOp.On("Create", types.Kind, k.loadConfigurations(cluster, provider)).Return(result, nil)
+
+	cluster, err := k.Provision(cluster, provider)
+	require.NoError(t, err, "A created cluster should be returned even if its nodes cannot be labeled")
+	require.Equal(t, types.Provisioned, cluster.ClusterInfo.Status.Phase)
+	require.Len(t, cluster.ClusterInfo.Status.Conditions, 1)
+	require.Equal(t, conditionNodesLabeled, cluster.ClusterInfo.Status.Conditions[0].Type)
+	require.Equal(t, "False", cluster.ClusterInfo.Status.Conditions[0].Status)
+	require.Contains(t, cluster.ClusterInfo.Status.Conditions[0].Message, "context kind-test-cluster does not exist")
+}
+
+func TestLabelNodes(t *testing.T) {
+	k8s := fake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "control-plane"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker", Labels: map[string]string{"existing": "label"}}},
+	)
+
+	err := labelNodes(k8s, map[string]string{"team": "hydro"})
+	require.NoError(t, err, "Labeling nodes should succeed")
+
+	nodes, err := k8s.CoreV1().Nodes().List(metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, nodes.Items, 2)
+	for _, n := range nodes.Items {
+		require.Equal(t, "hydro", n.Labels["team"], "All nodes should get the labels")
+	}
+	worker, err := k8s.CoreV1().Nodes().Get("worker", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "label", worker.Labels["existing"], "Existing labels should be kept")
+}
+
+func TestImport(t *testing.T) {
+	mockOp := &mocks.Operator{}
+	k := kindProvisioner{
+		provisionOperator: mockOp,
+	}
+
+	cluster := &types.Cluster{
+		Name:              "test-cluster",
+		KubernetesVersion: "1.16",
+	}
+	provider := &types.Provider{
+		Type:        types.Kind,
+		ProjectName: "my-project",
+	}
+
+	result := &types.ClusterInfo{
+		Endpoint:          "https://cluster-url.fake",
+		KubernetesVersion: "1.16.4",
+		Status: &types.ClusterStatus{
+			Phase: types.Provisioned,
+		},
+	}
+	diffs := []types.Difference{{Setting: "Cluster.MachineType", Requested: "type1", Actual: "type2"}}
+	mockOp.On("Import", types.Kind, k.loadConfigurations(cluster, provider), "test-cluster").Return(result, diffs, nil)
+
+	cluster, d, err := k.Import(cluster, provider, "test-cluster")
+	require.NoError(t, err, "Import should succeed")
+	require.Equal(t, result, cluster.ClusterInfo, "The cluster info returned from the operator should be in the cluster returned by Import")
+	require.Equal(t, "1.16.4", cluster.ClusterInfo.KubernetesVersion, "The running Kubernetes version should be kept")
+	require.Equal(t, diffs, d, "The differences found by the operator should be returned")
+
+	cluster.Name = ""
+	_, _, err = k.Import(cluster, provider, "test-cluster")
+	require.Error(t, err, "Import should fail with invalid inputs")
+}
+
+func TestCredentialsEncrypted(t *testing.T) {
+	cluster := &types.Cluster{
+		Name: "test-cluster",
+	}
+	provider := &types.Provider{
+		Type:        types.Kind,
+		ProjectName: "my-project",
+	}
+
+	sf := statefile.New(states.NewState(), "lineage", 1)
+	key, err := encryption.LoadKey(&types.EncryptionKey{Value: []byte("my-secret")})
+	require.NoError(t, err)
+	buf := &bytes.Buffer{}
+	require.NoError(t, statefile.Write(sf, buf))
+	enc, err := encryption.Encrypt(key, buf.Bytes())
+	require.NoError(t, err)
+
+	// a persisted cluster only keeps the encrypted state
+	cluster.ClusterInfo = &types.ClusterInfo{Endpoint: "127.0.0.1:6443", InternalState: &types.InternalState{TerraformState: sf, EncryptedTerraformState: enc}}
+	data, err := json.Marshal(cluster)
+	require.NoError(t, err)
+	loaded := &types.Cluster{}
+	require.NoError(t, json.Unmarshal(data, loaded))
+	require.Nil(t, loaded.ClusterInfo.InternalState.TerraformState)
+
+	kubeconfig, err := New(operator.TerraformOperator, types.WithEncryptionKey([]byte("my-secret"))).Credentials(loaded, provider)
+	require.NoError(t, err, "The credentials should be read from the encrypted state")
+	config, err := clientcmd.Load(kubeconfig)
+	require.NoError(t, err)
+	require.Equal(t, "https://127.0.0.1:6443", config.Clusters["test-cluster"].Server)
+
+	_, err = New(operator.TerraformOperator).Credentials(loaded, provider)
+	require.Error(t, err, "Reading an encrypted state without key should fail")
+}
@@ -0,0 +1,97 @@
+package labels
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kyma-incubator/hydroform/provision/internal/errs"
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	gcpMaxLabels     = 64
+	gcpMaxLength     = 63
+	azureMaxTags     = 50
+	azureMaxKeyLen   = 512
+	azureMaxValueLen = 256
+)
+
+var (
+	gcpKey   = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+	gcpValue = regexp.MustCompile(`^[a-z0-9_-]*$`)
+)
+
+// ForCluster returns the labels Hydroform puts on the provider resources of the given cluster.
+// These are the labels of the cluster plus the ones Hydroform manages itself, which take precedence.
+func ForCluster(cluster *types.Cluster) map[string]string {
+	l := make(map[string]string)
+	for k, v := range cluster.Labels {
+		l[k] = v
+	}
+
+	if !cluster.ExpiresAt.IsZero() {
+		// unix time only contains characters allowed in label values of all providers
+		l[types.ExpiresAtLabel] = strconv.FormatInt(cluster.ExpiresAt.Unix(), 10)
+	}
+	if cluster.Protected {
+		l[types.ProtectedLabel] = "true"
+	}
+	return l
+}
+
+// Validate checks the given labels against the label rules of the provider.
+// It returns the validation errors in the same format as the input validation of the provisioners, or an empty string if all labels are valid.
+func Validate(p types.ProviderType, l map[string]string) string {
+	var errMessage string
+	switch p {
+	case types.GCP:
+		if len(l) > gcpMaxLabels {
+			errMessage += fmt.Sprintf(errs.Custom, fmt.Sprintf("Cluster.Labels cannot contain more than %d labels", gcpMaxLabels))
+		}
+		for _, k := range sortedKeys(l) {
+			if len(k) > gcpMaxLength || !gcpKey.MatchString(k) {
+				errMessage += fmt.Sprintf(errs.Custom, fmt.Sprintf("Cluster.Labels key '%s' must start with a lowercase letter followed by up to %d lowercase letters, numbers, underscores or hyphens", k, gcpMaxLength-1))
+			}
+			if len(l[k]) > gcpMaxLength || !gcpValue.MatchString(l[k]) {
+				errMessage += fmt.Sprintf(errs.Custom, fmt.Sprintf("Cluster.Labels value of '%s' can only contain up to %d lowercase letters, numbers, underscores or hyphens", k, gcpMaxLength))
+			}
+		}
+	case types.Azure:
+		if len(l) > azureMaxTags {
+			errMessage += fmt.Sprintf(errs.Custom, fmt.Sprintf("Cluster.Labels cannot contain more than %d labels", azureMaxTags))
+		}
+		for _, k := range sortedKeys(l) {
+			if k == "" || len(k) > azureMaxKeyLen || strings.ContainsAny(k, `<>%&\?/`) {
+				errMessage += fmt.Sprintf(errs.Custom, fmt.Sprintf("Cluster.Labels key '%s' must have between 1 and %d characters and cannot contain any of <>%%&\\?/", k, azureMaxKeyLen))
+			}
+			if len(l[k]) > azureMaxValueLen {
+				errMessage += fmt.Sprintf(errs.Custom, fmt.Sprintf("Cluster.Labels value of '%s' cannot be longer than %d characters", k, azureMaxValueLen))
+			}
+		}
+	case types.Gardener, types.Kind:
+		// both are rendered as kubernetes labels
+		for _, k := range sortedKeys(l) {
+			for _, e := range validation.IsQualifiedName(k) {
+				errMessage += fmt.Sprintf(errs.Custom, fmt.Sprintf("Cluster.Labels key '%s' is invalid: %s", k, e))
+			}
+			for _, e := range validation.IsValidLabelValue(l[k]) {
+				errMessage += fmt.Sprintf(errs.Custom, fmt.Sprintf("Cluster.Labels value of '%s' is invalid: %s", k, e))
+			}
+		}
+	}
+	return errMessage
+}
+
+// sortedKeys returns the keys of the labels in alphabetical order, so that validation errors are always reported in the same order.
+func sortedKeys(l map[string]string) []string {
+	keys := make([]string, 0, len(l))
+	for k := range l {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,49 @@
+package labels
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForCluster(t *testing.T) {
+	cluster := &types.Cluster{Name: "my-cluster"}
+	require.Empty(t, ForCluster(cluster), "Clusters without labels, expiry or protection should have no labels")
+
+	cluster.Labels = map[string]string{"team": "hydro", types.ProtectedLabel: "false"}
+	cluster.ExpiresAt = time.Unix(1577836800, 0)
+	cluster.Protected = true
+	l := ForCluster(cluster)
+	require.Equal(t, "hydro", l["team"])
+	require.Equal(t, "1577836800", l[types.ExpiresAtLabel])
+	require.Equal(t, "true", l[types.ProtectedLabel], "Labels managed by Hydroform should take precedence")
+	require.Equal(t, "false", cluster.Labels[types.ProtectedLabel], "The cluster labels should not be modified")
+}
+
+func TestValidate(t *testing.T) {
+	valid := map[string]string{"team": "hydro", "cost-center": "1234"}
+	for _, p := range []types.ProviderType{types.GCP, types.Azure, types.Gardener, types.Kind} {
+		require.Empty(t, Validate(p, valid), "Labels should be valid for %s", p)
+		require.Empty(t, Validate(p, nil), "No labels should be valid for %s", p)
+	}
+
+	// GCP only allows lowercase keys and values
+	require.NotEmpty(t, Validate(types.GCP, map[string]string{"Team": "hydro"}))
+	require.NotEmpty(t, Validate(types.GCP, map[string]string{"team": "Hydro"}))
+	require.NotEmpty(t, Validate(types.GCP, map[string]string{"1team": "hydro"}))
+	require.NotEmpty(t, Validate(types.GCP, map[string]string{"team": strings.Repeat("a", 64)}))
+
+	// Azure tags are more permissive, except for some characters
+	require.Empty(t, Validate(types.Azure, map[string]string{"Team Name": "Hydro Form"}))
+	require.NotEmpty(t, Validate(types.Azure, map[string]string{"team/name": "hydro"}))
+	require.NotEmpty(t, Validate(types.Azure, map[string]string{"": "hydro"}))
+	require.NotEmpty(t, Validate(types.Azure, map[string]string{"team": strings.Repeat("a", 257)}))
+
+	// Gardener and kind follow the kubernetes label rules
+	require.Empty(t, Validate(types.Gardener, map[string]string{"kyma-project.io/team": "Hydro"}))
+	require.NotEmpty(t, Validate(types.Gardener, map[string]string{"team name": "hydro"}))
+	require.NotEmpty(t, Validate(types.Kind, map[string]string{"team": "hydro form"}))
+}
@@ -39,11 +39,11 @@ func (_m *Operator) Create(p types.ProviderType, cfg map[string]interface{}) (*t
 }
 
 // Delete provides a mock function with given fields: state, p, cfg
-func (_m *Operator) Delete(state *statefile.File, p types.ProviderType, cfg map[string]interface{}) error {
+func (_m *Operator) Delete(state *types.InternalState, p types.ProviderType, cfg map[string]interface{}) error {
 	ret := _m.Called(state, p, cfg)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*statefile.File, types.ProviderType, map[string]interface{}) error); ok {
+	if rf, ok := ret.Get(0).(func(*types.InternalState, types.ProviderType, map[string]interface{}) error); ok {
 		r0 = rf(state, p, cfg)
 	} else {
 		r0 = ret.Error(0)
@@ -52,12 +52,104 @@ func (_m *Operator) Delete(state *statefile.File, p types.ProviderType, cfg map[
 	return r0
 }
 
+// Eject provides a mock function with given fields: state, p, cfg, dir
+func (_m *Operator) Eject(state *types.InternalState, p types.ProviderType, cfg map[string]interface{}, dir string) error {
+	ret := _m.Called(state, p, cfg, dir)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*types.InternalState, types.ProviderType, map[string]interface{}, string) error); ok {
+		r0 = rf(state, p, cfg, dir)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Import provides a mock function with given fields: p, cfg, id
+func (_m *Operator) Import(p types.ProviderType, cfg map[string]interface{}, id string) (*types.ClusterInfo, []types.Difference, error) {
+	ret := _m.Called(p, cfg, id)
+
+	var r0 *types.ClusterInfo
+	if rf, ok := ret.Get(0).(func(types.ProviderType, map[string]interface{}, string) *types.ClusterInfo); ok {
+		r0 = rf(p, cfg, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.ClusterInfo)
+		}
+	}
+
+	var r1 []types.Difference
+	if rf, ok := ret.Get(1).(func(types.ProviderType, map[string]interface{}, string) []types.Difference); ok {
+		r1 = rf(p, cfg, id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]types.Difference)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(types.ProviderType, map[string]interface{}, string) error); ok {
+		r2 = rf(p, cfg, id)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Rollback provides a mock function with given fields: p, cfg, serial
+func (_m *Operator) Rollback(p types.ProviderType, cfg map[string]interface{}, serial uint64) (*types.ClusterInfo, error) {
+	ret := _m.Called(p, cfg, serial)
+
+	var r0 *types.ClusterInfo
+	if rf, ok := ret.Get(0).(func(types.ProviderType, map[string]interface{}, uint64) *types.ClusterInfo); ok {
+		r0 = rf(p, cfg, serial)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.ClusterInfo)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(types.ProviderType, map[string]interface{}, uint64) error); ok {
+		r1 = rf(p, cfg, serial)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// State provides a mock function with given fields: state
+func (_m *Operator) State(state *types.InternalState) (*statefile.File, error) {
+	ret := _m.Called(state)
+
+	var r0 *statefile.File
+	if rf, ok := ret.Get(0).(func(*types.InternalState) *statefile.File); ok {
+		r0 = rf(state)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*statefile.File)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*types.InternalState) error); ok {
+		r1 = rf(state)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Status provides a mock function with given fields: state, p, cfg
-func (_m *Operator) Status(state *statefile.File, p types.ProviderType, cfg map[string]interface{}) (*types.ClusterStatus, error) {
+func (_m *Operator) Status(state *types.InternalState, p types.ProviderType, cfg map[string]interface{}) (*types.ClusterStatus, error) {
 	ret := _m.Called(state, p, cfg)
 
 	var r0 *types.ClusterStatus
-	if rf, ok := ret.Get(0).(func(*statefile.File, types.ProviderType, map[string]interface{}) *types.ClusterStatus); ok {
+	if rf, ok := ret.Get(0).(func(*types.InternalState, types.ProviderType, map[string]interface{}) *types.ClusterStatus); ok {
 		r0 = rf(state, p, cfg)
 	} else {
 		if ret.Get(0) != nil {
@@ -66,7 +158,7 @@ func (_m *Operator) Status(state *statefile.File, p types.ProviderType, cfg map[
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(*statefile.File, types.ProviderType, map[string]interface{}) error); ok {
+	if rf, ok := ret.Get(1).(func(*types.InternalState, types.ProviderType, map[string]interface{}) error); ok {
 		r1 = rf(state, p, cfg)
 	} else {
 		r1 = ret.Error(1)
@@ -13,10 +13,36 @@ type Operator interface {
 	Create(p types.ProviderType, cfg map[string]interface{}) (*types.ClusterInfo, error)
 	// Status checks the cluster status based on the given state.
 	// If the state is empty or nil, Status will attempt to load the state from the file system.
-	Status(state *statefile.File, p types.ProviderType, cfg map[string]interface{}) (*types.ClusterStatus, error)
+	Status(state *types.InternalState, p types.ProviderType, cfg map[string]interface{}) (*types.ClusterStatus, error)
 	// Delete removes a cluster. For this operation a valid state is necessary.
 	// If the state is empty or nil, Delete will attempt to load the state from the file system.
-	Delete(state *statefile.File, p types.ProviderType, cfg map[string]interface{}) error
+	Delete(state *types.InternalState, p types.ProviderType, cfg map[string]interface{}) error
+	// Import brings an existing cluster with the given provider ID under management and returns its information and the settings which differ from the configuration.
+	// If the ID is empty, it is derived from the configuration.
+	Import(p types.ProviderType, cfg map[string]interface{}, id string) (*types.ClusterInfo, []types.Difference, error)
+	// Eject writes everything needed to manage the cluster without Hydroform into the given directory.
+	// If the state is empty or nil, Eject will attempt to load the state from the file system.
+	Eject(state *types.InternalState, p types.ProviderType, cfg map[string]interface{}, dir string) error
+	// Rollback restores the version of the cluster state with the given serial and applies the configuration it was created with, then returns the cluster information.
+	// The state history is only kept if the operator's data directory is persistent.
+	Rollback(p types.ProviderType, cfg map[string]interface{}, serial uint64) (*types.ClusterInfo, error)
+	// State returns the terraform state contained in the given internal state, decrypting it with the encryption key of the operator if it is encrypted.
+	// Returns nil if the internal state contains no terraform state.
+	State(state *types.InternalState) (*statefile.File, error)
+}
+
+// Inventory allows browsing the clusters an operator keeps state for.
+type Inventory interface {
+	// List returns a description of every cluster found in the operator's data directory.
+	List() ([]*types.ClusterDescription, error)
+	// Describe returns the description of a single cluster or an error if the operator has no data about it.
+	Describe(p types.ProviderType, project, cluster string) (*types.ClusterDescription, error)
+	// Record stores the cluster and provider specification next to the cluster state, so that the cluster can be managed later on without knowing its specification.
+	Record(cluster *types.Cluster, provider *types.Provider) error
+	// History returns the versions of the state of a cluster that the operator keeps, oldest first.
+	History(p types.ProviderType, project, cluster string) ([]*types.StateVersion, error)
+	// StateVersion returns the version of the state of a cluster with the given serial.
+	StateVersion(p types.ProviderType, project, cluster string, serial uint64) (*types.InternalState, error)
 }
 
 // Type points out the type of the operator.
@@ -26,3 +52,12 @@ const (
 	// TerraformOperator indicates the type of the operator is Terraform.
 	TerraformOperator Type = "terraform"
 )
+
+// Sensitive marks a configuration value as secret.
+// Operators must never write sensitive values to disk or include them in errors.
+type Sensitive string
+
+// String hides the actual value so that it does not end up in logs by accident.
+func (s Sensitive) String() string {
+	return "[REDACTED]"
+}
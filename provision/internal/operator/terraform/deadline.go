@@ -0,0 +1,107 @@
+package terraform
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/kyma-incubator/hydroform/provision/types"
+)
+
+// deadline bounds a whole operation by the operation timeout and keeps track of the phase the operation is in, to report it when the timeout is exceeded.
+type deadline struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	timeout time.Duration
+	stopped chan struct{}
+	once    sync.Once
+}
+
+// newDeadline starts a deadline of the given timeout. A zero timeout never expires.
+func newDeadline(timeout time.Duration) *deadline {
+	d := &deadline{
+		timeout: timeout,
+		stopped: make(chan struct{}),
+	}
+	if timeout > 0 {
+		d.ctx, d.cancel = context.WithTimeout(context.Background(), timeout)
+	} else {
+		d.ctx, d.cancel = context.WithCancel(context.Background())
+	}
+	return d
+}
+
+// stop releases the deadline once the operation is done.
+func (d *deadline) stop() {
+	d.once.Do(func() {
+		close(d.stopped)
+		d.cancel()
+	})
+}
+
+// context returns the context to bind requests of the operation to. A nil deadline never expires.
+func (d *deadline) context() context.Context {
+	if d == nil {
+		return context.Background()
+	}
+	return d.ctx
+}
+
+// exceeded tells if the operation ran out of time.
+func (d *deadline) exceeded() bool {
+	return d != nil && d.ctx.Err() == context.DeadlineExceeded
+}
+
+// shutdownCh returns a channel for terraform that forwards the given signals and asks terraform to stop once the deadline is exceeded.
+// On the first message terraform stops gracefully, finishing the running calls and writing the state.
+func (d *deadline) shutdownCh(signals <-chan struct{}) <-chan struct{} {
+	ch := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-signals:
+				select {
+				case ch <- struct{}{}:
+				case <-d.stopped:
+					return
+				}
+			case <-d.ctx.Done():
+				if d.exceeded() {
+					select {
+					case ch <- struct{}{}:
+					case <-d.stopped:
+					}
+				}
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// run runs a phase of the operation that stops on its own when the deadline is exceeded, such as terraform apply.
+// If the deadline was exceeded before the phase or made it fail, a TimeoutError is returned.
+func (d *deadline) run(phase string, f func() error) error {
+	if d.exceeded() {
+		return &types.TimeoutError{Phase: phase, Timeout: d.timeout}
+	}
+	err := f()
+	if err != nil && d.exceeded() {
+		return &types.TimeoutError{Phase: phase, Timeout: d.timeout, Err: err}
+	}
+	return err
+}
+
+// runToCompletion runs a phase of the operation that cannot be stopped, such as terraform init, which does not watch the shutdown channel.
+// It waits for the phase to return instead of abandoning it, so that nothing is restored or cleaned up while the phase still runs.
+// If the deadline was exceeded before or during the phase, a TimeoutError is returned.
+func (d *deadline) runToCompletion(phase string, f func() error) error {
+	if d.exceeded() {
+		return &types.TimeoutError{Phase: phase, Timeout: d.timeout}
+	}
+	err := f()
+	if d.exceeded() {
+		return &types.TimeoutError{Phase: phase, Timeout: d.timeout, Err: err}
+	}
+	return err
+}
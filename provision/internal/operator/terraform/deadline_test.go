@@ -0,0 +1,75 @@
+package terraform
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadlineRun(t *testing.T) {
+	d := newDeadline(50 * time.Millisecond)
+	defer d.stop()
+	ops := Options{deadline: d}
+	ops.ShutdownCh = d.shutdownCh(nil)
+
+	// simulate terraform apply, which stops when asked to
+	err := d.run("apply", func() error {
+		select {
+		case <-ops.ShutdownCh:
+			return errors.New("apply stopped")
+		case <-time.After(5 * time.Second):
+			return nil
+		}
+	})
+	require.Error(t, err, "Exceeding the deadline should stop the phase")
+
+	te := types.AsTimeout(errors.Wrap(err, "unable to provision cluster"))
+	require.NotNil(t, te, "The timeout should be recognizable through wrapped errors")
+	require.Equal(t, "apply", te.Phase)
+	require.Equal(t, 50*time.Millisecond, te.Timeout)
+	require.EqualError(t, err, "operation timed out after 50ms during apply: apply stopped")
+
+	called := false
+	err = d.run("refresh", func() error {
+		called = true
+		return nil
+	})
+	require.False(t, called, "No phase should start after the deadline was exceeded")
+	require.EqualError(t, err, "operation timed out after 50ms during refresh")
+}
+
+func TestDeadlineRunToCompletion(t *testing.T) {
+	d := newDeadline(50 * time.Millisecond)
+	defer d.stop()
+
+	finished := false
+	err := d.runToCompletion("init", func() error {
+		time.Sleep(100 * time.Millisecond)
+		finished = true
+		return nil
+	})
+	require.True(t, finished, "A phase that cannot be stopped should not be abandoned")
+	require.EqualError(t, err, "operation timed out after 50ms during init", "Exceeding the deadline should be reported once the phase returns")
+
+	called := false
+	err = d.runToCompletion("init", func() error {
+		called = true
+		return nil
+	})
+	require.False(t, called, "No phase should start after the deadline was exceeded")
+	require.Error(t, err)
+}
+
+func TestDeadlineUnbound(t *testing.T) {
+	for _, d := range []*deadline{nil, newDeadline(0)} {
+		err := d.run("apply", func() error { return errors.New("apply failed") })
+		require.EqualError(t, err, "apply failed", "Errors should be returned as they are without a timeout")
+		require.Nil(t, types.AsTimeout(err))
+
+		require.NoError(t, d.runToCompletion("init", func() error { return nil }))
+		require.NotNil(t, d.context())
+	}
+}
@@ -0,0 +1,129 @@
+package terraform
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/states/statefile"
+	"github.com/kyma-incubator/hydroform/provision/internal/encryption"
+	"github.com/kyma-incubator/hydroform/provision/internal/operator"
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/pkg/errors"
+)
+
+// ejectReadmeFile is the file describing the ejected project and its variables.
+const ejectReadmeFile = "README.md"
+
+// Eject writes a standalone terraform project for the cluster into the given directory, which must be empty or not exist.
+// The project contains the terraform files, a vars file without the sensitive values, the decrypted state and a README listing the variables.
+func (t *Terraform) Eject(state *types.InternalState, p types.ProviderType, cfg map[string]interface{}, dir string) (err error) {
+	// make sure no secrets leak through error messages
+	defer func() { err = redact(err, cfg) }()
+
+	applyTimeouts(cfg, t.ops.Timeouts)
+
+	key, err := encryption.LoadKey(t.ops.EncryptionKey)
+	if err != nil {
+		return err
+	}
+
+	sf, err := stateFromInternal(state, key)
+	if err != nil {
+		return err
+	}
+	// if no state given, try the file system
+	if sf == nil {
+		sf, err = stateFromFile(t.ops.DataDir(), cfg["project"].(string), cfg["cluster_name"].(string), p, key)
+		if err != nil {
+			return errors.Wrap(err, "no state provided, attempted to load from file")
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return errors.Wrap(err, "could not create the eject directory")
+	}
+	empty, err := isEmptyDir(dir)
+	if err != nil {
+		return err
+	}
+	if !empty {
+		return errors.Errorf("could not eject into %s, the directory is not empty", dir)
+	}
+
+	if tfMod(p) != "" {
+		// bound the module download by the operation timeout
+		ops := t.operationOptions()
+		defer ops.deadline.stop()
+
+		// silence stdErr during terraform execution, plugins send debug and trace entries there
+		defer silenceStderr()()
+
+		// init downloads the module into the empty directory
+		if err := tfInit(ops, p, cfg, dir); err != nil {
+			return err
+		}
+		// the plugins are installed again when the project is initialized on its own
+		if err := os.RemoveAll(filepath.Join(dir, ".terraform")); err != nil {
+			return err
+		}
+	}
+
+	if err := writeClusterFiles(dir, p, cfg); err != nil {
+		return errors.Wrap(err, "could not write the terraform files")
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, tfStateFile), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := statefile.Write(sf, f); err != nil {
+		return errors.Wrap(err, "could not write the state")
+	}
+
+	return ioutil.WriteFile(filepath.Join(dir, ejectReadmeFile), []byte(ejectReadme(p, cfg)), 0600)
+}
+
+// ejectReadme describes how to use the ejected project and lists its variables.
+func ejectReadme(p types.ProviderType, cfg map[string]interface{}) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", cfg["cluster_name"])
+	fmt.Fprintf(&b, "This terraform project manages the %s cluster `%s` of project `%s`. It was ejected from Hydroform and no longer depends on it.\n\n", p, cfg["cluster_name"], cfg["project"])
+
+	b.WriteString("## Usage\n\n")
+	b.WriteString("Set the secret variables listed below, then run `terraform init` and `terraform plan`. The plan must not contain any changes to the cluster.\n\n")
+	if p == types.Gardener {
+		fmt.Fprintf(&b, "The Gardener provider is not in the terraform registry. Install [version %s](%s) into the terraform plugins directory first.\n\n",
+			providerVersion, fmt.Sprintf("https://github.com/kyma-incubator/terraform-provider-gardener/releases/tag/%s", providerVersion))
+	}
+	fmt.Fprintf(&b, "The state is stored in plain text in `%s`. It can contain secrets, move it to a remote backend before committing the project.\n\n", tfStateFile)
+
+	b.WriteString("## Variables\n\n")
+	fmt.Fprintf(&b, "The values are set in `%s`, except for the secret ones.\n\n", tfVarsFile)
+	b.WriteString("| Name | Value |\n|------|-------|\n")
+
+	keys := make([]string, 0, len(cfg))
+	for k := range cfg {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		switch v := cfg[k].(type) {
+		case operator.Sensitive:
+			fmt.Fprintf(&b, "| %s | secret, set the **%s%s** environment variable |\n", k, tfVarEnvPrefix, k)
+		case time.Duration:
+			fmt.Fprintf(&b, "| %s | `%s` |\n", k, v.String())
+		case int, bool, string, []string, map[string]string:
+			fmt.Fprintf(&b, "| %s | `%v` |\n", k, v)
+		default:
+			fmt.Fprintf(&b, "| %s | `%v`, rendered into `%s` |\n", k, v, tfModuleFile)
+		}
+	}
+	return b.String()
+}
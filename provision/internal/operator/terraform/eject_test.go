@@ -0,0 +1,65 @@
+package terraform
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform/states/statefile"
+	"github.com/kyma-incubator/hydroform/provision/internal/operator"
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEject(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hf-eject")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	target := filepath.Join(dir, "cluster")
+
+	tf := &Terraform{ops: Options{}}
+	WithDataDir(filepath.Join(dir, "data"))(&tf.ops)
+
+	cfg := map[string]interface{}{
+		"cluster_name":       "cluster",
+		"project":            "project",
+		"location":           "europe-west3",
+		"node_count":         2,
+		"machine_type":       "n1-standard-4",
+		"disk_size":          30,
+		"kubernetes_version": "1.15.9-gke.8",
+		"credentials":        operator.Sensitive("secret-key"),
+	}
+	state := &types.InternalState{TerraformState: testStateFile()}
+
+	require.NoError(t, tf.Eject(state, types.GCP, cfg, target), "Eject should succeed")
+
+	module, err := ioutil.ReadFile(filepath.Join(target, tfModuleFile))
+	require.NoError(t, err, "The terraform files should be written")
+	require.Contains(t, string(module), `resource "google_container_cluster" "gke_cluster"`)
+
+	vars, err := ioutil.ReadFile(filepath.Join(target, tfVarsFile))
+	require.NoError(t, err, "The vars file should be written")
+	require.Contains(t, string(vars), `machine_type = "n1-standard-4"`)
+	require.NotContains(t, string(vars), "secret-key", "Secrets must not be written to the vars file")
+
+	f, err := os.Open(filepath.Join(target, tfStateFile))
+	require.NoError(t, err, "The state should be written")
+	defer f.Close()
+	sf, err := statefile.Read(f)
+	require.NoError(t, err)
+	require.True(t, sf.State.HasResources())
+
+	readme, err := ioutil.ReadFile(filepath.Join(target, ejectReadmeFile))
+	require.NoError(t, err, "The README should be written")
+	require.Contains(t, string(readme), "| credentials | secret, set the **TF_VAR_credentials** environment variable |")
+	require.Contains(t, string(readme), "| machine_type | `n1-standard-4` |")
+	require.NotContains(t, string(readme), "secret-key", "Secrets must not be written to the README")
+
+	err = tf.Eject(state, types.GCP, cfg, target)
+	require.Error(t, err, "Eject should fail if the directory is not empty")
+
+	err = tf.Eject(nil, types.GCP, cfg, filepath.Join(dir, "other"))
+	require.Error(t, err, "Eject should fail without any state")
+}
@@ -0,0 +1,114 @@
+package terraform
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/terraform/states/statefile"
+	"github.com/kyma-incubator/hydroform/provision/internal/encryption"
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/pkg/errors"
+)
+
+// sealState encrypts the plain state file in the given cluster directory and removes any plain state left behind by terraform.
+func sealState(dir string, key []byte) error {
+	stateFile := filepath.Join(dir, tfStateFile)
+	data, err := ioutil.ReadFile(stateFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	enc, err := encryption.Encrypt(key, data)
+	if err != nil {
+		return errors.Wrap(err, "could not encrypt state")
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, tfStateEncryptedFile), enc, 0600); err != nil {
+		return err
+	}
+
+	if err := os.Remove(stateFile); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(dir, tfStateBackupFile)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// unsealState decrypts the encrypted state file in the given cluster directory so that terraform can use it.
+func unsealState(dir string, key []byte) error {
+	encFile := filepath.Join(dir, tfStateEncryptedFile)
+	data, err := ioutil.ReadFile(encFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	plain, err := encryption.Decrypt(key, data)
+	if err != nil {
+		return errors.Wrap(err, "could not decrypt state")
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, tfStateFile), plain, 0600); err != nil {
+		return err
+	}
+	return os.Remove(encFile)
+}
+
+// stateFromInternal extracts the terraform state from the given internal state, decrypting it if necessary.
+// Returns nil if the internal state contains no terraform state.
+func stateFromInternal(is *types.InternalState, key []byte) (*statefile.File, error) {
+	if is == nil {
+		return nil, nil
+	}
+	if is.TerraformState != nil {
+		return is.TerraformState, nil
+	}
+	if len(is.EncryptedTerraformState) == 0 {
+		return nil, nil
+	}
+	if key == nil {
+		return nil, errors.New("the cluster state is encrypted but no encryption key was provided")
+	}
+
+	plain, err := encryption.Decrypt(key, is.EncryptedTerraformState)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decrypt state")
+	}
+	return statefile.Read(bytes.NewReader(plain))
+}
+
+// internalState wraps the terraform state into an internal state, adding its encrypted form if a key is given.
+func internalState(sf *statefile.File, key []byte) (*types.InternalState, error) {
+	is := &types.InternalState{TerraformState: sf}
+	if key == nil {
+		return is, nil
+	}
+
+	buf := &bytes.Buffer{}
+	if err := statefile.Write(sf, buf); err != nil {
+		return nil, err
+	}
+	enc, err := encryption.Encrypt(key, buf.Bytes())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encrypt state")
+	}
+	is.EncryptedTerraformState = enc
+	return is, nil
+}
+
+// State returns the terraform state contained in the given internal state, decrypting it with the encryption key of the operator if it is encrypted.
+// Returns nil if the internal state contains no terraform state.
+func (t *Terraform) State(state *types.InternalState) (*statefile.File, error) {
+	key, err := encryption.LoadKey(t.ops.EncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	return stateFromInternal(state, key)
+}
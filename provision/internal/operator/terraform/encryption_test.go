@@ -0,0 +1,87 @@
+package terraform
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform/states"
+	"github.com/hashicorp/terraform/states/statefile"
+	"github.com/kyma-incubator/hydroform/provision/internal/encryption"
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealUnsealState(t *testing.T) {
+	key, err := encryption.LoadKey(&types.EncryptionKey{Value: []byte("my-secret")})
+	require.NoError(t, err)
+
+	dir, err := clusterDir(".hf-test", "project", "cluster", types.GCP)
+	defer os.RemoveAll(".hf-test")
+	require.NoError(t, err)
+
+	// nothing to seal or unseal in an empty dir
+	require.NoError(t, sealState(dir, key))
+	require.NoError(t, unsealState(dir, key))
+
+	sf := statefile.New(states.NewState(), "lineage", 1)
+	require.NoError(t, stateToFile(sf, ".hf-test", "project", "cluster", types.GCP))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, tfStateBackupFile), []byte("backup"), 0600))
+
+	require.NoError(t, sealState(dir, key))
+	_, err = os.Stat(filepath.Join(dir, tfStateFile))
+	require.True(t, os.IsNotExist(err), "The plain state should be removed after sealing")
+	_, err = os.Stat(filepath.Join(dir, tfStateBackupFile))
+	require.True(t, os.IsNotExist(err), "The state backup should be removed after sealing")
+
+	// the encrypted state can be read directly
+	loaded, err := stateFromFile(".hf-test", "project", "cluster", types.GCP, key)
+	require.NoError(t, err)
+	require.Equal(t, "lineage", loaded.Lineage)
+
+	_, err = stateFromFile(".hf-test", "project", "cluster", types.GCP, nil)
+	require.Error(t, err, "Reading an encrypted state without key should fail")
+
+	require.NoError(t, unsealState(dir, key))
+	_, err = os.Stat(filepath.Join(dir, tfStateEncryptedFile))
+	require.True(t, os.IsNotExist(err), "The encrypted state should be removed after unsealing")
+
+	loaded, err = stateFromFile(".hf-test", "project", "cluster", types.GCP, nil)
+	require.NoError(t, err)
+	require.Equal(t, "lineage", loaded.Lineage)
+}
+
+func TestInternalState(t *testing.T) {
+	sf := statefile.New(states.NewState(), "lineage", 1)
+
+	is, err := internalState(sf, nil)
+	require.NoError(t, err)
+	require.Empty(t, is.EncryptedTerraformState, "State should not be encrypted without key")
+
+	key, err := encryption.LoadKey(&types.EncryptionKey{Value: []byte("my-secret")})
+	require.NoError(t, err)
+
+	is, err = internalState(sf, key)
+	require.NoError(t, err)
+	require.NotEmpty(t, is.EncryptedTerraformState)
+
+	// the JSON form only contains the encrypted state
+	data, err := json.Marshal(is)
+	require.NoError(t, err)
+	restored := &types.InternalState{}
+	require.NoError(t, json.Unmarshal(data, restored))
+	require.Nil(t, restored.TerraformState)
+
+	loaded, err := stateFromInternal(restored, key)
+	require.NoError(t, err)
+	require.Equal(t, "lineage", loaded.Lineage)
+
+	_, err = stateFromInternal(restored, nil)
+	require.Error(t, err, "Encrypted state without key should fail")
+
+	loaded, err = stateFromInternal(nil, key)
+	require.NoError(t, err)
+	require.Nil(t, loaded)
+}
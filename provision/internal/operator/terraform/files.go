@@ -7,22 +7,31 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"sort"
 	"strings"
 	"text/template"
 	"time"
 
 	"github.com/hashicorp/terraform/command/cliconfig"
 	"github.com/hashicorp/terraform/states/statefile"
+	"github.com/kyma-incubator/hydroform/provision/internal/operator"
 	"github.com/kyma-incubator/hydroform/provision/types"
 	"github.com/pkg/errors"
 )
 
 const (
 	// file names for terraform
-	tfStateFile  = "terraform.tfstate"
-	tfModuleFile = "terraform.tf"
-	tfVarsFile   = "terraform.tfvars"
+	tfStateFile          = "terraform.tfstate"
+	tfStateBackupFile    = "terraform.tfstate.backup"
+	tfStateEncryptedFile = "terraform.tfstate.enc"
+	tfModuleFile         = "terraform.tf"
+	tfLabelsFile         = "hydroform_labels.tf"
+	tfOverrideFile       = "hydroform_override.tf"
+	tfVarsFile           = "terraform.tfvars"
+	// file name for the specification Hydroform records for each cluster
+	recordFile = "hydroform.json"
 	// TODO release modules and do not use master as ref when stable
 	azureMod = "git::https://github.com/kyma-incubator/terraform-modules//azurerm_kubernetes_cluster?ref=v0.0.3"
 
@@ -31,7 +40,7 @@ const (
 	gcpClusterTemplate = `
   variable "node_count"    		{}
   variable "cluster_name"  		{}
-  variable "credentials_file_path" 	{}
+  variable "credentials" 	{}
   variable "project"       		{}
   variable "location"      		{}
   variable "machine_type"  		{}
@@ -40,9 +49,35 @@ const (
   variable "create_timeout" 	{}
   variable "update_timeout" 	{}
   variable "delete_timeout" 	{}
+  variable "labels" 			{
+		default = {}
+  }
+{{ if index . "network" }}
+  variable "network" 			{}
+{{ end }}
+{{ if index . "subnetwork" }}
+  variable "subnetwork" 		{}
+{{ end }}
+{{ if index . "vpc_native" }}
+  variable "cluster_ipv4_cidr_block" 		{
+		default = ""
+  }
+  variable "services_ipv4_cidr_block" 		{
+		default = ""
+  }
+  variable "cluster_secondary_range_name" 	{
+		default = ""
+  }
+  variable "services_secondary_range_name" 	{
+		default = ""
+  }
+{{ end }}
+{{ if index . "private_nodes" }}
+  variable "master_ipv4_cidr_block" 	{}
+{{ end }}
 
   provider "google" {
-    	credentials   = "${file("${var.credentials_file_path}")}"
+    	credentials   = "${var.credentials}"
 		project       = "${var.project}"
   }
 
@@ -52,6 +87,37 @@ const (
     	initial_node_count = "${var.node_count}"
     	min_master_version = "${var.kubernetes_version}"
     	node_version       = "${var.kubernetes_version}"
+    	resource_labels    = "${var.labels}"
+{{ if index . "network" }}
+    	network            = "${var.network}"
+{{ end }}
+{{ if index . "subnetwork" }}
+    	subnetwork         = "${var.subnetwork}"
+{{ end }}
+{{ if index . "vpc_native" }}
+    ip_allocation_policy {
+		cluster_ipv4_cidr_block       = "${var.cluster_ipv4_cidr_block == "" ? null : var.cluster_ipv4_cidr_block}"
+		services_ipv4_cidr_block      = "${var.services_ipv4_cidr_block == "" ? null : var.services_ipv4_cidr_block}"
+		cluster_secondary_range_name  = "${var.cluster_secondary_range_name == "" ? null : var.cluster_secondary_range_name}"
+		services_secondary_range_name = "${var.services_secondary_range_name == "" ? null : var.services_secondary_range_name}"
+    }
+{{ end }}
+{{ if index . "private_nodes" }}
+    private_cluster_config {
+		enable_private_nodes    = true
+		enable_private_endpoint = {{ if index . "private_endpoint" }}true{{ else }}false{{ end }}
+		master_ipv4_cidr_block  = "${var.master_ipv4_cidr_block}"
+    }
+{{ end }}
+{{ if index . "master_authorized_networks" }}
+    master_authorized_networks_config {
+	{{ range (index . "master_authorized_networks") }}
+		cidr_blocks {
+			cidr_block = {{ quote . }}
+		}
+	{{ end }}
+    }
+{{ end }}
     
     node_config {
       	machine_type = "${var.machine_type}"
@@ -78,6 +144,10 @@ const (
   output "cluster_ca_certificate" {
     value = "${google_container_cluster.gke_cluster.master_auth.0.cluster_ca_certificate}"
   }
+
+  output "kubernetes_version" {
+    value = "${google_container_cluster.gke_cluster.master_version}"
+  }
 `
 
 	gardenerClusterTemplate = `
@@ -86,7 +156,7 @@ variable "target_profile"			{}
 variable "target_secret"			{}
 variable "node_count"    			{}
 variable "cluster_name"  			{}
-variable "credentials_file_path" 	{}
+variable "credentials" 			{}
 variable "project"					{}
 variable "namespace"       			{}
 variable "location"      			{}
@@ -114,6 +184,13 @@ variable "gcp_control_plane_zone"		{}
 variable "zoned"      				{}
 variable "service_endpoints"		{}
 {{ end }}
+{{ if eq (index . "target_provider") "openstack" }}
+variable "floating_pool_name"		{}
+variable "load_balancer_provider"	{}
+{{ if has . "router_id" }}
+variable "router_id"				{}
+{{ end }}
+{{ end }}
 variable "machine_type"  			{}
 variable "kubernetes_version"   	{}
 variable "disk_size" 				{}
@@ -127,17 +204,44 @@ variable "worker_maximum"			{}
 variable "worker_minimum"			{}
 variable "machine_image_name"		{}
 variable "machine_image_version"	{}
+variable "labels"					{
+	default = {}
+}
+variable "maintenance_window_begin"	{
+	default = "030000+0000"
+}
+variable "maintenance_window_end"	{
+	default = "040000+0000"
+}
+variable "auto_update_kubernetes_version"	{
+	default = true
+}
+variable "auto_update_machine_image_version"	{
+	default = true
+}
+variable "allow_privileged_containers"	{
+	default = true
+}
+{{ if has . "kubelet_pod_pids_limit" }}
+variable "kubelet_pod_pids_limit"	{}
+{{ end }}
+{{ if has . "kubelet_cpu_cfs_quota" }}
+variable "kubelet_cpu_cfs_quota"	{}
+{{ end }}
+{{ if has . "kubelet_cpu_manager_policy" }}
+variable "kubelet_cpu_manager_policy"	{}
+{{ end }}
 
 
 provider "gardener" {
-	kube_file          = "${file("${var.credentials_file_path}")}"
+	kube_file          = "${var.credentials}"
 }
 
 resource "gardener_shoot" "gardener_cluster" {
 	metadata {
 	  name      = "${var.cluster_name}"
 	  namespace = "${var.namespace}"
-  
+	  labels    = "${var.labels}"
 	}
 
 	timeouts {
@@ -158,14 +262,25 @@ resource "gardener_shoot" "gardener_cluster" {
        }
       maintenance {
         auto_update {
-          kubernetes_version = "true"
-          machine_image_version = "true"
+          kubernetes_version = "${var.auto_update_kubernetes_version}"
+          machine_image_version = "${var.auto_update_machine_image_version}"
         }
 		time_window {
-		  begin = "030000+0000"
-          end = "040000+0000"
+		  begin = "${var.maintenance_window_begin}"
+          end = "${var.maintenance_window_end}"
         }
       }
+	  {{ if has . "hibernation_schedules" }}
+	  hibernation {
+		{{ range (index . "hibernation_schedules") }}
+		schedules {
+		  {{ if index . "start" }}start = {{ quote (index . "start") }}{{ end }}
+		  {{ if index . "end" }}end = {{ quote (index . "end") }}{{ end }}
+		  {{ if has $ "hibernation_location" }}location = {{ quote (index $ "hibernation_location") }}{{ end }}
+		}
+		{{ end }}
+	  }
+	  {{ end }}
       provider {
         type = "${var.target_provider}"
 		{{ if eq (index . "target_provider") "gcp" }}
@@ -175,6 +290,13 @@ resource "gardener_shoot" "gardener_cluster" {
  				}
 			}
 		{{ end }}
+		{{ if eq (index . "target_provider") "openstack" }}
+			control_plane_config {
+				openstack {
+					load_balancer_provider = "${var.load_balancer_provider}"
+				}
+			}
+		{{ end }}
         infrastructure_config {
            {{ if eq (index . "target_provider") "azure" }}
 			  azure {
@@ -203,10 +325,38 @@ resource "gardener_shoot" "gardener_cluster" {
 						}
 						{{range $i, $z:= (index . "zones")}}
 						zones {
-							name = "{{ $z }}"
-							workers = "{{ (index (index $ "workerNets") $i) }}"
-							public = "{{ (index (index $ "publicNets") $i) }}"
-							internal = "{{ (index (index $ "internalNets") $i) }}"
+							name = {{ quote $z }}
+							workers = {{ quote (index (index $ "workerNets") $i) }}
+							public = {{ quote (index (index $ "publicNets") $i) }}
+							internal = {{ quote (index (index $ "internalNets") $i) }}
+						}
+						{{end}}
+					}
+				}
+		   {{ end }}
+		   {{ if eq (index . "target_provider") "openstack" }}
+				openstack {
+					floating_pool_name = "${var.floating_pool_name}"
+					networks {
+						workers = "${var.workercidr}"
+						{{ if has . "router_id" }}
+						router {
+							id = "${var.router_id}"
+						}
+						{{ end }}
+					}
+				}
+		   {{ end }}
+		   {{ if eq (index . "target_provider") "alicloud" }}
+				alicloud {
+					networks {
+						vpc {
+							cidr = "${var.vnetcidr}"
+						}
+						{{range $i, $z:= (index . "zones")}}
+						zones {
+							name = {{ quote $z }}
+							worker = {{ quote (index (index $ "workerNets") $i) }}
 						}
 						{{end}}
 					}
@@ -235,17 +385,54 @@ resource "gardener_shoot" "gardener_cluster" {
       }
   
 	  kubernetes {
-		allow_privileged_containers = true
+		allow_privileged_containers = "${var.allow_privileged_containers}"
 		version = "${var.kubernetes_version}"
+		{{ if has . "kube_api_server_admission_plugins" }}
+		kube_api_server {
+		  {{ range (index . "kube_api_server_admission_plugins") }}
+		  admission_plugins {
+			name = {{ quote . }}
+		  }
+		  {{ end }}
+		}
+		{{ end }}
+		{{ if or (has . "kubelet_pod_pids_limit") (has . "kubelet_cpu_cfs_quota") (has . "kubelet_cpu_manager_policy") }}
+		kubelet {
+		  {{ if has . "kubelet_pod_pids_limit" }}pod_pids_limit = "${var.kubelet_pod_pids_limit}"{{ end }}
+		  {{ if has . "kubelet_cpu_cfs_quota" }}cpu_cfs_quota = "${var.kubelet_cpu_cfs_quota}"{{ end }}
+		  {{ if has . "kubelet_cpu_manager_policy" }}cpu_manager_policy = "${var.kubelet_cpu_manager_policy}"{{ end }}
+		}
+		{{ end }}
 	  }
   }
 }
+`
+
+	// the azure module does not support tags, they are added to the cluster resource with an override file.
+	// The override file also marks the kubeconfig output of the module as sensitive, so that terraform never prints it.
+	// Terraform 0.12 cannot mark variables as sensitive, the secret ones are kept out of the vars file instead.
+	azureLabelsTemplate = `
+variable "labels" {
+	default = {}
+}
+`
+	azureLabelsOverrideTemplate = `
+resource "azurerm_kubernetes_cluster" "azure_cluster" {
+	tags = "${var.labels}"
+}
+
+output "kube_config" {
+	sensitive = true
+}
 `
 
 	kindClusterTemplate = `
 variable "project"				{}
 variable "cluster_name"			{}
 variable "node_image"				{}
+variable "kubernetes_version"		{
+	default = ""
+}
 variable "create_timeout" 			{}
 variable "update_timeout" 			{}
 variable "delete_timeout" 			{}
@@ -265,19 +452,31 @@ resource "kind" "kind-cluster" {
 `
 )
 
+// variablePattern matches the variable declarations of terraform files.
+var variablePattern = regexp.MustCompile(`(?m)^\s*variable\s+"([^"]+)"`)
+
 // initClusterFiles initializes all necessary files for a cluster in the given data directory
 func initClusterFiles(dataDir string, p types.ProviderType, cfg map[string]interface{}) error {
 	dir, err := clusterDir(dataDir, cfg["project"].(string), cfg["cluster_name"].(string), p)
 	if err != nil {
 		return err
 	}
+	return writeClusterFiles(dir, p, cfg)
+}
 
+// writeClusterFiles writes the terraform files of the cluster and its vars file into the given directory.
+// Modules are not downloaded, they must be in the directory already.
+func writeClusterFiles(dir string, p types.ProviderType, cfg map[string]interface{}) error {
 	// create module file for providers that are not using modules
 	// TODO delete this when all providers have downloadable modules
 	var data []byte
 	switch p {
 	case types.GCP:
-		data = []byte(gcpClusterTemplate)
+		t, err := expandGCPClusterTemplate(cfg)
+		if err != nil {
+			return err
+		}
+		data = []byte(t)
 	case types.Gardener:
 		t, err := expandGardenerClusterTemplate(cfg)
 		if err != nil {
@@ -285,7 +484,12 @@ func initClusterFiles(dataDir string, p types.ProviderType, cfg map[string]inter
 		}
 		data = []byte(t)
 	case types.Azure:
-		break
+		if err := ioutil.WriteFile(filepath.Join(dir, tfLabelsFile), []byte(azureLabelsTemplate), 0700); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, tfOverrideFile), []byte(azureLabelsOverrideTemplate), 0700); err != nil {
+			return err
+		}
 	case types.AWS:
 		data = []byte(awsClusterTemplate)
 	case types.Kind:
@@ -300,14 +504,35 @@ func initClusterFiles(dataDir string, p types.ProviderType, cfg map[string]inter
 
 	// create vars file
 	var vars strings.Builder
-	for k, v := range cfg {
-		switch t := v.(type) {
+	// write the vars in a stable order, so that the file can be read and compared
+	keys := make([]string, 0, len(cfg))
+	for k := range cfg {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	// the generated templates only declare the variables they use, the other configurations only control the template
+	var declared map[string]bool
+	if len(data) > 0 {
+		declared = declaredVariables(data)
+	}
+	for _, k := range keys {
+		if declared != nil && !declared[k] {
+			continue
+		}
+		switch t := cfg[k].(type) {
+		case operator.Sensitive:
+			// sensitive values are never written into the vars file of the cluster, each operation passes them in its own private vars file
+			continue
 		case int:
 			if _, err := vars.WriteString(fmt.Sprintf("%s = \"%d\"\n", k, t)); err != nil {
 				return err
 			}
+		case bool:
+			if _, err := vars.WriteString(fmt.Sprintf("%s = %t\n", k, t)); err != nil {
+				return err
+			}
 		case string:
-			if _, err := vars.WriteString(fmt.Sprintf("%s = \"%s\"\n", k, t)); err != nil {
+			if _, err := vars.WriteString(fmt.Sprintf("%s = %s\n", k, quote(t))); err != nil {
 				return err
 			}
 		case time.Duration:
@@ -317,13 +542,25 @@ func initClusterFiles(dataDir string, p types.ProviderType, cfg map[string]inter
 		case []string:
 			var a []string
 			for _, v := range t {
-				x := fmt.Sprintf("\"%s\"", v)
-				a = append(a, x)
+				a = append(a, quote(v))
 			}
 			b := strings.Join(a, ",")
 			if _, err := vars.WriteString(fmt.Sprintf("%s = [%s]\n", k, b)); err != nil {
 				return err
 			}
+		case map[string]string:
+			keys := make([]string, 0, len(t))
+			for mk := range t {
+				keys = append(keys, mk)
+			}
+			sort.Strings(keys)
+			var a []string
+			for _, mk := range keys {
+				a = append(a, fmt.Sprintf("%s = %s", quote(mk), quote(t[mk])))
+			}
+			if _, err := vars.WriteString(fmt.Sprintf("%s = {%s}\n", k, strings.Join(a, ", "))); err != nil {
+				return err
+			}
 		}
 
 	}
@@ -334,18 +571,31 @@ func initClusterFiles(dataDir string, p types.ProviderType, cfg map[string]inter
 	return nil
 }
 
-// stateFromFile loads the terraform state file for the given cluster
-func stateFromFile(dataDir, project, cluster string, p types.ProviderType) (*statefile.File, error) {
+// stateFromFile loads the terraform state file for the given cluster.
+// If there is only an encrypted state file, it is decrypted with the given key.
+func stateFromFile(dataDir, project, cluster string, p types.ProviderType, key []byte) (*statefile.File, error) {
 	dir, err := clusterDir(dataDir, project, cluster, p)
 	if err != nil {
 		return nil, err
 	}
 
 	stateFilePath := filepath.Join(dir, tfStateFile)
+	if _, err := os.Stat(stateFilePath); os.IsNotExist(err) {
+		encrypted, err := ioutil.ReadFile(filepath.Join(dir, tfStateEncryptedFile))
+		if os.IsNotExist(err) {
+			return nil, errors.Errorf("no state file found in %s", dir)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return stateFromInternal(&types.InternalState{EncryptedTerraformState: encrypted}, key)
+	}
+
 	f, err := os.Open(stateFilePath)
 	if err != nil {
 		return nil, err
 	}
+	defer f.Close()
 
 	st, err := statefile.Read(f)
 	if err != nil {
@@ -354,36 +604,57 @@ func stateFromFile(dataDir, project, cluster string, p types.ProviderType) (*sta
 	return st, nil
 }
 
-// stateToFile saves the terraform state into its corresponding file
+// stateToFile saves the terraform state into its corresponding file, replacing the previous one
 func stateToFile(state *statefile.File, dataDir, project, cluster string, p types.ProviderType) error {
 	dir, err := clusterDir(dataDir, project, cluster, p)
 	if err != nil {
 		return err
 	}
 
-	stateFilePath := filepath.Join(dir, tfStateFile)
-	f, err := os.Create(stateFilePath)
+	return writeState(state, filepath.Join(dir, tfStateFile))
+}
+
+// writeState writes the terraform state into the given file.
+// The state is written into a temporary file first and then moved in place, so that a failure never leaves a broken state behind.
+func writeState(state *statefile.File, path string) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path))
 	if err != nil {
 		return err
 	}
-	return statefile.Write(state, f)
+	defer os.Remove(f.Name())
+
+	if err := statefile.Write(state, f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
 }
 
-func clusterInfoFromFile(dataDir, project, cluster string, p types.ProviderType) (*types.ClusterInfo, error) {
-	sf, err := stateFromFile(dataDir, project, cluster, p)
+// clusterInfoFromFile loads the cluster information from the state file of the given cluster.
+// If a key is given, the returned internal state is encrypted with it.
+func clusterInfoFromFile(dataDir, project, cluster string, p types.ProviderType, key []byte) (*types.ClusterInfo, error) {
+	sf, err := stateFromFile(dataDir, project, cluster, p, key)
+	if err != nil {
+		return nil, err
+	}
+
+	is, err := internalState(sf, key)
 	if err != nil {
 		return nil, err
 	}
 
 	var certificateData []byte
-	var endpoint string
+	var endpoint, version string
 
 	if len(sf.State.Modules) > 0 {
 		if val, ok := sf.State.Modules[""].OutputValues["cluster_ca_certificate"]; ok {
 			certificateData, err = base64.StdEncoding.DecodeString(val.Value.AsString())
 			if err != nil {
 				return &types.ClusterInfo{
-					InternalState: &types.InternalState{TerraformState: sf},
+					InternalState: is,
 					Status:        &types.ClusterStatus{Phase: types.Errored},
 				}, errors.Wrap(err, "Unable to decode certificate data")
 			}
@@ -391,12 +662,20 @@ func clusterInfoFromFile(dataDir, project, cluster string, p types.ProviderType)
 		if val, ok := sf.State.Modules[""].OutputValues["endpoint"]; ok {
 			endpoint = val.Value.AsString()
 		}
+		// the running version can differ from the requested one, for example after GKE upgraded the master
+		if val, ok := sf.State.Modules[""].OutputValues["kubernetes_version"]; ok {
+			version = val.Value.AsString()
+		}
+	}
+	if version == "" {
+		version = runningVersion(p, sf)
 	}
 
 	return &types.ClusterInfo{
 		Endpoint:                 endpoint,
 		CertificateAuthorityData: certificateData,
-		InternalState:            &types.InternalState{TerraformState: sf},
+		KubernetesVersion:        version,
+		InternalState:            is,
 		Status:                   &types.ClusterStatus{Phase: types.Provisioned},
 	}, nil
 }
@@ -437,6 +716,15 @@ func clusterDir(dataDir, project, cluster string, p types.ProviderType) (string,
 	return clDir, nil
 }
 
+func expandGCPClusterTemplate(cfg map[string]interface{}) (string, error) {
+	t := template.Must(template.New("gcpCluster").Funcs(template.FuncMap{"quote": quote}).Parse(gcpClusterTemplate))
+	s := &strings.Builder{}
+	if err := t.Execute(s, cfg); err != nil {
+		return "", err
+	}
+	return s.String(), nil
+}
+
 func expandGardenerClusterTemplate(cfg map[string]interface{}) (string, error) {
 	funcs := template.FuncMap{
 		"seq": func(n int) []int {
@@ -447,6 +735,11 @@ func expandGardenerClusterTemplate(cfg map[string]interface{}) (string, error) {
 			}
 			return r
 		},
+		"has": func(m map[string]interface{}, key string) bool {
+			_, ok := m[key]
+			return ok
+		},
+		"quote": quote,
 	}
 
 	if cfg["target_provider"] == string(types.AWS) {
@@ -457,6 +750,14 @@ func expandGardenerClusterTemplate(cfg map[string]interface{}) (string, error) {
 			return "", errors.Wrap(err, "Error generating subnets for AWS zones")
 		}
 	}
+	if cfg["target_provider"] == "alicloud" {
+		// worker subnets for zones
+		var err error
+		cfg["workerNets"], err = generateGardenerAlicloudSubnets(cfg["vnetcidr"].(string), len(cfg["zones"].([]string)))
+		if err != nil {
+			return "", errors.Wrap(err, "Error generating subnets for Alicloud zones")
+		}
+	}
 
 	t := template.Must(template.New("gardenerCluster").Funcs(funcs).Parse(gardenerClusterTemplate))
 	s := &strings.Builder{}
@@ -466,6 +767,31 @@ func expandGardenerClusterTemplate(cfg map[string]interface{}) (string, error) {
 	return s.String(), nil
 }
 
+// quote returns the given value as an HCL string literal, so that it can be interpolated into terraform files.
+// Quotes, backslashes and control characters are escaped, and template sequences are escaped so that terraform does not evaluate them.
+func quote(v interface{}) string {
+	s := fmt.Sprint(v)
+	r := strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\n", `\n`,
+		"\r", `\r`,
+		"\t", `\t`,
+		"${", "$${",
+		"%{", "%%{",
+	)
+	return `"` + r.Replace(s) + `"`
+}
+
+// declaredVariables returns the names of the variables declared in the given terraform file.
+func declaredVariables(tf []byte) map[string]bool {
+	vars := map[string]bool{}
+	for _, m := range variablePattern.FindAllSubmatch(tf, -1) {
+		vars[string(m[1])] = true
+	}
+	return vars
+}
+
 // cleanup removes all terraform generated files for a given cluster
 func cleanup(dataDir, project, cluster string, p types.ProviderType) error {
 	d, err := clusterDir(dataDir, project, cluster, p)
@@ -511,3 +837,29 @@ func generateGardenerAWSSubnets(baseNet string, zoneCount int) (workerNets, publ
 	}
 	return
 }
+
+// generateGardenerAlicloudSubnets splits the VPC into one worker subnet per zone.
+func generateGardenerAlicloudSubnets(baseNet string, zoneCount int) (workerNets []string, err error) {
+	_, cidr, err := net.ParseCIDR(baseNet)
+	if err != nil {
+		return
+	}
+	if zoneCount < 1 {
+		return nil, errors.New("There must be at least 1 zone defined.")
+	}
+	if ones, _ := cidr.Mask.Size(); ones > 16 {
+		return nil, errors.Errorf("The VPC %s is too small, it must be at least a /16", baseNet)
+	}
+
+	// each zone gets its own /19 subnet
+	const subnetSize = 32
+	if zoneCount*subnetSize > 256 {
+		return nil, errors.Errorf("The VPC %s has no room for %d zones", baseNet, zoneCount)
+	}
+	for i := 0; i < zoneCount; i++ {
+		cidr.IP[2] = byte(i * subnetSize)
+		cidr.Mask = net.CIDRMask(19, 8*net.IPv4len)
+		workerNets = append(workerNets, cidr.String())
+	}
+	return
+}
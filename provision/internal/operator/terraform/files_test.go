@@ -0,0 +1,147 @@
+package terraform
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform/configs"
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandGCPClusterTemplate(t *testing.T) {
+	// without networking configuration the defaults are kept
+	res, err := expandGCPClusterTemplate(map[string]interface{}{})
+	require.NoError(t, err)
+	require.NotContains(t, res, "network ")
+	require.NotContains(t, res, "ip_allocation_policy")
+	require.NotContains(t, res, "private_cluster_config")
+	require.NotContains(t, res, "master_authorized_networks_config")
+
+	res, err = expandGCPClusterTemplate(map[string]interface{}{
+		"network":                    "my-vpc",
+		"subnetwork":                 "my-subnet",
+		"vpc_native":                 true,
+		"private_nodes":              true,
+		"private_endpoint":           true,
+		"master_ipv4_cidr_block":     "172.16.0.0/28",
+		"master_authorized_networks": []string{"10.0.0.0/8", "192.168.0.0/16"},
+	})
+	require.NoError(t, err)
+	require.Contains(t, res, `network            = "${var.network}"`)
+	require.Contains(t, res, `subnetwork         = "${var.subnetwork}"`)
+	require.Contains(t, res, "ip_allocation_policy {")
+	require.Contains(t, res, "enable_private_endpoint = true")
+	require.Contains(t, res, `cidr_block = "10.0.0.0/8"`)
+	require.Contains(t, res, `cidr_block = "192.168.0.0/16"`)
+}
+
+func TestExpandGardenerClusterTemplate(t *testing.T) {
+	// without shoot settings no optional blocks are rendered
+	res, err := expandGardenerClusterTemplate(map[string]interface{}{"target_provider": "gcp"})
+	require.NoError(t, err)
+	require.NotContains(t, res, "hibernation {")
+	require.NotContains(t, res, "kube_api_server {")
+	require.NotContains(t, res, "kubelet {")
+
+	res, err = expandGardenerClusterTemplate(map[string]interface{}{
+		"target_provider":                   "gcp",
+		"hibernation_schedules":             []map[string]string{{"start": "00 20 * * 1,2,3,4,5", "end": "00 08 * * 1,2,3,4,5"}, {"start": "00 20 * * 6"}},
+		"hibernation_location":              "Europe/Berlin",
+		"kube_api_server_admission_plugins": []string{"PodNodeSelector"},
+		"kubelet_pod_pids_limit":            1024,
+		"kubelet_cpu_manager_policy":        "static",
+	})
+	require.NoError(t, err)
+	require.Contains(t, res, `start = "00 20 * * 1,2,3,4,5"`)
+	require.Contains(t, res, `end = "00 08 * * 1,2,3,4,5"`)
+	require.Contains(t, res, `start = "00 20 * * 6"`)
+	require.Contains(t, res, `location = "Europe/Berlin"`)
+	require.Contains(t, res, `name = "PodNodeSelector"`)
+	require.Contains(t, res, "kubelet {")
+	require.NotContains(t, res, "cpu_cfs_quota")
+}
+
+func TestExpandGardenerInfrastructure(t *testing.T) {
+	res, err := expandGardenerClusterTemplate(map[string]interface{}{
+		"target_provider": "openstack",
+		"router_id":       "router-1",
+	})
+	require.NoError(t, err)
+	require.Contains(t, res, `floating_pool_name = "${var.floating_pool_name}"`)
+	require.Contains(t, res, `load_balancer_provider = "${var.load_balancer_provider}"`)
+	require.Contains(t, res, `id = "${var.router_id}"`)
+
+	res, err = expandGardenerClusterTemplate(map[string]interface{}{
+		"target_provider": "alicloud",
+		"vnetcidr":        "10.250.0.0/16",
+		"zones":           []string{"eu-central-1a", "eu-central-1b"},
+	})
+	require.NoError(t, err)
+	require.Contains(t, res, "alicloud {")
+	require.Contains(t, res, `worker = "10.250.0.0/19"`)
+	require.Contains(t, res, `worker = "10.250.32.0/19"`)
+}
+
+func TestGenerateGardenerAlicloudSubnets(t *testing.T) {
+	nets, err := generateGardenerAlicloudSubnets("10.250.0.0/16", 3)
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.250.0.0/19", "10.250.32.0/19", "10.250.64.0/19"}, nets)
+
+	_, err = generateGardenerAlicloudSubnets("10.250.0.0/24", 1)
+	require.Error(t, err, "A VPC smaller than /16 should be rejected")
+	_, err = generateGardenerAlicloudSubnets("10.250.0.0/16", 9)
+	require.Error(t, err, "More zones than subnets should be rejected")
+	_, err = generateGardenerAlicloudSubnets("10.250.0.0/16", 0)
+	require.Error(t, err, "At least one zone is needed")
+}
+
+func TestAzureOverrideMarksKubeconfigSensitive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "azure")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, writeClusterFiles(dir, types.Azure, map[string]interface{}{"cluster_name": "my-cluster"}))
+
+	f, diags := configs.NewParser(nil).LoadConfigFileOverride(filepath.Join(dir, tfOverrideFile))
+	require.False(t, diags.HasErrors(), diags.Error())
+	require.Len(t, f.Outputs, 1)
+	require.Equal(t, "kube_config", f.Outputs[0].Name)
+	require.True(t, f.Outputs[0].Sensitive, "The kubeconfig output must be sensitive")
+}
+
+func TestWriteClusterFilesDeclaredVariables(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gcp")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, writeClusterFiles(dir, types.GCP, map[string]interface{}{
+		"cluster_name":               "my-cluster",
+		"network":                    "my-vpc",
+		"vpc_native":                 true,
+		"master_authorized_networks": []string{"10.0.0.0/8"},
+	}))
+
+	vars, err := ioutil.ReadFile(filepath.Join(dir, tfVarsFile))
+	require.NoError(t, err)
+	require.Equal(t, "cluster_name = \"my-cluster\"\nnetwork = \"my-vpc\"\n", string(vars), "Only variables declared by the template should be written")
+}
+
+func TestQuote(t *testing.T) {
+	require.Equal(t, `"10.0.0.0/8"`, quote("10.0.0.0/8"))
+	require.Equal(t, `"a\"b\\c\nd"`, quote("a\"b\\c\nd"))
+	require.Equal(t, `"$${file(\"/etc/passwd\")} %%{if}"`, quote(`${file("/etc/passwd")} %{if}`), "Template sequences should not be evaluated")
+}
+
+func TestExpandGardenerClusterTemplateQuotesSettings(t *testing.T) {
+	res, err := expandGardenerClusterTemplate(map[string]interface{}{
+		"target_provider":                   "gcp",
+		"kube_api_server_admission_plugins": []string{`Plugin"}`},
+		"hibernation_schedules":             []map[string]string{{"start": "${x}"}},
+	})
+	require.NoError(t, err)
+	require.Contains(t, res, `name = "Plugin\"}"`)
+	require.Contains(t, res, `start = "$${x}"`)
+}
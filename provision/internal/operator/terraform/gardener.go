@@ -1,6 +1,7 @@
 package terraform
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -9,7 +10,10 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/kyma-incubator/hydroform/provision/types"
 )
 
 // TODO remove this file when the gardener provider is on the official terraform registry
@@ -20,9 +24,21 @@ const (
 	providerVersion = "v0.0.9"
 )
 
+// providerMu makes sure that concurrent operations do not download the provider at the same time.
+var providerMu sync.Mutex
+
 // initGardenerProvider will check if the gardener provider is available and download it if not.
+// The download is bound by the deadline of the operation.
+func initGardenerProvider(ops Options) error {
+	return ops.phase(types.Gardener, "provider download", func() error {
+		return installGardenerProvider(ops.deadline.context())
+	})
+}
+
+func installGardenerProvider(ctx context.Context) error {
+	providerMu.Lock()
+	defer providerMu.Unlock()
 
-func initGardenerProvider() error {
 	pluginDirs, err := globalPluginDirs()
 	if err != nil {
 		return err
@@ -41,7 +57,7 @@ func initGardenerProvider() error {
 	}
 
 	// Download the plugin for the OS and arch
-	r, err := downloadBinary(fmt.Sprintf(providerURL, providerVersion, runtime.GOOS, runtime.GOARCH))
+	r, err := downloadBinary(ctx, fmt.Sprintf(providerURL, providerVersion, runtime.GOOS, runtime.GOARCH))
 	if err != nil {
 		return err
 	}
@@ -54,16 +70,23 @@ func initGardenerProvider() error {
 			return err
 		}
 	}
-	providerFile, err := os.OpenFile(providerPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0700)
+	// download into a temporary file first, so that an interrupted download does not leave a broken provider behind
+	downloadPath := providerPath + ".download"
+	providerFile, err := os.OpenFile(downloadPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0700)
 	if err != nil {
 		return err
 	}
 	if _, err := io.Copy(providerFile, r); err != nil {
+		providerFile.Close()
+		os.Remove(downloadPath)
 		return err
 	}
 	if err := providerFile.Close(); err != nil {
 		return err
 	}
+	if err := os.Rename(downloadPath, providerPath); err != nil {
+		return err
+	}
 	if runtime.GOOS == "windows" {
 		// Create exe for windows if it doesn't exist
 		err = generateWindowsBinary(providerPath)
@@ -102,7 +125,7 @@ func generateWindowsBinary(providerPath string) error {
 	return nil
 }
 
-func downloadBinary(url string) (io.ReadCloser, error) {
+func downloadBinary(ctx context.Context, url string) (io.ReadCloser, error) {
 	c := &http.Client{
 		Timeout: 5 * time.Minute,
 	}
@@ -110,6 +133,7 @@ func downloadBinary(url string) (io.ReadCloser, error) {
 	if err != nil {
 		return nil, err
 	}
+	req = req.WithContext(ctx)
 	req.Header.Set("Accept", "application/octet-stream")
 
 	resp, err := c.Do(req)
@@ -0,0 +1,357 @@
+package terraform
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/states/statefile"
+	"github.com/kyma-incubator/hydroform/provision/internal/encryption"
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	// historyDir is the directory inside of a cluster directory that keeps the versions of its state.
+	historyDir = "history"
+	// historyTimeFormat is the format of the time in the names of state versions.
+	historyTimeFormat = "20060102T150405Z"
+
+	// operations that produce state versions
+	historyCreate   = "create"
+	historyDelete   = "delete"
+	historyImport   = "import"
+	historyRollback = "rollback"
+	// historyExisting marks a state that was not written by a versioned operation, for example a state passed in by the caller.
+	historyExisting = "existing"
+)
+
+// History returns the versions of the state of a cluster in the data directory, oldest first.
+func (t *Terraform) History(p types.ProviderType, project, cluster string) ([]*types.StateVersion, error) {
+	dir, err := t.existingClusterDir(p, project, cluster)
+	if err != nil {
+		return nil, err
+	}
+	return stateHistory(dir)
+}
+
+// StateVersion returns the version of the state of a cluster with the given serial.
+// If an encryption key is set, the returned internal state is encrypted with it.
+func (t *Terraform) StateVersion(p types.ProviderType, project, cluster string, serial uint64) (*types.InternalState, error) {
+	key, err := encryption.LoadKey(t.ops.EncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := t.existingClusterDir(p, project, cluster)
+	if err != nil {
+		return nil, err
+	}
+	vDir, err := versionDir(dir, serial)
+	if err != nil {
+		return nil, err
+	}
+
+	sf, _, err := readState(vDir, key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read state version %d", serial)
+	}
+	if sf == nil {
+		return nil, errors.Errorf("state version %d has no state", serial)
+	}
+	return internalState(sf, key)
+}
+
+// Rollback restores the version of the cluster state with the given serial together with the configuration files it was created with, and applies them.
+// The current state is kept in the history, so a rollback can be rolled back as well.
+func (t *Terraform) Rollback(p types.ProviderType, cfg map[string]interface{}, serial uint64) (ci *types.ClusterInfo, err error) {
+	// make sure no secrets leak through error messages
+	defer func() { err = redact(err, cfg) }()
+
+	if !t.ops.Persistent {
+		return nil, errors.New("rolling back requires a persistent data directory, since the state history is kept there")
+	}
+
+	applyTimeouts(cfg, t.ops.Timeouts)
+
+	// bound the whole operation by the operation timeout
+	ops := t.operationOptions()
+	defer ops.deadline.stop()
+
+	key, err := encryption.LoadKey(t.ops.EncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterDir, err := t.existingClusterDir(p, cfg["project"].(string), cfg["cluster_name"].(string))
+	if err != nil {
+		return nil, err
+	}
+	vDir, err := versionDir(clusterDir, serial)
+	if err != nil {
+		return nil, err
+	}
+
+	// pass sensitive values to terraform in a vars file of this operation instead of the vars file of the cluster
+	varsFile, removeVars, err := writeSensitiveVars(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer removeVars()
+	ops.sensitiveVars = varsFile
+
+	// silence stdErr during terraform execution, plugins send debug and trace entries there
+	defer silenceStderr()()
+
+	if key != nil {
+		// terraform needs the plain state while running, encrypt it again when done
+		if err := unsealState(clusterDir, key); err != nil {
+			return nil, err
+		}
+		defer func() {
+			if sealErr := sealState(clusterDir, key); sealErr != nil && err == nil {
+				err = sealErr
+			}
+		}()
+	}
+
+	// keep the current state before replacing it
+	if err := t.version(clusterDir, key, historyExisting); err != nil {
+		return nil, err
+	}
+	defer t.versionOnReturn(clusterDir, key, historyRollback, &err)
+
+	if err := ops.phase(p, "restore", func() error { return restoreVersion(clusterDir, vDir, key) }); err != nil {
+		return nil, errors.Wrapf(err, "could not restore state version %d", serial)
+	}
+
+	// INIT
+	if p == types.Gardener {
+		if err := initGardenerProvider(ops); err != nil {
+			return nil, errors.Wrap(err, "could not initialize the gardener provider")
+		}
+	}
+	if err := tfInit(ops, p, cfg, clusterDir); err != nil {
+		return nil, err
+	}
+
+	// APPLY
+	if err := tfApply(ops, p, cfg, clusterDir); err != nil {
+		return nil, err
+	}
+	return clusterInfoFromFile(t.ops.DataDir(), cfg["project"].(string), cfg["cluster_name"].(string), p, key)
+}
+
+// existingClusterDir returns the directory of a cluster in the data directory, or an error if Hydroform has no data about the cluster.
+func (t *Terraform) existingClusterDir(p types.ProviderType, project, cluster string) (string, error) {
+	dir := filepath.Join(t.ops.DataDir(), "clusters", string(p), project, cluster)
+	if _, err := os.Stat(dir); err != nil {
+		if os.IsNotExist(err) {
+			return "", types.Classify(types.ErrorClassNotFound, errors.Errorf("cluster %s of project %s on %s not found in %s", cluster, project, p, t.ops.DataDir()))
+		}
+		return "", err
+	}
+	return dir, nil
+}
+
+// version adds the state in the cluster directory to its history as produced by the given operation.
+// Nothing is versioned if the data directory is not persistent, since it is removed after the operation.
+func (t *Terraform) version(dir string, key []byte, operation string) error {
+	if !t.ops.Persistent {
+		return nil
+	}
+	return versionState(dir, key, operation)
+}
+
+// versionOnReturn adds the state in the cluster directory to its history when an operation returns, whether it failed or not, since terraform also keeps the state of partial changes.
+// It is meant to be deferred with the address of the error of the operation, which is set if the state cannot be versioned and the operation succeeded otherwise.
+func (t *Terraform) versionOnReturn(dir string, key []byte, operation string, err *error) {
+	if versionErr := t.version(dir, key, operation); versionErr != nil && *err == nil {
+		*err = versionErr
+	}
+}
+
+// versionState adds the state in the given cluster directory to its history as produced by the given operation, together with the configuration files it was applied with.
+// Nothing is added if there is no state or if its serial is in the history already.
+func versionState(dir string, key []byte, operation string) error {
+	sf, _, err := readState(dir, key)
+	if err != nil {
+		return errors.Wrap(err, "could not read the state to version it")
+	}
+	if sf == nil {
+		return nil
+	}
+
+	versions, err := stateHistory(dir)
+	if err != nil {
+		return err
+	}
+	for _, v := range versions {
+		if v.Serial == sf.Serial {
+			return nil
+		}
+	}
+
+	// write the version into a hidden directory first, so that no partial version ends up in the history
+	name := versionName(&types.StateVersion{Serial: sf.Serial, Time: time.Now(), Operation: operation})
+	tmpDir := filepath.Join(dir, historyDir, "."+name)
+	if err := os.MkdirAll(tmpDir, 0700); err != nil {
+		return errors.Wrap(err, "could not create the state version directory")
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := copyConfigFiles(dir, tmpDir); err != nil {
+		return errors.Wrap(err, "could not copy the configuration into the state history")
+	}
+
+	buf := &bytes.Buffer{}
+	if err := statefile.Write(sf, buf); err != nil {
+		return err
+	}
+	stateFile, data := tfStateFile, buf.Bytes()
+	if key != nil {
+		if data, err = encryption.Encrypt(key, data); err != nil {
+			return errors.Wrap(err, "could not encrypt state")
+		}
+		stateFile = tfStateEncryptedFile
+	}
+	if err := ioutil.WriteFile(filepath.Join(tmpDir, stateFile), data, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpDir, filepath.Join(dir, historyDir, name))
+}
+
+// restoreVersion replaces the configuration files and the plain state in the given cluster directory with the ones of the version in the given directory.
+// The restored state gets a serial higher than any in the history, since terraform only accepts states which are newer than the ones it replaces.
+func restoreVersion(dir, vDir string, key []byte) error {
+	sf, _, err := readState(vDir, key)
+	if err != nil {
+		return err
+	}
+	if sf == nil {
+		return errors.New("the state version has no state")
+	}
+
+	versions, err := stateHistory(dir)
+	if err != nil {
+		return err
+	}
+	for _, v := range versions {
+		if v.Serial >= sf.Serial {
+			sf.Serial = v.Serial + 1
+		}
+	}
+
+	// files of the current configuration which are not part of the version must not be applied
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if isConfigFile(e) {
+			if err := os.Remove(filepath.Join(dir, e.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	if err := copyConfigFiles(vDir, dir); err != nil {
+		return err
+	}
+
+	if err := writeState(sf, filepath.Join(dir, tfStateFile)); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(dir, tfStateBackupFile)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// stateHistory lists the versions of the state in the given cluster directory, oldest first.
+func stateHistory(dir string) ([]*types.StateVersion, error) {
+	names, err := subDirs(filepath.Join(dir, historyDir))
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*types.StateVersion, 0, len(names))
+	for _, n := range names {
+		// skip versions which are still being written and anything that is not a version
+		if v := parseVersionName(n); v != nil {
+			res = append(res, v)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Serial < res[j].Serial })
+	return res, nil
+}
+
+// versionDir returns the directory of the state version with the given serial in the given cluster directory.
+func versionDir(dir string, serial uint64) (string, error) {
+	versions, err := stateHistory(dir)
+	if err != nil {
+		return "", err
+	}
+	for _, v := range versions {
+		if v.Serial == serial {
+			return filepath.Join(dir, historyDir, versionName(v)), nil
+		}
+	}
+	return "", types.Classify(types.ErrorClassNotFound, errors.Errorf("state version %d not found in the history of %s", serial, dir))
+}
+
+// versionName returns the directory name of a state version, which holds all of its metadata.
+func versionName(v *types.StateVersion) string {
+	return strings.Join([]string{strconv.FormatUint(v.Serial, 10), v.Time.UTC().Format(historyTimeFormat), v.Operation}, "-")
+}
+
+// parseVersionName returns the state version described by the given directory name, or nil if it is not the name of a version.
+func parseVersionName(name string) *types.StateVersion {
+	parts := strings.SplitN(name, "-", 3)
+	if len(parts) != 3 {
+		return nil
+	}
+	serial, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return nil
+	}
+	t, err := time.Parse(historyTimeFormat, parts[1])
+	if err != nil {
+		return nil
+	}
+	return &types.StateVersion{Serial: serial, Time: t, Operation: parts[2]}
+}
+
+// copyConfigFiles copies the terraform files, the vars file and the cluster record from one directory to another.
+// Modules are copied as well, as long as they are on the top level of the directory.
+func copyConfigFiles(from, to string) error {
+	entries, err := ioutil.ReadDir(from)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if !isConfigFile(e) {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(from, e.Name()))
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(to, e.Name()), data, 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isConfigFile(f os.FileInfo) bool {
+	if !f.Mode().IsRegular() {
+		return false
+	}
+	return strings.HasSuffix(f.Name(), ".tf") || strings.HasSuffix(f.Name(), ".tfvars") || f.Name() == recordFile
+}
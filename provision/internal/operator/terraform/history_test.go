@@ -0,0 +1,136 @@
+package terraform
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/states"
+	"github.com/hashicorp/terraform/states/statefile"
+	"github.com/kyma-incubator/hydroform/provision/internal/encryption"
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionState(t *testing.T) {
+	defer os.RemoveAll(".hf-test")
+	dir, err := clusterDir(".hf-test", "project", "cluster", types.GCP)
+	require.NoError(t, err)
+
+	// nothing to version without a state
+	require.NoError(t, versionState(dir, nil, historyCreate))
+	versions, err := stateHistory(dir)
+	require.NoError(t, err)
+	require.Empty(t, versions)
+
+	sf := testStateFile()
+	sf.Serial = 1
+	require.NoError(t, stateToFile(sf, ".hf-test", "project", "cluster", types.GCP))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, tfVarsFile), []byte(`node_count = 1`), 0600))
+	require.NoError(t, versionState(dir, nil, historyCreate))
+	require.NoError(t, versionState(dir, nil, historyExisting), "Versioning an unchanged state should do nothing")
+
+	sf.Serial = 2
+	require.NoError(t, stateToFile(sf, ".hf-test", "project", "cluster", types.GCP))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, tfVarsFile), []byte(`node_count = 2`), 0600))
+	require.NoError(t, versionState(dir, nil, historyDelete))
+
+	versions, err = stateHistory(dir)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	require.Equal(t, uint64(1), versions[0].Serial)
+	require.Equal(t, historyCreate, versions[0].Operation)
+	require.Equal(t, uint64(2), versions[1].Serial)
+	require.Equal(t, historyDelete, versions[1].Operation)
+	require.WithinDuration(t, time.Now(), versions[1].Time, time.Minute)
+
+	// the configuration is kept with each version
+	vDir, err := versionDir(dir, 1)
+	require.NoError(t, err)
+	vars, err := ioutil.ReadFile(filepath.Join(vDir, tfVarsFile))
+	require.NoError(t, err)
+	require.Equal(t, `node_count = 1`, string(vars))
+
+	_, err = versionDir(dir, 3)
+	require.Error(t, err, "Unknown versions should not be found")
+}
+
+func TestRestoreVersion(t *testing.T) {
+	defer os.RemoveAll(".hf-test")
+	dir, err := clusterDir(".hf-test", "project", "cluster", types.GCP)
+	require.NoError(t, err)
+
+	sf := testStateFile()
+	sf.Serial = 1
+	require.NoError(t, stateToFile(sf, ".hf-test", "project", "cluster", types.GCP))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, tfVarsFile), []byte(`node_count = 1`), 0600))
+	require.NoError(t, versionState(dir, nil, historyCreate))
+
+	// a newer state with an additional configuration file
+	require.NoError(t, stateToFile(statefile.New(states.NewState(), sf.Lineage, 5), ".hf-test", "project", "cluster", types.GCP))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, tfVarsFile), []byte(`node_count = 2`), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, tfOverrideFile), []byte(`# override`), 0600))
+	require.NoError(t, versionState(dir, nil, historyDelete))
+
+	vDir, err := versionDir(dir, 1)
+	require.NoError(t, err)
+	require.NoError(t, restoreVersion(dir, vDir, nil))
+
+	vars, err := ioutil.ReadFile(filepath.Join(dir, tfVarsFile))
+	require.NoError(t, err)
+	require.Equal(t, `node_count = 1`, string(vars), "The configuration of the version should be restored")
+	_, err = os.Stat(filepath.Join(dir, tfOverrideFile))
+	require.True(t, os.IsNotExist(err), "Configuration files which are not part of the version should be removed")
+
+	restored, err := stateFromFile(".hf-test", "project", "cluster", types.GCP, nil)
+	require.NoError(t, err)
+	require.True(t, restored.State.HasResources(), "The state of the version should be restored")
+	require.Equal(t, uint64(6), restored.Serial, "The restored state should be newer than any state in the history")
+}
+
+func TestHistory(t *testing.T) {
+	defer os.RemoveAll(".hf-test")
+	tf := &Terraform{ops: Options{}}
+	WithDataDir(".hf-test")(&tf.ops)
+	WithEncryptionKey(&types.EncryptionKey{Value: []byte("my-secret")})(&tf.ops)
+	key, err := encryption.LoadKey(tf.ops.EncryptionKey)
+	require.NoError(t, err)
+
+	_, err = tf.History(types.GCP, "project", "cluster")
+	require.Error(t, err, "The history of an unknown cluster should fail")
+
+	dir, err := clusterDir(".hf-test", "project", "cluster", types.GCP)
+	require.NoError(t, err)
+	sf := testStateFile()
+	sf.Serial = 4
+	require.NoError(t, stateToFile(sf, ".hf-test", "project", "cluster", types.GCP))
+	require.NoError(t, sealState(dir, key))
+	require.NoError(t, versionState(dir, key, historyImport))
+
+	versions, err := tf.History(types.GCP, "project", "cluster")
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+	require.Equal(t, uint64(4), versions[0].Serial)
+	require.Equal(t, historyImport, versions[0].Operation)
+
+	vDir, err := versionDir(dir, 4)
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(vDir, tfStateFile))
+	require.True(t, os.IsNotExist(err), "Versions of encrypted states should not be stored in plain text")
+
+	is, err := tf.StateVersion(types.GCP, "project", "cluster", 4)
+	require.NoError(t, err)
+	require.NotEmpty(t, is.EncryptedTerraformState)
+	require.Equal(t, uint64(4), is.TerraformState.Serial)
+
+	_, err = tf.StateVersion(types.GCP, "project", "cluster", 5)
+	require.Error(t, err, "Fetching an unknown version should fail")
+}
+
+func TestRollbackNotPersistent(t *testing.T) {
+	tf := &Terraform{ops: Options{}}
+	_, err := tf.Rollback(types.GCP, map[string]interface{}{"project": "project", "cluster_name": "cluster"}, 1)
+	require.Error(t, err, "Rolling back should require a persistent data directory")
+}
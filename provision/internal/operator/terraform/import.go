@@ -0,0 +1,167 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/addrs"
+	"github.com/hashicorp/terraform/states/statefile"
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/pkg/errors"
+)
+
+// setting maps a configuration value to the attribute of the cluster resource holding it.
+type setting struct {
+	// name is the setting as the user specifies it.
+	name string
+	// config is the key of the value in the operator configuration.
+	config string
+	// attribute is the path of the value in the attributes of the cluster resource, list elements are addressed by their index.
+	attribute string
+}
+
+// importedSettings are the settings compared between the configuration and an imported cluster.
+var importedSettings = map[types.ProviderType][]setting{
+	types.GCP: {
+		{"Cluster.Location", "location", "location"},
+		{"Cluster.KubernetesVersion", "kubernetes_version", "master_version"},
+		{"Cluster.NodeCount", "node_count", "initial_node_count"},
+		{"Cluster.MachineType", "machine_type", "node_config.0.machine_type"},
+		{"Cluster.DiskSizeGB", "disk_size", "node_config.0.disk_size_gb"},
+	},
+	types.Azure: {
+		{"Cluster.Location", "location", "location"},
+		{"Cluster.KubernetesVersion", "kubernetes_version", "kubernetes_version"},
+		{"Cluster.NodeCount", "agent_count", "agent_pool_profile.0.count"},
+		{"Cluster.MachineType", "agent_vm_size", "agent_pool_profile.0.vm_size"},
+		{"Cluster.DiskSizeGB", "agent_disk_size", "agent_pool_profile.0.os_disk_size_gb"},
+	},
+	types.Gardener: {
+		{"Cluster.Location", "location", "spec.0.region"},
+		{"Cluster.KubernetesVersion", "kubernetes_version", "spec.0.kubernetes.0.version"},
+		{"Cluster.MachineType", "machine_type", "spec.0.provider.0.worker.0.machine.0.type"},
+		{"Provider.CustomConfigurations['target_profile']", "target_profile", "spec.0.cloud_profile_name"},
+		{"Provider.CustomConfigurations['target_secret']", "target_secret", "spec.0.secret_binding_name"},
+		{"Provider.CustomConfigurations['worker_minimum']", "worker_minimum", "spec.0.provider.0.worker.0.minimum"},
+		{"Provider.CustomConfigurations['worker_maximum']", "worker_maximum", "spec.0.provider.0.worker.0.maximum"},
+	},
+	types.Kind: {
+		{"Provider.CustomConfigurations['node_image']", "node_image", "node_image"},
+	},
+}
+
+// defaultedSettings are the configuration values the provider chooses if they are left zero, such as the disk size on GKE and AKS.
+// A zero value is not compared, since the cluster runs with the default of the provider.
+var defaultedSettings = map[string]bool{
+	"disk_size":       true,
+	"agent_disk_size": true,
+}
+
+// differences compares the cluster resource in the state with the configuration and returns the settings with different values.
+// Settings missing in either of them are not compared.
+func differences(p types.ProviderType, cfg map[string]interface{}, sf *statefile.File) ([]types.Difference, error) {
+	attrs, err := clusterAttributes(p, sf)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []types.Difference
+	for _, s := range importedSettings[p] {
+		requested, ok := cfg[s.config]
+		if !ok || (defaultedSettings[s.config] && isZero(requested)) {
+			continue
+		}
+		actual, ok := attribute(attrs, s.attribute)
+		if !ok {
+			continue
+		}
+		if r, a := fmt.Sprintf("%v", requested), fmt.Sprintf("%v", actual); r != a {
+			diffs = append(diffs, types.Difference{Setting: s.name, Requested: r, Actual: a})
+		}
+	}
+	return diffs, nil
+}
+
+// runningVersion returns the Kubernetes version of the cluster resource in the state, or an empty string if the state does not contain it.
+func runningVersion(p types.ProviderType, sf *statefile.File) string {
+	attrs, err := clusterAttributes(p, sf)
+	if err != nil {
+		return ""
+	}
+
+	if p == types.Kind {
+		// kind runs the version its node image is tagged with, for example kindest/node:v1.16.4
+		image, ok := attribute(attrs, "node_image")
+		if !ok {
+			return ""
+		}
+		ref := strings.SplitN(fmt.Sprintf("%v", image), "@", 2)[0]
+		if i := strings.LastIndex(ref, ":v"); i >= 0 {
+			return ref[i+2:]
+		}
+		return ""
+	}
+
+	for _, s := range importedSettings[p] {
+		if s.config != "kubernetes_version" {
+			continue
+		}
+		if v, ok := attribute(attrs, s.attribute); ok {
+			return fmt.Sprintf("%v", v)
+		}
+	}
+	return ""
+}
+
+// isZero tells if a configuration value is the zero value of its type, as loadConfigurations sets settings the user left empty.
+func isZero(v interface{}) bool {
+	return v == nil || reflect.DeepEqual(v, reflect.Zero(reflect.TypeOf(v)).Interface())
+}
+
+// clusterAttributes returns the attributes of the cluster resource in the state.
+func clusterAttributes(p types.ProviderType, sf *statefile.File) (map[string]interface{}, error) {
+	if sf == nil || sf.State == nil || sf.State.RootModule() == nil {
+		return nil, errors.New("the state does not contain any resources")
+	}
+	r, ok := sf.State.RootModule().Resources[clusterResource(p)]
+	if !ok {
+		return nil, errors.Errorf("the state does not contain the cluster resource %s", clusterResource(p))
+	}
+	i, ok := r.Instances[addrs.NoKey]
+	if !ok || i.Current == nil {
+		return nil, errors.Errorf("the state does not contain an instance of the cluster resource %s", clusterResource(p))
+	}
+
+	attrs := map[string]interface{}{}
+	if err := json.Unmarshal(i.Current.AttrsJSON, &attrs); err != nil {
+		return nil, errors.Wrap(err, "could not read the attributes of the cluster resource")
+	}
+	return attrs, nil
+}
+
+// attribute returns the value at the given path, nested blocks are lists in the state so their elements are addressed by index.
+func attribute(attrs map[string]interface{}, path string) (interface{}, bool) {
+	var v interface{} = attrs
+	for _, step := range strings.Split(path, ".") {
+		switch current := v.(type) {
+		case map[string]interface{}:
+			next, ok := current[step]
+			if !ok {
+				return nil, false
+			}
+			v = next
+		case []interface{}:
+			i, err := strconv.Atoi(step)
+			if err != nil || i >= len(current) {
+				return nil, false
+			}
+			v = current[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, v != nil
+}
@@ -0,0 +1,102 @@
+package terraform
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/addrs"
+	"github.com/hashicorp/terraform/states"
+	"github.com/hashicorp/terraform/states/statefile"
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDifferences(t *testing.T) {
+	s := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(
+			addrs.Resource{
+				Mode: addrs.ManagedResourceMode,
+				Type: "google_container_cluster",
+				Name: "gke_cluster",
+			}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+			&states.ResourceInstanceObjectSrc{
+				Status: states.ObjectReady,
+				AttrsJSON: []byte(`{
+					"name": "cluster",
+					"location": "europe-west3",
+					"master_version": "1.15.9-gke.8",
+					"initial_node_count": 3,
+					"node_config": [{"machine_type": "n1-standard-8", "disk_size_gb": 30}]
+				}`),
+			},
+			addrs.ProviderConfig{Type: "google"}.Absolute(addrs.RootModuleInstance),
+		)
+	})
+	sf := statefile.New(s, "lineage", 1)
+
+	cfg := map[string]interface{}{
+		"cluster_name":       "cluster",
+		"location":           "europe-west3",
+		"kubernetes_version": "1.15.9-gke.8",
+		"node_count":         3,
+		"machine_type":       "n1-standard-4",
+		"disk_size":          30,
+	}
+	diffs, err := differences(types.GCP, cfg, sf)
+	require.NoError(t, err)
+	require.Equal(t, []types.Difference{{Setting: "Cluster.MachineType", Requested: "n1-standard-4", Actual: "n1-standard-8"}}, diffs)
+
+	cfg["machine_type"] = "n1-standard-8"
+	delete(cfg, "disk_size")
+	diffs, err = differences(types.GCP, cfg, sf)
+	require.NoError(t, err)
+	require.Empty(t, diffs, "Matching and missing settings should not be reported")
+
+	// the user did not set the disk size, so the cluster got the default of GKE
+	cfg["disk_size"] = 0
+	diffs, err = differences(types.GCP, cfg, sf)
+	require.NoError(t, err)
+	require.Empty(t, diffs, "Settings left to the provider default should not be reported")
+
+	_, err = differences(types.Gardener, cfg, sf)
+	require.Error(t, err, "A state without the cluster resource should fail")
+}
+
+func TestRunningVersion(t *testing.T) {
+	state := func(resource, provider, attrs string) *statefile.File {
+		r := strings.SplitN(resource, ".", 2)
+		return statefile.New(states.BuildState(func(s *states.SyncState) {
+			s.SetResourceInstanceCurrent(
+				addrs.Resource{Mode: addrs.ManagedResourceMode, Type: r[0], Name: r[1]}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+				&states.ResourceInstanceObjectSrc{Status: states.ObjectReady, AttrsJSON: []byte(attrs)},
+				addrs.ProviderConfig{Type: provider}.Absolute(addrs.RootModuleInstance),
+			)
+		}), "lineage", 1)
+	}
+
+	require.Equal(t, "1.15.9-gke.8", runningVersion(types.GCP, state(clusterResource(types.GCP), "google", `{"master_version": "1.15.9-gke.8"}`)))
+	require.Equal(t, "1.15.7", runningVersion(types.Azure, state(clusterResource(types.Azure), "azurerm", `{"kubernetes_version": "1.15.7"}`)))
+	require.Equal(t, "1.16.4", runningVersion(types.Gardener, state(clusterResource(types.Gardener), "gardener", `{"spec": [{"kubernetes": [{"version": "1.16.4"}]}]}`)))
+	require.Equal(t, "1.16.4", runningVersion(types.Kind, state(clusterResource(types.Kind), "kind", `{"node_image": "kindest/node:v1.16.4@sha256:b91a2c2"}`)))
+	require.Empty(t, runningVersion(types.Kind, state(clusterResource(types.Kind), "kind", `{"node_image": "my-registry/node"}`)), "Untagged images do not tell the version")
+	require.Empty(t, runningVersion(types.Azure, statefile.New(states.NewState(), "lineage", 1)), "A state without the cluster should not report a version")
+}
+
+func TestAttribute(t *testing.T) {
+	attrs := map[string]interface{}{
+		"spec": []interface{}{
+			map[string]interface{}{"region": "europe-west3", "kubernetes": []interface{}{}},
+		},
+	}
+
+	v, ok := attribute(attrs, "spec.0.region")
+	require.True(t, ok)
+	require.Equal(t, "europe-west3", v)
+
+	_, ok = attribute(attrs, "spec.0.kubernetes.0.version")
+	require.False(t, ok, "Missing list elements should not be found")
+	_, ok = attribute(attrs, "spec.1.region")
+	require.False(t, ok, "Indexes out of range should not be found")
+	_, ok = attribute(attrs, "spec.0.region.name")
+	require.False(t, ok, "Paths into plain values should not be found")
+}
@@ -0,0 +1,217 @@
+package terraform
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/terraform/states/statefile"
+	"github.com/kyma-incubator/hydroform/provision/internal/encryption"
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/pkg/errors"
+)
+
+// List returns a description of every cluster found in the data directory.
+// Clusters are stored following the layout clusters/<provider>/<project>/<cluster>.
+func (t *Terraform) List() ([]*types.ClusterDescription, error) {
+	key, err := encryption.LoadKey(t.ops.EncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	root := filepath.Join(t.ops.DataDir(), "clusters")
+	providers, err := subDirs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*types.ClusterDescription, 0)
+	for _, p := range providers {
+		projects, err := subDirs(filepath.Join(root, p))
+		if err != nil {
+			return nil, err
+		}
+		for _, project := range projects {
+			clusters, err := subDirs(filepath.Join(root, p, project))
+			if err != nil {
+				return nil, err
+			}
+			for _, cluster := range clusters {
+				res = append(res, describe(filepath.Join(root, p, project, cluster), types.ProviderType(p), project, cluster, key))
+			}
+		}
+	}
+	return res, nil
+}
+
+// Describe returns the description of a single cluster in the data directory.
+func (t *Terraform) Describe(p types.ProviderType, project, cluster string) (*types.ClusterDescription, error) {
+	key, err := encryption.LoadKey(t.ops.EncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := t.existingClusterDir(p, project, cluster)
+	if err != nil {
+		return nil, err
+	}
+	return describe(dir, p, project, cluster, key), nil
+}
+
+// Record stores the cluster and provider specification in the directory of the cluster.
+// The cluster information is not stored, since it is already part of the state.
+func (t *Terraform) Record(cluster *types.Cluster, provider *types.Provider) error {
+	dir, err := clusterDir(t.ops.DataDir(), provider.ProjectName, cluster.Name, provider.Type)
+	if err != nil {
+		return err
+	}
+
+	spec := *cluster
+	spec.ClusterInfo = nil
+	data, err := json.MarshalIndent(&clusterRecord{Cluster: &spec, Provider: provider}, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "could not serialize the cluster record")
+	}
+	return ioutil.WriteFile(filepath.Join(dir, recordFile), data, 0600)
+}
+
+// clusterRecord is the specification of a cluster as stored in the data directory.
+type clusterRecord struct {
+	Cluster  *types.Cluster  `json:"cluster"`
+	Provider *types.Provider `json:"provider"`
+}
+
+// describe summarizes the cluster in the given directory.
+// Clusters without a readable state are still described, with the phase set to unknown, since they may be left overs of failed operations.
+// If the state or the record exists but cannot be read, the reason is reported in the error of the description.
+func describe(dir string, p types.ProviderType, project, cluster string, key []byte) *types.ClusterDescription {
+	d := &types.ClusterDescription{
+		Name:     cluster,
+		Provider: p,
+		Project:  project,
+		Dir:      dir,
+		Phase:    types.Unknown,
+	}
+
+	if info, err := os.Stat(dir); err == nil {
+		d.LastModified = info.ModTime()
+	}
+
+	if data, err := ioutil.ReadFile(filepath.Join(dir, recordFile)); err == nil {
+		r := &clusterRecord{}
+		if err := json.Unmarshal(data, r); err != nil {
+			d.Error = errors.Wrap(err, "could not read the cluster record").Error()
+		} else {
+			if r.Provider != nil {
+				normalizeConfig(r.Provider.CustomConfigurations)
+			}
+			d.ClusterSpec = r.Cluster
+			d.ProviderSpec = r.Provider
+		}
+	} else if !os.IsNotExist(err) {
+		d.Error = errors.Wrap(err, "could not read the cluster record").Error()
+	}
+
+	sf, modified, err := readState(dir, key)
+	if err != nil {
+		// the state error is more relevant than the record one, since it decides the phase
+		d.Error = errors.Wrap(err, "could not read the cluster state").Error()
+		return d
+	}
+	if sf == nil {
+		return d
+	}
+	d.LastModified = modified
+
+	for _, m := range sf.State.Modules {
+		for _, r := range m.Resources {
+			d.ResourceCount += len(r.Instances)
+		}
+	}
+	if sf.State.HasResources() {
+		d.Phase = types.Provisioned
+	}
+	return d
+}
+
+// normalizeConfig restores the types of custom configurations that get lost in JSON, since the providers rely on them.
+func normalizeConfig(cfg map[string]interface{}) {
+	for k, v := range cfg {
+		switch t := v.(type) {
+		case float64:
+			if t == float64(int(t)) {
+				cfg[k] = int(t)
+			}
+		case []interface{}:
+			strs := make([]string, 0, len(t))
+			maps := make([]map[string]string, 0, len(t))
+			for _, e := range t {
+				switch et := e.(type) {
+				case string:
+					strs = append(strs, et)
+				case map[string]interface{}:
+					m := make(map[string]string, len(et))
+					for mk, mv := range et {
+						if s, ok := mv.(string); ok {
+							m[mk] = s
+						}
+					}
+					maps = append(maps, m)
+				}
+			}
+			if len(strs) == len(t) {
+				cfg[k] = strs
+			} else if len(maps) == len(t) {
+				cfg[k] = maps
+			}
+		}
+	}
+}
+
+// readState reads the plain or encrypted state in the given cluster directory without creating or modifying anything.
+// It returns nil if there is no state in the directory.
+func readState(dir string, key []byte) (*statefile.File, time.Time, error) {
+	if info, err := os.Stat(filepath.Join(dir, tfStateFile)); err == nil {
+		f, err := os.Open(filepath.Join(dir, tfStateFile))
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		defer f.Close()
+
+		sf, err := statefile.Read(f)
+		return sf, info.ModTime(), err
+	}
+
+	if info, err := os.Stat(filepath.Join(dir, tfStateEncryptedFile)); err == nil {
+		data, err := ioutil.ReadFile(filepath.Join(dir, tfStateEncryptedFile))
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		sf, err := stateFromInternal(&types.InternalState{EncryptedTerraformState: data}, key)
+		return sf, info.ModTime(), err
+	}
+
+	return nil, time.Time{}, nil
+}
+
+// subDirs returns the names of all directories inside of the given one.
+// A non existing directory has no sub directories.
+func subDirs(dir string) ([]string, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var res []string
+	for _, e := range entries {
+		if e.IsDir() {
+			res = append(res, e.Name())
+		}
+	}
+	return res, nil
+}
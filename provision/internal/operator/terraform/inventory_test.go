@@ -0,0 +1,127 @@
+package terraform
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/addrs"
+	"github.com/hashicorp/terraform/states"
+	"github.com/hashicorp/terraform/states/statefile"
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestList(t *testing.T) {
+	defer os.RemoveAll(".hf-test")
+	tf := &Terraform{ops: Options{}}
+	WithDataDir(".hf-test")(&tf.ops)
+
+	// empty data dir
+	res, err := tf.List()
+	require.NoError(t, err)
+	require.Empty(t, res)
+
+	// a cluster with state and one left over without state
+	_, err = clusterDir(".hf-test", "project", "cluster", types.GCP)
+	require.NoError(t, err)
+	require.NoError(t, stateToFile(testStateFile(), ".hf-test", "project", "cluster", types.GCP))
+	_, err = clusterDir(".hf-test", "group", "crashed", types.Azure)
+	require.NoError(t, err)
+
+	res, err = tf.List()
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+
+	require.Equal(t, "crashed", res[0].Name)
+	require.Equal(t, types.Azure, res[0].Provider)
+	require.Equal(t, "group", res[0].Project)
+	require.Equal(t, types.Unknown, res[0].Phase, "Clusters without state should have unknown phase")
+	require.Equal(t, 0, res[0].ResourceCount)
+
+	require.Equal(t, "cluster", res[1].Name)
+	require.Equal(t, types.GCP, res[1].Provider)
+	require.Equal(t, "project", res[1].Project)
+	require.Equal(t, types.Provisioned, res[1].Phase)
+	require.Equal(t, 1, res[1].ResourceCount)
+	require.False(t, res[1].LastModified.IsZero())
+}
+
+func TestDescribe(t *testing.T) {
+	defer os.RemoveAll(".hf-test")
+	tf := &Terraform{ops: Options{}}
+	WithDataDir(".hf-test")(&tf.ops)
+
+	_, err := tf.Describe(types.GCP, "project", "cluster")
+	require.Error(t, err, "Describing an unknown cluster should fail")
+
+	_, err = clusterDir(".hf-test", "project", "cluster", types.GCP)
+	require.NoError(t, err)
+	require.NoError(t, stateToFile(testStateFile(), ".hf-test", "project", "cluster", types.GCP))
+
+	d, err := tf.Describe(types.GCP, "project", "cluster")
+	require.NoError(t, err)
+	require.Equal(t, "cluster", d.Name)
+	require.Equal(t, types.Provisioned, d.Phase)
+	require.Equal(t, 1, d.ResourceCount)
+	require.Empty(t, d.Error)
+
+	// an encrypted state with the wrong key
+	dir, err := clusterDir(".hf-test", "project", "cluster", types.GCP)
+	require.NoError(t, err)
+	require.NoError(t, sealState(dir, []byte("0123456789abcdef0123456789abcdef")))
+	d = describe(dir, types.GCP, "project", "cluster", []byte("fedcba9876543210fedcba9876543210"))
+	require.Equal(t, types.Unknown, d.Phase)
+	require.Contains(t, d.Error, "could not read the cluster state", "A state which cannot be read should be reported")
+}
+
+// testStateFile returns a state file containing a single cluster resource.
+func testStateFile() *statefile.File {
+	s := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(
+			addrs.Resource{
+				Mode: addrs.ManagedResourceMode,
+				Type: "google_container_cluster",
+				Name: "gke_cluster",
+			}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+			&states.ResourceInstanceObjectSrc{
+				Status:    states.ObjectReady,
+				AttrsJSON: []byte(`{"name":"cluster"}`),
+			},
+			addrs.ProviderConfig{Type: "google"}.Absolute(addrs.RootModuleInstance),
+		)
+	})
+	return statefile.New(s, "lineage", 1)
+}
+
+func TestRecord(t *testing.T) {
+	defer os.RemoveAll(".hf-test")
+	tf := &Terraform{ops: Options{}}
+	WithDataDir(".hf-test")(&tf.ops)
+
+	cluster := &types.Cluster{
+		Name:        "cluster",
+		NodeCount:   2,
+		TTL:         time.Hour,
+		ExpiresAt:   time.Unix(1577836800, 0).UTC(),
+		ClusterInfo: &types.ClusterInfo{Endpoint: "https://cluster-url.fake"},
+	}
+	provider := &types.Provider{
+		Type:                types.Gardener,
+		ProjectName:         "project",
+		CredentialsFilePath: "/path/to/credentials",
+		CustomConfigurations: map[string]interface{}{
+			"worker_minimum": 2,
+			"zones":          []string{"europe-west3-b"},
+		},
+	}
+	require.NoError(t, tf.Record(cluster, provider))
+
+	d, err := tf.Describe(types.Gardener, "project", "cluster")
+	require.NoError(t, err)
+	require.NotNil(t, d.ClusterSpec)
+	require.Nil(t, d.ClusterSpec.ClusterInfo, "Cluster info should not be recorded")
+	require.Equal(t, time.Hour, d.ClusterSpec.TTL)
+	require.True(t, cluster.ExpiresAt.Equal(d.ClusterSpec.ExpiresAt))
+	require.Equal(t, provider, d.ProviderSpec, "Custom configurations should keep their types")
+}
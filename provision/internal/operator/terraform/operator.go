@@ -3,12 +3,10 @@ package terraform
 import (
 	"io/ioutil"
 	"log"
-	"os"
 
+	"github.com/kyma-incubator/hydroform/provision/internal/encryption"
 	"github.com/kyma-incubator/hydroform/provision/types"
 	"github.com/pkg/errors"
-
-	"github.com/hashicorp/terraform/states/statefile"
 )
 
 // Terraform is an Operator.
@@ -27,57 +25,117 @@ func New(ops ...Option) *Terraform {
 }
 
 // Create creates a new cluster for a specific provider based on configuration details. It returns a ClusterInfo object with provider-related information, or an error if cluster provisioning failed.
-func (t *Terraform) Create(p types.ProviderType, cfg map[string]interface{}) (*types.ClusterInfo, error) {
+func (t *Terraform) Create(p types.ProviderType, cfg map[string]interface{}) (ci *types.ClusterInfo, err error) {
+	// make sure no secrets leak through error messages
+	defer func() { err = redact(err, cfg) }()
+
 	applyTimeouts(cfg, t.ops.Timeouts)
 
+	// bound the whole operation by the operation timeout
+	ops := t.operationOptions()
+	defer ops.deadline.stop()
+
+	key, err := encryption.LoadKey(t.ops.EncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// pass sensitive values to terraform in a vars file of this operation instead of the vars file of the cluster
+	varsFile, removeVars, err := writeSensitiveVars(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer removeVars()
+	ops.sensitiveVars = varsFile
+
 	// silence stdErr during terraform execution, plugins send debug and trace entries there
-	stderr := os.Stderr
-	os.Stderr, _ = os.Open(os.DevNull)
-	defer func() { os.Stderr = stderr }()
+	defer silenceStderr()()
 
 	// init cluster files
 	if !t.ops.Persistent {
-		// remove all files if not persistent after running
-		defer cleanup(t.ops.DataDir(), cfg["project"].(string), cfg["cluster_name"].(string), p)
+		// remove all files if not persistent after running.
+		// If the operation timed out, terraform may have created resources, so their state is kept to delete them later.
+		defer func() {
+			if types.AsTimeout(err) == nil {
+				cleanup(t.ops.DataDir(), cfg["project"].(string), cfg["cluster_name"].(string), p)
+			}
+		}()
 	}
 
 	clusterDir, err := clusterDir(t.ops.DataDir(), cfg["project"].(string), cfg["cluster_name"].(string), p)
 	if err != nil {
 		return nil, err
 	}
+
+	// keep the current state and configuration in the history before they are replaced
+	if err := t.version(clusterDir, key, historyExisting); err != nil {
+		return nil, err
+	}
+	defer t.versionOnReturn(clusterDir, key, historyCreate, &err)
+
 	// INIT
 	if p == types.Gardener {
-		if err := initGardenerProvider(); err != nil {
+		if err := initGardenerProvider(ops); err != nil {
 			return nil, errors.Wrap(err, "could not initialize the gardener provider")
 		}
 	}
-	if err := tfInit(t.ops, p, cfg, clusterDir); err != nil {
+	if err := tfInit(ops, p, cfg, clusterDir); err != nil {
 		return nil, err
 	}
 
-	if err := initClusterFiles(t.ops.DataDir(), p, cfg); err != nil {
+	if err := ops.phase(p, "cluster files", func() error { return initClusterFiles(t.ops.DataDir(), p, cfg) }); err != nil {
 		return nil, errors.Wrap(err, "Could not initialize cluster data")
 	}
 
+	if key != nil {
+		// terraform needs the plain state while running, encrypt it again when done
+		if err := unsealState(clusterDir, key); err != nil {
+			return nil, err
+		}
+		defer func() {
+			if sealErr := sealState(clusterDir, key); sealErr != nil && err == nil {
+				err = sealErr
+			}
+		}()
+	}
+
 	// APPLY
-	if err := tfApply(t.ops, p, cfg, clusterDir); err != nil {
+	if err := tfApply(ops, p, cfg, clusterDir); err != nil {
 		return nil, err
 	}
-	return clusterInfoFromFile(t.ops.DataDir(), cfg["project"].(string), cfg["cluster_name"].(string), p)
+	return clusterInfoFromFile(t.ops.DataDir(), cfg["project"].(string), cfg["cluster_name"].(string), p, key)
+}
+
+// operationOptions returns the options for a single operation, bound by the operation timeout.
+func (t *Terraform) operationOptions() Options {
+	ops := t.ops
+	ops.deadline = newDeadline(t.ops.Timeouts.Operation)
+	// terraform stops gracefully on signals and when the deadline is exceeded
+	ops.ShutdownCh = ops.deadline.shutdownCh(t.ops.ShutdownCh)
+	return ops
 }
 
 // Status checks the current state of the cluster from the file
-func (t *Terraform) Status(sf *statefile.File, p types.ProviderType, cfg map[string]interface{}) (*types.ClusterStatus, error) {
+func (t *Terraform) Status(state *types.InternalState, p types.ProviderType, cfg map[string]interface{}) (*types.ClusterStatus, error) {
 	applyTimeouts(cfg, t.ops.Timeouts)
 
 	cs := &types.ClusterStatus{
 		Phase: types.Unknown,
 	}
-	var err error
+
+	key, err := encryption.LoadKey(t.ops.EncryptionKey)
+	if err != nil {
+		return cs, err
+	}
+
+	sf, err := stateFromInternal(state, key)
+	if err != nil {
+		return cs, err
+	}
 
 	// if no state given, try the file system
 	if sf == nil {
-		sf, err = stateFromFile(t.ops.DataDir(), cfg["project"].(string), cfg["cluster_name"].(string), p)
+		sf, err = stateFromFile(t.ops.DataDir(), cfg["project"].(string), cfg["cluster_name"].(string), p, key)
 		if err != nil {
 			return cs, errors.Wrap(err, "no state provided, attempted to load from file")
 		}
@@ -91,18 +149,46 @@ func (t *Terraform) Status(sf *statefile.File, p types.ProviderType, cfg map[str
 }
 
 // Delete removes an existing cluster or returns an error if removing the cluster is not possible.
-func (t *Terraform) Delete(sf *statefile.File, p types.ProviderType, cfg map[string]interface{}) error {
+func (t *Terraform) Delete(state *types.InternalState, p types.ProviderType, cfg map[string]interface{}) (err error) {
+	// make sure no secrets leak through error messages
+	defer func() { err = redact(err, cfg) }()
+
 	applyTimeouts(cfg, t.ops.Timeouts)
 
+	// bound the whole operation by the operation timeout
+	ops := t.operationOptions()
+	defer ops.deadline.stop()
+
+	key, err := encryption.LoadKey(t.ops.EncryptionKey)
+	if err != nil {
+		return err
+	}
+
+	sf, err := stateFromInternal(state, key)
+	if err != nil {
+		return err
+	}
+
+	// pass sensitive values to terraform in a vars file of this operation instead of the vars file of the cluster
+	varsFile, removeVars, err := writeSensitiveVars(cfg)
+	if err != nil {
+		return err
+	}
+	defer removeVars()
+	ops.sensitiveVars = varsFile
+
 	// silence stdErr during terraform execution, plugins send debug and trace entries there
-	stderr := os.Stderr
-	os.Stderr, _ = os.Open(os.DevNull)
-	defer func() { os.Stderr = stderr }()
+	defer silenceStderr()()
 
 	// init cluster files
 	if !t.ops.Persistent {
-		// remove all files if not persistent after running
-		defer cleanup(t.ops.DataDir(), cfg["project"].(string), cfg["cluster_name"].(string), p)
+		// remove all files if not persistent after running.
+		// If destroying failed, the cluster may still exist, so its state is kept to delete it later.
+		defer func() {
+			if err == nil {
+				cleanup(t.ops.DataDir(), cfg["project"].(string), cfg["cluster_name"].(string), p)
+			}
+		}()
 	}
 
 	clusterDir, err := clusterDir(t.ops.DataDir(), cfg["project"].(string), cfg["cluster_name"].(string), p)
@@ -110,22 +196,40 @@ func (t *Terraform) Delete(sf *statefile.File, p types.ProviderType, cfg map[str
 		return err
 	}
 
+	// keep the current state and configuration in the history before they are replaced
+	if err := t.version(clusterDir, key, historyExisting); err != nil {
+		return err
+	}
+	defer t.versionOnReturn(clusterDir, key, historyDelete, &err)
+
 	// INIT
 	if p == types.Gardener {
-		if err := initGardenerProvider(); err != nil {
+		if err := initGardenerProvider(ops); err != nil {
 			return errors.Wrap(err, "could not initialize the gardener provider")
 		}
 	}
-	if err := tfInit(t.ops, p, cfg, clusterDir); err != nil {
+	if err := tfInit(ops, p, cfg, clusterDir); err != nil {
 		return err
 	}
-	if err := initClusterFiles(t.ops.DataDir(), p, cfg); err != nil {
+	if err := ops.phase(p, "cluster files", func() error { return initClusterFiles(t.ops.DataDir(), p, cfg) }); err != nil {
 		return errors.Wrap(err, "Could not initialize cluster data")
 	}
 
+	if key != nil {
+		// terraform needs the plain state while running, encrypt it again when done
+		if err := unsealState(clusterDir, key); err != nil {
+			return err
+		}
+		defer func() {
+			if sealErr := sealState(clusterDir, key); sealErr != nil && err == nil {
+				err = sealErr
+			}
+		}()
+	}
+
 	// if no state given, check if it is already in the file system
 	if sf == nil {
-		_, err := stateFromFile(t.ops.DataDir(), cfg["project"].(string), cfg["cluster_name"].(string), p)
+		_, err := stateFromFile(t.ops.DataDir(), cfg["project"].(string), cfg["cluster_name"].(string), p, key)
 		if err != nil {
 			return errors.Wrap(err, "no state provided, attempted to load from file")
 		}
@@ -134,11 +238,111 @@ func (t *Terraform) Delete(sf *statefile.File, p types.ProviderType, cfg map[str
 		if err := stateToFile(sf, t.ops.DataDir(), cfg["project"].(string), cfg["cluster_name"].(string), p); err != nil {
 			return errors.Wrap(err, "could not store state into file")
 		}
+		if err := t.version(clusterDir, key, historyExisting); err != nil {
+			return err
+		}
 	}
 
 	// APPLY
-	if err := tfDestroy(t.ops, p, cfg, clusterDir); err != nil {
+	if err := tfDestroy(ops, p, cfg, clusterDir); err != nil {
 		return err
 	}
 	return nil
 }
+
+// Import adopts an existing cluster into the state of the operator, so that it can be managed like a cluster created with Create.
+// It returns the ClusterInfo of the cluster and the settings in which it differs from the configuration.
+func (t *Terraform) Import(p types.ProviderType, cfg map[string]interface{}, id string) (ci *types.ClusterInfo, diffs []types.Difference, err error) {
+	// make sure no secrets leak through error messages
+	defer func() { err = redact(err, cfg) }()
+
+	applyTimeouts(cfg, t.ops.Timeouts)
+
+	// bound the whole operation by the operation timeout
+	ops := t.operationOptions()
+	defer ops.deadline.stop()
+
+	key, err := encryption.LoadKey(t.ops.EncryptionKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if sf, err := stateFromFile(t.ops.DataDir(), cfg["project"].(string), cfg["cluster_name"].(string), p, key); err == nil && sf.State.HasResources() {
+		return nil, nil, types.Classify(types.ErrorClassAlreadyExists, errors.Errorf("cluster %s is already managed, there is a state for it in the data directory", cfg["cluster_name"]))
+	}
+
+	if id == "" {
+		id = clusterID(p, cfg)
+	}
+	if id == "" {
+		return nil, nil, errors.Errorf("importing clusters is not supported for provider %s", p)
+	}
+
+	// pass sensitive values to terraform in a vars file of this operation instead of the vars file of the cluster
+	varsFile, removeVars, err := writeSensitiveVars(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer removeVars()
+	ops.sensitiveVars = varsFile
+
+	// silence stdErr during terraform execution, plugins send debug and trace entries there
+	defer silenceStderr()()
+
+	// init cluster files
+	if !t.ops.Persistent {
+		// remove all files if not persistent after running.
+		// If the operation timed out, terraform may have created resources, so their state is kept to delete them later.
+		defer func() {
+			if types.AsTimeout(err) == nil {
+				cleanup(t.ops.DataDir(), cfg["project"].(string), cfg["cluster_name"].(string), p)
+			}
+		}()
+	}
+
+	clusterDir, err := clusterDir(t.ops.DataDir(), cfg["project"].(string), cfg["cluster_name"].(string), p)
+	if err != nil {
+		return nil, nil, err
+	}
+	// INIT
+	if p == types.Gardener {
+		if err := initGardenerProvider(ops); err != nil {
+			return nil, nil, errors.Wrap(err, "could not initialize the gardener provider")
+		}
+	}
+	if err := tfInit(ops, p, cfg, clusterDir); err != nil {
+		return nil, nil, err
+	}
+
+	if err := ops.phase(p, "cluster files", func() error { return initClusterFiles(t.ops.DataDir(), p, cfg) }); err != nil {
+		return nil, nil, errors.Wrap(err, "Could not initialize cluster data")
+	}
+
+	if key != nil {
+		// the state is written in plain text by terraform, encrypt it when done
+		defer func() {
+			if sealErr := sealState(clusterDir, key); sealErr != nil && err == nil {
+				err = sealErr
+			}
+		}()
+	}
+
+	defer t.versionOnReturn(clusterDir, key, historyImport, &err)
+
+	// IMPORT
+	if err := tfImport(ops, p, cfg, clusterDir, id); err != nil {
+		return nil, nil, err
+	}
+
+	sf, err := stateFromFile(t.ops.DataDir(), cfg["project"].(string), cfg["cluster_name"].(string), p, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	diffs, err = differences(p, cfg, sf)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ci, err = clusterInfoFromFile(t.ops.DataDir(), cfg["project"].(string), cfg["cluster_name"].(string), p, key)
+	return ci, diffs, err
+}
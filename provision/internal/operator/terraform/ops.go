@@ -11,6 +11,7 @@ import (
 	"github.com/hashicorp/terraform/command/cliconfig"
 	"github.com/hashicorp/terraform/command/format"
 	"github.com/hashicorp/terraform/plugin/discovery"
+	"github.com/kyma-incubator/hydroform/provision/internal/tracing"
 	"github.com/kyma-incubator/hydroform/provision/types"
 	"github.com/mitchellh/colorstring"
 
@@ -28,6 +29,24 @@ type Options struct {
 
 	// Timeouts specifies the timeouts of the operations
 	Timeouts types.Timeouts
+
+	// EncryptionKey specifies the source of the key to encrypt the state with. If nil, the state is not encrypted.
+	EncryptionKey *types.EncryptionKey
+
+	// Metrics records the phases of the operations. If nil, nothing is recorded.
+	Metrics types.Metrics
+
+	// Tracer traces the phases of the operations. If nil, nothing is traced.
+	Tracer *tracing.Tracer
+
+	// PlanObserver receives the summary of every applied plan. If nil, the summaries are dropped.
+	PlanObserver func(types.PlanSummary)
+
+	// deadline bounds the running operation, it is set for each operation by the operator.
+	deadline *deadline
+
+	// sensitiveVars is the vars file with the sensitive values of the running operation, it is set for each operation by the operator.
+	sensitiveVars string
 }
 
 // Option is a function that allows to extensibly configure the terraform operator.
@@ -61,6 +80,34 @@ func WithTimeouts(timeouts types.Timeouts) Option {
 	}
 }
 
+// Encrypt the state with the key from the given source
+func WithEncryptionKey(key *types.EncryptionKey) Option {
+	return func(ops *Options) {
+		ops.EncryptionKey = key
+	}
+}
+
+// Record the phases of the operations with the given metrics
+func WithMetrics(m types.Metrics) Option {
+	return func(ops *Options) {
+		ops.Metrics = m
+	}
+}
+
+// Trace the phases of the operations with the given tracer
+func WithTracer(t *tracing.Tracer) Option {
+	return func(ops *Options) {
+		ops.Tracer = t
+	}
+}
+
+// Pass the summary of every applied plan to the given function
+func WithPlanObserver(f func(types.PlanSummary)) Option {
+	return func(ops *Options) {
+		ops.PlanObserver = f
+	}
+}
+
 // ToTerraformOptions turns Hydroform options into terraform operator specific options
 func ToTerraformOptions(ops *types.Options) (tfOps []Option) {
 
@@ -76,6 +123,22 @@ func ToTerraformOptions(ops *types.Options) (tfOps []Option) {
 		tfOps = append(tfOps, WithTimeouts(*ops.Timeouts))
 	}
 
+	if ops.EncryptionKey != nil {
+		tfOps = append(tfOps, WithEncryptionKey(ops.EncryptionKey))
+	}
+
+	if ops.Metrics != nil {
+		tfOps = append(tfOps, WithMetrics(ops.Metrics))
+	}
+
+	if t := tracing.New(ops); t != nil {
+		tfOps = append(tfOps, WithTracer(t))
+	}
+
+	if ops.PlanObserver != nil {
+		tfOps = append(tfOps, WithPlanObserver(ops.PlanObserver))
+	}
+
 	return tfOps
 }
 
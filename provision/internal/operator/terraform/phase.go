@@ -0,0 +1,51 @@
+package terraform
+
+import (
+	"time"
+
+	"github.com/kyma-incubator/hydroform/provision/internal/tracing"
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// phase runs a phase of the operation bound by the deadline of the operation, traces it and records it in the metrics.
+func (ops Options) phase(p types.ProviderType, name string, f func() error) error {
+	start := time.Now()
+	_, span := ops.Tracer.Start(name)
+	err := ops.deadline.run(name, f)
+	ops.observePhase(p, name, start, span, err)
+	return err
+}
+
+// uninterruptiblePhase runs a phase of the operation that cannot be stopped, see deadline.runToCompletion, traces it and records it in the metrics.
+func (ops Options) uninterruptiblePhase(p types.ProviderType, name string, f func() error) error {
+	start := time.Now()
+	_, span := ops.Tracer.Start(name)
+	err := ops.deadline.runToCompletion(name, f)
+	ops.observePhase(p, name, start, span, err)
+	return err
+}
+
+func (ops Options) observePhase(p types.ProviderType, name string, start time.Time, span trace.Span, err error) {
+	tracing.End(span, err)
+	if ops.Metrics != nil {
+		ops.Metrics.ObservePhase(name, p, time.Since(start), err)
+	}
+}
+
+// retry records in the metrics that a phase is retried for the given reason.
+func (ops Options) retry(p types.ProviderType, name, reason string) {
+	if ops.Metrics != nil {
+		ops.Metrics.ObserveRetry(name, p, reason)
+	}
+}
+
+// reportPlan passes the summary of the plan terraform applied last to the plan observer, if any.
+func (ops Options) reportPlan() {
+	if ops.PlanObserver == nil {
+		return
+	}
+	if h, ok := ops.Ui.(*HydroUI); ok && h.PlanSummary() != nil {
+		ops.PlanObserver(*h.PlanSummary())
+	}
+}
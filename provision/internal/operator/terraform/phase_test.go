@@ -0,0 +1,45 @@
+package terraform
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type phaseRecorder struct {
+	phases  []string
+	errs    []error
+	retries []string
+}
+
+func (r *phaseRecorder) ObserveOperation(operation string, p types.ProviderType, d time.Duration, err error) {
+}
+
+func (r *phaseRecorder) ObservePhase(phase string, p types.ProviderType, d time.Duration, err error) {
+	r.phases = append(r.phases, string(p)+"/"+phase)
+	r.errs = append(r.errs, err)
+}
+
+func (r *phaseRecorder) ObserveRetry(phase string, p types.ProviderType, reason string) {
+	r.retries = append(r.retries, phase+": "+reason)
+}
+
+func TestPhaseMetrics(t *testing.T) {
+	r := &phaseRecorder{}
+	ops := Options{Metrics: r}
+
+	require.NoError(t, ops.uninterruptiblePhase(types.Azure, "init", func() error { return nil }))
+	require.Error(t, ops.phase(types.Azure, "apply", func() error { return errors.New("already exists") }))
+	ops.retry(types.Azure, "apply", "already exists")
+
+	require.Equal(t, []string{"azure/init", "azure/apply"}, r.phases)
+	require.NoError(t, r.errs[0])
+	require.EqualError(t, r.errs[1], "already exists")
+	require.Equal(t, []string{"apply: already exists"}, r.retries)
+
+	// without metrics the phases just run
+	require.NoError(t, Options{}.phase(types.Azure, "apply", func() error { return nil }))
+}
@@ -0,0 +1,81 @@
+package terraform
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kyma-incubator/hydroform/provision/internal/operator"
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	// tfVarEnvPrefix is the prefix terraform uses to read variables from the environment, ejected projects read the sensitive values from there.
+	tfVarEnvPrefix = "TF_VAR_"
+	redacted       = "[REDACTED]"
+)
+
+// sensitiveVarsFile is the name of the vars file holding the sensitive values of an operation.
+const sensitiveVarsFile = "sensitive.tfvars"
+
+// writeSensitiveVars writes all sensitive values in the config into a vars file in a private temporary directory, readable only by the owner,
+// so that they are never written into the vars file of the cluster and concurrent operations do not share them.
+// It returns the path of the file, or an empty path if the config has no sensitive values, and a function that removes the file.
+func writeSensitiveVars(cfg map[string]interface{}) (string, func(), error) {
+	keys := make([]string, 0, len(cfg))
+	for k, v := range cfg {
+		if _, ok := v.(operator.Sensitive); ok {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return "", func() {}, nil
+	}
+	sort.Strings(keys)
+
+	var vars strings.Builder
+	for _, k := range keys {
+		if _, err := vars.WriteString(fmt.Sprintf("%s = %s\n", k, quote(string(cfg[k].(operator.Sensitive))))); err != nil {
+			return "", func() {}, err
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "hydroform-")
+	if err != nil {
+		return "", func() {}, errors.Wrap(err, "could not create the directory for sensitive variables")
+	}
+	remove := func() { os.RemoveAll(dir) }
+	path := filepath.Join(dir, sensitiveVarsFile)
+	if err := ioutil.WriteFile(path, []byte(vars.String()), 0600); err != nil {
+		remove()
+		return "", func() {}, errors.Wrap(err, "could not write the sensitive variables")
+	}
+	return path, remove, nil
+}
+
+// redact removes any sensitive value from the config out of the given error message.
+func redact(err error, cfg map[string]interface{}) error {
+	if err == nil {
+		return nil
+	}
+	// keep timeouts recognizable, only their cause can contain values
+	if te, ok := err.(*types.TimeoutError); ok {
+		te.Err = redact(te.Err, cfg)
+		return te
+	}
+
+	msg := err.Error()
+	for _, v := range cfg {
+		if s, ok := v.(operator.Sensitive); ok && s != "" {
+			msg = strings.Replace(msg, string(s), redacted, -1)
+		}
+	}
+	if msg == err.Error() {
+		return err
+	}
+	return errors.New(msg)
+}
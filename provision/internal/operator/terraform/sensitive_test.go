@@ -0,0 +1,65 @@
+package terraform
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kyma-incubator/hydroform/provision/internal/operator"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteSensitiveVars(t *testing.T) {
+	cfg := map[string]interface{}{
+		"cluster_name":  "my-cluster",
+		"client_secret": operator.Sensitive(`super"secret`),
+	}
+
+	path, remove, err := writeSensitiveVars(cfg)
+	require.NoError(t, err)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm(), "Only the owner should read the sensitive values")
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "client_secret = \"super\\\"secret\"\n", string(data), "Only sensitive values should be written, quoted for terraform")
+
+	remove()
+	_, err = os.Stat(filepath.Dir(path))
+	require.True(t, os.IsNotExist(err), "The directory of the sensitive values should be removed")
+
+	path, remove, err = writeSensitiveVars(map[string]interface{}{"cluster_name": "my-cluster"})
+	require.NoError(t, err)
+	require.Empty(t, path, "No file should be written without sensitive values")
+	remove()
+}
+
+func TestWriteSensitiveVarsConcurrently(t *testing.T) {
+	// operations with different credentials must not wait for each other
+	path1, remove1, err := writeSensitiveVars(map[string]interface{}{"client_secret": operator.Sensitive("super-secret")})
+	require.NoError(t, err)
+	defer remove1()
+	path2, remove2, err := writeSensitiveVars(map[string]interface{}{"client_secret": operator.Sensitive("other-secret")})
+	require.NoError(t, err)
+	defer remove2()
+
+	require.NotEqual(t, filepath.Dir(path1), filepath.Dir(path2), "Each operation should have its own directory")
+}
+
+func TestRedact(t *testing.T) {
+	cfg := map[string]interface{}{
+		"cluster_name":  "my-cluster",
+		"client_secret": operator.Sensitive("super-secret"),
+	}
+
+	require.NoError(t, redact(nil, cfg))
+
+	err := redact(errors.New("authentication with super-secret failed for my-cluster"), cfg)
+	require.EqualError(t, err, "authentication with [REDACTED] failed for my-cluster")
+
+	original := errors.New("nothing to hide")
+	require.Equal(t, original, redact(original, cfg), "Errors without secrets should not be modified")
+}
@@ -0,0 +1,41 @@
+package terraform
+
+import (
+	"os"
+	"sync"
+)
+
+var (
+	// stderrMu guards the silencing of stderr, operations running concurrently share it.
+	stderrMu       sync.Mutex
+	stderrSilenced int
+	stderr         *os.File
+)
+
+// silenceStderr redirects stderr to the null device while terraform runs, plugins send debug and trace entries there.
+// It returns a function that restores stderr once no operation needs it silenced anymore.
+func silenceStderr() func() {
+	stderrMu.Lock()
+	defer stderrMu.Unlock()
+
+	if stderrSilenced == 0 {
+		stderr = os.Stderr
+		os.Stderr, _ = os.Open(os.DevNull)
+	}
+	stderrSilenced++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			stderrMu.Lock()
+			defer stderrMu.Unlock()
+
+			if stderrSilenced--; stderrSilenced == 0 {
+				if os.Stderr != nil {
+					os.Stderr.Close()
+				}
+				os.Stderr = stderr
+			}
+		})
+	}
+}
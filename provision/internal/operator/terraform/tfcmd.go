@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	be_init "github.com/hashicorp/terraform/backend/init"
@@ -23,10 +24,13 @@ func tfInit(ops Options, p types.ProviderType, cfg map[string]interface{}, dir s
 		Meta: ops.Meta,
 	}
 
-	if e := i.Run(initArgs(p, cfg, dir)); e != 0 {
-		return checkUIErrors(ops.Ui)
-	}
-	return nil
+	// init does not watch the shutdown channel, the operation waits for it even when the deadline is exceeded
+	return ops.uninterruptiblePhase(p, "init", func() error {
+		if e := i.Run(initArgs(p, cfg, dir)); e != 0 {
+			return checkUIErrors(ops.Ui)
+		}
+		return nil
+	})
 }
 
 // initArgs generates the flag list for the terraform init command based on the operator configuration
@@ -81,28 +85,22 @@ func tfApply(ops Options, p types.ProviderType, cfg map[string]interface{}, dir
 	a := &command.ApplyCommand{
 		Meta: ops.Meta,
 	}
-	e := a.Run(applyArgs(p, cfg, dir))
-	if e != 0 {
-		errList := checkUIErrors(ops.Ui)
+	errList := ops.phase(p, "apply", func() error {
+		if e := a.Run(applyArgs(p, cfg, dir, ops.sensitiveVars)); e != 0 {
+			return checkUIErrors(ops.Ui)
+		}
+		return nil
+	})
+	if errList != nil {
+		// an apply stopped by the deadline must not be retried
+		if types.AsTimeout(errList) != nil {
+			return errList
+		}
 
 		// if cluster already exists import it and refresh the state
 		if strings.Contains(strings.ToLower(errList.Error()), "already exists") {
-			i := &command.ImportCommand{
-				Meta: ops.Meta,
-			}
-
-			if e := i.Run(importArgs(p, cfg, dir)); e != 0 {
-				return checkUIErrors(ops.Ui)
-			}
-
-			r := &command.RefreshCommand{
-				Meta: ops.Meta,
-			}
-
-			if e := r.Run(refreshArgs(p, cfg, dir)); e != 0 {
-				return checkUIErrors(ops.Ui)
-			}
-			return nil
+			ops.retry(p, "apply", "already exists")
+			return tfImport(ops, p, cfg, dir, clusterID(p, cfg))
 		}
 
 		// if cluster was not found, cluster got deeted on the remote or state is wrong, delete state and start over
@@ -114,27 +112,64 @@ func tfApply(ops Options, p types.ProviderType, cfg map[string]interface{}, dir
 			}
 
 			// try applying again
+			ops.retry(p, "apply", "not found")
 			return tfApply(ops, p, cfg, dir)
 		}
 		return errList
 	}
+	ops.reportPlan()
 	return nil
 }
 
+// tfImport runs the 'terraform import' command for the cluster with the given ID and refreshes the imported state.
+func tfImport(ops Options, p types.ProviderType, cfg map[string]interface{}, dir, id string) error {
+	i := &command.ImportCommand{
+		Meta: ops.Meta,
+	}
+
+	err := ops.phase(p, "import", func() error {
+		if e := i.Run(importArgs(p, cfg, dir, id, ops.sensitiveVars)); e != 0 {
+			return checkUIErrors(ops.Ui)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	r := &command.RefreshCommand{
+		Meta: ops.Meta,
+	}
+
+	return ops.phase(p, "refresh", func() error {
+		if e := r.Run(refreshArgs(p, cfg, dir, ops.sensitiveVars)); e != 0 {
+			return checkUIErrors(ops.Ui)
+		}
+		return nil
+	})
+}
+
 // tfDestroy runs the 'terraform destroy' command with the specified options and config in the given working directory
 func tfDestroy(ops Options, p types.ProviderType, cfg map[string]interface{}, dir string) error {
 	a := &command.ApplyCommand{
 		Meta:    ops.Meta,
 		Destroy: true,
 	}
-	if e := a.Run(applyArgs(p, cfg, dir)); e != 0 {
-		return checkUIErrors(ops.Ui)
+	err := ops.phase(p, "destroy", func() error {
+		if e := a.Run(applyArgs(p, cfg, dir, ops.sensitiveVars)); e != 0 {
+			return checkUIErrors(ops.Ui)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
+	ops.reportPlan()
 	return nil
 }
 
 // applyArgs generates the flag list for the terraform apply command based on the operator configuration
-func applyArgs(p types.ProviderType, cfg map[string]interface{}, clusterDir string) []string {
+func applyArgs(p types.ProviderType, cfg map[string]interface{}, clusterDir, sensitiveVars string) []string {
 	args := make([]string, 0)
 
 	stateFile := filepath.Join(clusterDir, tfStateFile)
@@ -142,7 +177,9 @@ func applyArgs(p types.ProviderType, cfg map[string]interface{}, clusterDir stri
 
 	args = append(args,
 		fmt.Sprintf("-state=%s", stateFile),
-		fmt.Sprintf("-var-file=%s", varsFile),
+		fmt.Sprintf("-var-file=%s", varsFile))
+	args = appendSensitiveVars(args, sensitiveVars)
+	args = append(args,
 		"-auto-approve",
 		clusterDir)
 
@@ -150,7 +187,7 @@ func applyArgs(p types.ProviderType, cfg map[string]interface{}, clusterDir stri
 }
 
 // importArgs generates the flag list for the terraform import command based on the operator configuration
-func importArgs(p types.ProviderType, cfg map[string]interface{}, clusterDir string) []string {
+func importArgs(p types.ProviderType, cfg map[string]interface{}, clusterDir, id, sensitiveVars string) []string {
 	args := make([]string, 0)
 
 	stateFile := filepath.Join(clusterDir, tfStateFile)
@@ -159,10 +196,12 @@ func importArgs(p types.ProviderType, cfg map[string]interface{}, clusterDir str
 	args = append(args,
 		fmt.Sprintf("-state=%s", stateFile),
 		fmt.Sprintf("-state-out=%s", stateFile),
-		fmt.Sprintf("-var-file=%s", varsFile),
+		fmt.Sprintf("-var-file=%s", varsFile))
+	args = appendSensitiveVars(args, sensitiveVars)
+	args = append(args,
 		fmt.Sprintf("-config=%s", clusterDir),
 		clusterResource(p), // cluster resource
-		clusterID(p, cfg))  // cluster ID
+		id)                 // cluster ID
 
 	return args
 }
@@ -176,6 +215,8 @@ func clusterResource(p types.ProviderType) string {
 		return "azurerm_kubernetes_cluster.azure_cluster"
 	case types.Gardener:
 		return "gardener_shoot.gardener_cluster"
+	case types.Kind:
+		return "kind.kind-cluster"
 	case types.AWS:
 		return "not supported"
 	}
@@ -190,6 +231,10 @@ func clusterID(p types.ProviderType, cfg map[string]interface{}) string {
 		return fmt.Sprintf("%s/%s/%s", cfg["project"], cfg["location"], cfg["cluster_name"])
 	case types.Gardener:
 		return fmt.Sprintf("%s/%s", cfg["namespace"], cfg["cluster_name"])
+	case types.Azure:
+		return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerService/managedClusters/%s", cfg["subscription_id"], cfg["resource_group"], cfg["cluster_name"])
+	case types.Kind:
+		return fmt.Sprintf("%s", cfg["cluster_name"])
 	case types.AWS:
 		return "not supported"
 	}
@@ -197,7 +242,7 @@ func clusterID(p types.ProviderType, cfg map[string]interface{}) string {
 }
 
 // refreshArgs generates the flag list for the terraform refresh command based on the operator configuration
-func refreshArgs(p types.ProviderType, cfg map[string]interface{}, clusterDir string) []string {
+func refreshArgs(p types.ProviderType, cfg map[string]interface{}, clusterDir, sensitiveVars string) []string {
 	args := make([]string, 0)
 
 	stateFile := filepath.Join(clusterDir, tfStateFile)
@@ -205,12 +250,21 @@ func refreshArgs(p types.ProviderType, cfg map[string]interface{}, clusterDir st
 
 	args = append(args,
 		fmt.Sprintf("-state=%s", stateFile),
-		fmt.Sprintf("-var-file=%s", varsFile),
-		clusterDir)
+		fmt.Sprintf("-var-file=%s", varsFile))
+	args = appendSensitiveVars(args, sensitiveVars)
+	args = append(args, clusterDir)
 
 	return args
 }
 
+// appendSensitiveVars adds the vars file with the sensitive values of the operation, if there is one.
+func appendSensitiveVars(args []string, sensitiveVars string) []string {
+	if sensitiveVars == "" {
+		return args
+	}
+	return append(args, fmt.Sprintf("-var-file=%s", sensitiveVars))
+}
+
 func checkUIErrors(ui hashiCli.Ui) error {
 	var errsum strings.Builder
 	if h, ok := ui.(*HydroUI); ok {
@@ -222,8 +276,31 @@ func checkUIErrors(ui hashiCli.Ui) error {
 	}
 
 	if errsum.Len() != 0 {
-		return errors.New(errsum.String())
+		return classifyProviderError(errors.New(errsum.String()))
 	}
 
 	return nil
 }
+
+// providerErrorClasses recognize the error codes of the cloud APIs, the Kubernetes API of Gardener and terraform in the errors of terraform, which passes them on as text only.
+// Quota errors come first, since GCP reports them with status 403 as well. Errors matching none of them keep the class other.
+var providerErrorClasses = []struct {
+	code  *regexp.Regexp
+	class string
+}{
+	{regexp.MustCompile(`quotaExceeded|QUOTA_EXCEEDED|QuotaExceeded|RESOURCE_EXHAUSTED|rateLimitExceeded|Error 429|StatusCode=429|exceeded quota|exceeding approved .* quota`), types.ErrorClassQuota},
+	{regexp.MustCompile(`Error 40[13]|StatusCode=40[13]|AuthorizationFailed|InvalidAuthenticationToken|AADSTS[0-9]+|invalid_grant|oauth2: cannot fetch token|could not find default credentials|Unauthorized|is forbidden`), types.ErrorClassCredentials},
+	{regexp.MustCompile(`Error 409|StatusCode=409|already exists|AlreadyExists`), types.ErrorClassAlreadyExists},
+	{regexp.MustCompile(`Error 404|StatusCode=404|ResourceNotFound|ResourceGroupNotFound|Code="NotFound"`), types.ErrorClassNotFound},
+	{regexp.MustCompile(`Error 400|StatusCode=400|InvalidParameter|badRequest|Invalid value for variable|Unsupported argument|Missing required argument|Invalid variable value`), types.ErrorClassValidation},
+}
+
+// classifyProviderError marks an error of terraform with the class of the cloud API error it contains, see types.ErrorClass.
+func classifyProviderError(err error) error {
+	for _, c := range providerErrorClasses {
+		if c.code.MatchString(err.Error()) {
+			return types.Classify(c.class, err)
+		}
+	}
+	return err
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -35,13 +36,18 @@ func TestInitArgs(t *testing.T) {
 
 func TestApplyArgs(t *testing.T) {
 	// for now apply args does not use the cluster and provider config for anything
-	res := applyArgs("", nil, "/path/to/cluster")
+	res := applyArgs("", nil, "/path/to/cluster", "")
 
 	require.Len(t, res, 4)
 	require.Equal(t, "-state=/path/to/cluster/terraform.tfstate", res[0])   // state file
 	require.Equal(t, "-var-file=/path/to/cluster/terraform.tfvars", res[1]) // vars file
 	require.Equal(t, "-auto-approve", res[2])                               // auto approve is important so that hydroform does not wait for user confirmation
 	require.Equal(t, "/path/to/cluster", res[3])                            // cluster config directory
+
+	res = applyArgs("", nil, "/path/to/cluster", "/tmp/hydroform-1/sensitive.tfvars")
+	require.Len(t, res, 5)
+	require.Equal(t, "-var-file=/tmp/hydroform-1/sensitive.tfvars", res[2]) // sensitive vars file of the operation
+	require.Equal(t, "/path/to/cluster", res[4])
 }
 
 func TestImportArgs(t *testing.T) {
@@ -49,7 +55,7 @@ func TestImportArgs(t *testing.T) {
 	cfg := map[string]interface{}{"project": "my-project", "namespace": "my-namespace", "location": "somewhere", "cluster_name": "my-cluster"}
 
 	// test GCP
-	res := importArgs(types.GCP, cfg, "/path/to/cluster")
+	res := importArgs(types.GCP, cfg, "/path/to/cluster", clusterID(types.GCP, cfg), "")
 	require.Len(t, res, 6)
 	require.Equal(t, "-state=/path/to/cluster/terraform.tfstate", res[0])     // state file
 	require.Equal(t, "-state-out=/path/to/cluster/terraform.tfstate", res[1]) // state output file
@@ -59,7 +65,7 @@ func TestImportArgs(t *testing.T) {
 	require.Equal(t, "my-project/somewhere/my-cluster", res[5])               // cluster ID
 
 	// test Gardener
-	res = importArgs(types.Gardener, cfg, "/path/to/cluster")
+	res = importArgs(types.Gardener, cfg, "/path/to/cluster", clusterID(types.Gardener, cfg), "")
 	require.Len(t, res, 6)
 	require.Equal(t, "-state=/path/to/cluster/terraform.tfstate", res[0])     // state file
 	require.Equal(t, "-state-out=/path/to/cluster/terraform.tfstate", res[1]) // state output file
@@ -67,4 +73,53 @@ func TestImportArgs(t *testing.T) {
 	require.Equal(t, "-config=/path/to/cluster", res[3])                      // config folder for import to know where the tf files are (if any)
 	require.Equal(t, "gardener_shoot.gardener_cluster", res[4])               // resource type for a GCP cluster
 	require.Equal(t, "my-namespace/my-cluster", res[5])                       // cluster ID
+
+	// test an explicit ID
+	res = importArgs(types.Kind, cfg, "/path/to/cluster", "other-cluster", "")
+	require.Len(t, res, 6)
+	require.Equal(t, "kind.kind-cluster", res[4]) // resource type for a kind cluster
+	require.Equal(t, "other-cluster", res[5])     // cluster ID
+}
+
+func TestClusterID(t *testing.T) {
+	cfg := map[string]interface{}{
+		"project":         "my-project",
+		"location":        "somewhere",
+		"cluster_name":    "my-cluster",
+		"subscription_id": "my-subscription",
+		"resource_group":  "my-group",
+	}
+
+	require.Equal(t, "/subscriptions/my-subscription/resourceGroups/my-group/providers/Microsoft.ContainerService/managedClusters/my-cluster", clusterID(types.Azure, cfg))
+	require.Equal(t, "my-cluster", clusterID(types.Kind, cfg))
+	require.Equal(t, "", clusterID(types.ProviderType("unknown"), cfg))
+}
+
+func TestClassifyProviderError(t *testing.T) {
+	for msg, class := range map[string]string{
+		"googleapi: Error 403: Insufficient regional quota to satisfy request, quotaExceeded":                         types.ErrorClassQuota,
+		"googleapi: Error 403: Required 'container.clusters.create' permission, forbidden":                            types.ErrorClassCredentials,
+		`containerservice.ManagedClustersClient: StatusCode=403 -- Code="AuthorizationFailed"`:                        types.ErrorClassCredentials,
+		"googleapi: Error 409: Already exists: projects/my-project/locations/europe-west3/clusters/c":                 types.ErrorClassAlreadyExists,
+		`StatusCode=404 -- Code="ResourceGroupNotFound"`:                                                              types.ErrorClassNotFound,
+		"googleapi: Error 429: Quota exceeded for quota metric, rateLimitExceeded":                                    types.ErrorClassQuota,
+		"rpc error: code = ResourceExhausted desc = RESOURCE_EXHAUSTED":                                               types.ErrorClassQuota,
+		`Code="OperationNotAllowed" Message="Operation results in exceeding approved standardDSv2Family Cores quota"`: types.ErrorClassQuota,
+		`shoots.core.gardener.cloud "c" is forbidden: exceeded quota: garden-quota`:                                   types.ErrorClassQuota,
+		"oauth2: cannot fetch token: 400 Bad Request Response: invalid_grant":                                         types.ErrorClassCredentials,
+		"AADSTS7000215: Invalid client secret is provided.":                                                           types.ErrorClassCredentials,
+		`shoots.core.gardener.cloud is forbidden: User "robot" cannot create resource "shoots"`:                       types.ErrorClassCredentials,
+		"Unauthorized": types.ErrorClassCredentials,
+		`shoots.core.gardener.cloud "c" AlreadyExists`:                              types.ErrorClassAlreadyExists,
+		`StatusCode=404 -- Code="NotFound"`:                                         types.ErrorClassNotFound,
+		"googleapi: Error 400: Master version \"1.99\" is unsupported., badRequest": types.ErrorClassValidation,
+		`StatusCode=400 -- Code="InvalidParameter"`:                                 types.ErrorClassValidation,
+		"Invalid value for variable":                                                types.ErrorClassValidation,
+		"Unsupported argument":                                                      types.ErrorClassValidation,
+		"Failed to instantiate provider \"kind\" to obtain schema":                  types.ErrorClassOther,
+	} {
+		err := classifyProviderError(errors.New(msg))
+		require.Equal(t, class, types.ErrorClass(err), msg)
+		require.EqualError(t, err, msg, "The message should not change")
+	}
 }
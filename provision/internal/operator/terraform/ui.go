@@ -1,11 +1,21 @@
 package terraform
 
 import (
+	"regexp"
+	"strconv"
+
+	"github.com/kyma-incubator/hydroform/provision/types"
 	"github.com/pkg/errors"
 )
 
+var (
+	applySummary   = regexp.MustCompile(`Apply complete! Resources: (\d+) added, (\d+) changed, (\d+) destroyed`)
+	destroySummary = regexp.MustCompile(`Destroy complete! Resources: (\d+) destroyed`)
+)
+
 type HydroUI struct {
-	errs []error
+	errs    []error
+	summary *types.PlanSummary
 }
 
 // Ask asks the user for input using the given query. For Hydroform,
@@ -21,8 +31,14 @@ func (h *HydroUI) AskSecret(string) (string, error) {
 }
 
 // Output is called for normal standard output.
-// Terraform output is ignored in Hydroform
-func (h *HydroUI) Output(string) {}
+// Terraform output is ignored in Hydroform, except for the summary of applied plans.
+func (h *HydroUI) Output(s string) {
+	if m := applySummary.FindStringSubmatch(s); m != nil {
+		h.summary = &types.PlanSummary{Added: atoi(m[1]), Changed: atoi(m[2]), Destroyed: atoi(m[3])}
+	} else if m := destroySummary.FindStringSubmatch(s); m != nil {
+		h.summary = &types.PlanSummary{Destroyed: atoi(m[1])}
+	}
+}
 
 // Info is called for information related to the previous output.
 // In general this may be the exact same as Output, but this gives
@@ -44,3 +60,14 @@ func (h *HydroUI) Warn(s string) {
 func (h *HydroUI) Errors() []error {
 	return h.errs
 }
+
+// PlanSummary returns the summary of the last plan terraform applied, or nil if it did not apply any.
+func (h *HydroUI) PlanSummary() *types.PlanSummary {
+	return h.summary
+}
+
+func atoi(s string) int {
+	// the expressions only match digits
+	i, _ := strconv.Atoi(s)
+	return i
+}
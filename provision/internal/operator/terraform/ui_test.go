@@ -3,6 +3,7 @@ package terraform
 import (
 	"testing"
 
+	"github.com/kyma-incubator/hydroform/provision/types"
 	"github.com/stretchr/testify/require"
 )
 
@@ -30,3 +31,22 @@ func TestWarnAnError(t *testing.T) {
 
 	require.Len(t, ui.Errors(), 2, "There should be 2 errors in total (1 errror and 1 warning)")
 }
+
+func TestPlanSummary(t *testing.T) {
+	ui := &HydroUI{}
+
+	ui.Output("Initializing the backend...")
+	require.Nil(t, ui.PlanSummary(), "There should be no summary before a plan is applied")
+
+	ui.Output("\nApply complete! Resources: 3 added, 1 changed, 0 destroyed.")
+	require.Equal(t, &types.PlanSummary{Added: 3, Changed: 1}, ui.PlanSummary())
+
+	ui.Output("\nDestroy complete! Resources: 4 destroyed.")
+	require.Equal(t, &types.PlanSummary{Destroyed: 4}, ui.PlanSummary())
+
+	var observed []types.PlanSummary
+	ops := Options{PlanObserver: func(s types.PlanSummary) { observed = append(observed, s) }}
+	ops.Ui = ui
+	ops.reportPlan()
+	require.Equal(t, []types.PlanSummary{{Destroyed: 4}}, observed)
+}
@@ -16,11 +16,31 @@ func (u *Unknown) Create(p types.ProviderType, cfg map[string]interface{}) (*typ
 	return nil, errors.New("unknown operator")
 }
 
-func (u *Unknown) Status(state *statefile.File, p types.ProviderType, cfg map[string]interface{}) (*types.ClusterStatus, error) {
+func (u *Unknown) Status(state *types.InternalState, p types.ProviderType, cfg map[string]interface{}) (*types.ClusterStatus, error) {
 	return nil, errors.New("unknown operator")
 }
 
 // Delete returns an error if the operator is unknown.
-func (u *Unknown) Delete(state *statefile.File, p types.ProviderType, cfg map[string]interface{}) error {
+func (u *Unknown) Delete(state *types.InternalState, p types.ProviderType, cfg map[string]interface{}) error {
 	return errors.New("unknown operator")
 }
+
+// Import returns an error if the operator is unknown.
+func (u *Unknown) Import(p types.ProviderType, cfg map[string]interface{}, id string) (*types.ClusterInfo, []types.Difference, error) {
+	return nil, nil, errors.New("unknown operator")
+}
+
+// Eject returns an error if the operator is unknown.
+func (u *Unknown) Eject(state *types.InternalState, p types.ProviderType, cfg map[string]interface{}, dir string) error {
+	return errors.New("unknown operator")
+}
+
+// Rollback returns an error if the operator is unknown.
+func (u *Unknown) Rollback(p types.ProviderType, cfg map[string]interface{}, serial uint64) (*types.ClusterInfo, error) {
+	return nil, errors.New("unknown operator")
+}
+
+// State returns an error if the operator is unknown.
+func (u *Unknown) State(state *types.InternalState) (*statefile.File, error) {
+	return nil, errors.New("unknown operator")
+}
@@ -0,0 +1,144 @@
+package readiness
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+// systemNamespace is the namespace of the workloads that must run for a cluster to be usable.
+const systemNamespace = "kube-system"
+
+// pollInterval is the time between two rounds of checks.
+var pollInterval = 10 * time.Second
+
+// check tells whether a part of the cluster is ready and describes its state.
+type check struct {
+	name string
+	run  func(k8s kubernetes.Interface) (bool, string)
+}
+
+// checks are run in order, since later checks cannot pass before the earlier ones.
+var checks = []check{
+	{name: types.ReadinessAPIServer, run: apiServerReady},
+	{name: types.ReadinessNodes, run: nodesReady},
+	{name: types.ReadinessSystemWorkloads, run: systemWorkloadsReady},
+}
+
+// Wait checks the cluster with the given client until it is usable, the timeout is reached or the context is done.
+// Every change in the result of a check is reported to the observer, if any. Exceeding the timeout returns a TimeoutError.
+func Wait(ctx context.Context, k8s kubernetes.Interface, cluster string, timeout time.Duration, observer func(types.ReadinessEvent)) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	last := make(map[string]types.ReadinessEvent)
+	report := func(name string, ready bool, msg string) {
+		e := types.ReadinessEvent{Cluster: cluster, Check: name, Ready: ready, Message: msg}
+		if prev, ok := last[name]; ok && prev.Ready == ready && prev.Message == msg {
+			return
+		}
+		last[name] = e
+		if observer != nil {
+			e.Elapsed = time.Since(start)
+			observer(e)
+		}
+	}
+
+	var pending string
+	err := wait.PollImmediateUntil(pollInterval, func() (bool, error) {
+		for _, c := range checks {
+			ready, msg := c.run(k8s)
+			report(c.name, ready, msg)
+			if !ready {
+				pending = fmt.Sprintf("%s: %s", c.name, msg)
+				return false, nil
+			}
+		}
+		return true, nil
+	}, ctx.Done())
+
+	if err == wait.ErrWaitTimeout {
+		if ctx.Err() == context.DeadlineExceeded {
+			return &types.TimeoutError{
+				Phase:   "readiness",
+				Timeout: timeout,
+				Err:     errors.Errorf("cluster %s is not ready, %s", cluster, pending),
+			}
+		}
+		return errors.Wrapf(ctx.Err(), "stopped waiting for cluster %s", cluster)
+	}
+	return err
+}
+
+func apiServerReady(k8s kubernetes.Interface) (bool, string) {
+	v, err := k8s.Discovery().ServerVersion()
+	if err != nil {
+		return false, err.Error()
+	}
+	return true, fmt.Sprintf("reachable, running %s", v.GitVersion)
+}
+
+func nodesReady(k8s kubernetes.Interface) (bool, string) {
+	nodes, err := k8s.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return false, err.Error()
+	}
+	if len(nodes.Items) == 0 {
+		return false, "no nodes registered yet"
+	}
+
+	ready := 0
+	for _, n := range nodes.Items {
+		for _, c := range n.Status.Conditions {
+			if c.Type == corev1.NodeReady && c.Status == corev1.ConditionTrue {
+				ready++
+			}
+		}
+	}
+	return ready == len(nodes.Items), fmt.Sprintf("%d of %d nodes ready", ready, len(nodes.Items))
+}
+
+func systemWorkloadsReady(k8s kubernetes.Interface) (bool, string) {
+	deployments, err := k8s.AppsV1().Deployments(systemNamespace).List(metav1.ListOptions{})
+	if err != nil {
+		return false, err.Error()
+	}
+	daemonSets, err := k8s.AppsV1().DaemonSets(systemNamespace).List(metav1.ListOptions{})
+	if err != nil {
+		return false, err.Error()
+	}
+
+	var waiting []string
+	for _, d := range deployments.Items {
+		// deployments without replicas count run a single one
+		want := int32(1)
+		if d.Spec.Replicas != nil {
+			want = *d.Spec.Replicas
+		}
+		if d.Status.ReadyReplicas < want {
+			waiting = append(waiting, "deployment "+d.Name)
+		}
+	}
+	for _, ds := range daemonSets.Items {
+		if ds.Status.NumberReady < ds.Status.DesiredNumberScheduled {
+			waiting = append(waiting, "daemon set "+ds.Name)
+		}
+	}
+
+	total := len(deployments.Items) + len(daemonSets.Items)
+	if len(waiting) > 0 {
+		sort.Strings(waiting)
+		return false, fmt.Sprintf("%d of %d workloads running, waiting for %s", total-len(waiting), total, strings.Join(waiting, ", "))
+	}
+	return true, fmt.Sprintf("%d of %d workloads running", total, total)
+}
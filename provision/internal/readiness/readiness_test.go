@@ -0,0 +1,78 @@
+package readiness
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestWait(t *testing.T) {
+	defer func(i time.Duration) { pollInterval = i }(pollInterval)
+	pollInterval = 10 * time.Millisecond
+	replicas := int32(2)
+	coreDNS := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "coredns", Namespace: systemNamespace},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{ReadyReplicas: 1},
+	}
+	k8s := fake.NewSimpleClientset(node("node-1", corev1.ConditionTrue), node("node-2", corev1.ConditionFalse), coreDNS)
+
+	var events []types.ReadinessEvent
+	err := Wait(context.Background(), k8s, "my-cluster", 100*time.Millisecond, func(e types.ReadinessEvent) { events = append(events, e) })
+	te := types.AsTimeout(err)
+	require.NotNil(t, te, "Waiting for a cluster that does not become ready should time out")
+	require.Equal(t, "readiness", te.Phase)
+	require.Contains(t, err.Error(), "1 of 2 nodes ready")
+
+	require.Len(t, events, 2, "Only changes of the checks should be reported")
+	require.Equal(t, types.ReadinessAPIServer, events[0].Check)
+	require.True(t, events[0].Ready)
+	require.Equal(t, "my-cluster", events[0].Cluster)
+	require.Equal(t, types.ReadinessNodes, events[1].Check)
+	require.False(t, events[1].Ready)
+
+	// all nodes become ready, but coredns is still starting
+	_, err = k8s.CoreV1().Nodes().Update(node("node-2", corev1.ConditionTrue))
+	require.NoError(t, err)
+	ready, msg := systemWorkloadsReady(k8s)
+	require.False(t, ready)
+	require.Equal(t, "0 of 1 workloads running, waiting for deployment coredns", msg)
+
+	coreDNS.Status.ReadyReplicas = 2
+	_, err = k8s.AppsV1().Deployments(systemNamespace).Update(coreDNS)
+	require.NoError(t, err)
+
+	events = nil
+	require.NoError(t, Wait(context.Background(), k8s, "my-cluster", time.Second, func(e types.ReadinessEvent) { events = append(events, e) }))
+	require.Len(t, events, 3)
+	for _, e := range events {
+		require.True(t, e.Ready, "%s should be ready", e.Check)
+	}
+}
+
+func TestWaitCanceled(t *testing.T) {
+	defer func(i time.Duration) { pollInterval = i }(pollInterval)
+	pollInterval = 10 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := Wait(ctx, fake.NewSimpleClientset(), "my-cluster", time.Minute, nil)
+	require.Error(t, err)
+	require.Nil(t, types.AsTimeout(err), "A canceled wait should not be reported as timeout")
+}
+
+func node(name string, ready corev1.ConditionStatus) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}},
+		},
+	}
+}
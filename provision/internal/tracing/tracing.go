@@ -0,0 +1,92 @@
+// Package tracing creates the OpenTelemetry spans of the Hydroform operations.
+package tracing
+
+import (
+	"context"
+
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/kyma-incubator/hydroform/provision"
+
+// Attribute keys of the Hydroform spans.
+const (
+	ProviderKey = attribute.Key("hydroform.provider")
+	ProjectKey  = attribute.Key("hydroform.project")
+	ClusterKey  = attribute.Key("hydroform.cluster")
+	OutcomeKey  = attribute.Key("hydroform.outcome")
+)
+
+// Tracer starts spans as children of the span in its context. A nil Tracer does not trace anything.
+type Tracer struct {
+	ctx    context.Context
+	tracer trace.Tracer
+}
+
+// New creates a Tracer from the tracer provider and the context in the options.
+// If no tracer provider is set, it returns nil, so that nothing is traced.
+func New(ops *types.Options) *Tracer {
+	if ops.TracerProvider == nil {
+		return nil
+	}
+
+	ctx := ops.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &Tracer{
+		ctx:    ctx,
+		tracer: ops.TracerProvider.Tracer(instrumentationName),
+	}
+}
+
+// Start starts a span with the given name and attributes. It returns a Tracer for the children of the span.
+func (t *Tracer) Start(name string, attrs ...attribute.KeyValue) (*Tracer, trace.Span) {
+	if t == nil {
+		// the span of an empty context does not record anything
+		return nil, trace.SpanFromContext(context.Background())
+	}
+
+	ctx, span := t.tracer.Start(t.ctx, name, trace.WithAttributes(attrs...))
+	return &Tracer{ctx: ctx, tracer: t.tracer}, span
+}
+
+// Context returns the context carrying the span of the Tracer.
+func (t *Tracer) Context() context.Context {
+	if t == nil {
+		return context.Background()
+	}
+	return t.ctx
+}
+
+// Trace runs f in a span with the given name and records its outcome.
+func (t *Tracer) Trace(name string, f func() error) error {
+	_, span := t.Start(name)
+	err := f()
+	End(span, err)
+	return err
+}
+
+// End records the outcome of the span and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.SetAttributes(OutcomeKey.String("failure"))
+	} else {
+		span.SetAttributes(OutcomeKey.String("success"))
+	}
+	span.End()
+}
+
+// Cluster returns the attributes identifying a cluster.
+func Cluster(p types.ProviderType, project, cluster string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		ProviderKey.String(string(p)),
+		ProjectKey.String(project),
+		ClusterKey.String(cluster),
+	}
+}
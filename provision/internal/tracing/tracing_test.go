@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	// continue a trace of the caller
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "caller")
+	tracer := New(&types.Options{TracerProvider: tp, Context: ctx})
+	require.NotNil(t, tracer)
+
+	child, span := tracer.Start("provision", Cluster(types.GCP, "my-project", "my-cluster")...)
+	require.NoError(t, child.Trace("validate", func() error { return nil }))
+	require.Error(t, child.Trace("apply", func() error { return errors.New("apply failed") }))
+	End(span, nil)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 4)
+	validate, apply, provision := spans[0], spans[1], spans[2]
+
+	require.Equal(t, "provision", provision.Name())
+	require.Equal(t, parent.SpanContext().SpanID(), provision.Parent().SpanID(), "The operation should continue the trace of the context")
+	require.Subset(t, provision.Attributes(), []attribute.KeyValue{
+		ProviderKey.String("gcp"),
+		ProjectKey.String("my-project"),
+		ClusterKey.String("my-cluster"),
+		OutcomeKey.String("success"),
+	})
+
+	require.Equal(t, "validate", validate.Name())
+	require.Equal(t, provision.SpanContext().SpanID(), validate.Parent().SpanID(), "Phases should be children of the operation")
+	require.Contains(t, validate.Attributes(), OutcomeKey.String("success"))
+
+	require.Equal(t, "apply", apply.Name())
+	require.Contains(t, apply.Attributes(), OutcomeKey.String("failure"))
+	require.Equal(t, codes.Error, apply.Status().Code)
+	require.Equal(t, "apply failed", apply.Status().Description)
+}
+
+func TestTracerDisabled(t *testing.T) {
+	tracer := New(&types.Options{})
+	require.Nil(t, tracer, "Without a tracer provider nothing should be traced")
+
+	child, span := tracer.Start("provision")
+	require.Nil(t, child)
+	require.False(t, span.IsRecording())
+	End(span, errors.New("failed"))
+
+	require.NoError(t, tracer.Trace("validate", func() error { return nil }))
+	require.NotNil(t, tracer.Context())
+}
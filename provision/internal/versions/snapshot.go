@@ -0,0 +1,45 @@
+package versions
+
+import (
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/pkg/errors"
+)
+
+// snapshot contains the Kubernetes versions the providers offered when this version of Hydroform was released.
+// Update it together with the terraform providers. Versions differ slightly between locations, the snapshot lists the ones offered in all of them.
+var snapshot = map[types.ProviderType]types.VersionCatalog{
+	types.GCP: {
+		Versions: []string{"1.13.12-gke.30", "1.14.10-gke.17", "1.15.9-gke.8", "1.16.5-gke.2"},
+		Default:  "1.14.10-gke.17",
+	},
+	types.Azure: {
+		Versions: []string{"1.13.12", "1.14.8", "1.15.7", "1.16.4"},
+		Default:  "1.15.7",
+	},
+	// the versions of a Gardener landscape depend on its cloud profiles, these are the ones of the public landscapes
+	types.Gardener: {
+		Versions: []string{"1.15.10", "1.16.7", "1.17.3", "1.17.4"},
+		Default:  "1.16.7",
+	},
+	// the versions kind node images are published for
+	types.Kind: {
+		Versions: []string{"1.11.10", "1.12.10", "1.13.12", "1.14.10", "1.15.7", "1.16.4", "1.17.0"},
+		Default:  "1.17.0",
+	},
+}
+
+type snapshotSource struct{}
+
+// Snapshot returns the source of the Kubernetes versions shipped with Hydroform. It works offline and ignores the location.
+func Snapshot() types.VersionSource {
+	return snapshotSource{}
+}
+
+func (snapshotSource) Versions(p types.ProviderType, location string) (*types.VersionCatalog, error) {
+	c, ok := snapshot[p]
+	if !ok {
+		return nil, errors.Errorf("provider %s is not supported", p)
+	}
+	// hand out a copy so callers cannot change the snapshot
+	return &types.VersionCatalog{Versions: append([]string(nil), c.Versions...), Default: c.Default}, nil
+}
@@ -0,0 +1,140 @@
+package versions
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/pkg/errors"
+)
+
+// versionRegexp splits a version into its major, minor and patch numbers and the provider specific suffix, for example -gke.8.
+var versionRegexp = regexp.MustCompile(`^v?(\d+)\.(\d+)(?:\.(\d+))?(.*)$`)
+
+// Resolve returns the exact version the provider offers for the requested one.
+// The requested version can be an exact version, a prefix of one such as 1.16 or 1.15.9, "latest" or "default".
+// Prefixes resolve to the newest matching version. Versions the catalog does not know are passed to the provider as they are,
+// so that versions released after the catalog was taken can be used. If source is nil, the snapshot is used.
+func Resolve(source types.VersionSource, p types.ProviderType, location, requested string) (string, error) {
+	if source == nil {
+		source = Snapshot()
+	}
+	where := string(p)
+	if location != "" {
+		where += " in " + location
+	}
+
+	alias := requested == types.LatestVersion || requested == types.DefaultVersion
+	if !alias && !versionRegexp.MatchString(requested) {
+		return "", errors.Errorf("Kubernetes version %s is neither a version nor %s or %s", requested, types.LatestVersion, types.DefaultVersion)
+	}
+
+	catalog, err := source.Versions(p, location)
+	if err != nil {
+		return "", errors.Wrapf(err, "could not get the Kubernetes versions of %s", where)
+	}
+	if catalog == nil || len(catalog.Versions) == 0 {
+		if alias {
+			return "", errors.Errorf("no Kubernetes versions known for %s", where)
+		}
+		return strings.TrimPrefix(requested, "v"), nil
+	}
+
+	switch requested {
+	case types.LatestVersion:
+		return latest(catalog.Versions), nil
+	case types.DefaultVersion:
+		if catalog.Default != "" {
+			return catalog.Default, nil
+		}
+		return latest(catalog.Versions), nil
+	}
+
+	requested = strings.TrimPrefix(requested, "v")
+	var matches []string
+	for _, v := range catalog.Versions {
+		if v == requested {
+			return v, nil
+		}
+		if strings.HasPrefix(v, requested+".") || strings.HasPrefix(v, requested+"-") {
+			matches = append(matches, v)
+		}
+	}
+	if len(matches) == 0 {
+		// the provider rejects the version if it does not offer it
+		return requested, nil
+	}
+	return latest(matches), nil
+}
+
+// ForCluster returns the exact Kubernetes version of the cluster.
+// Versions of clusters that were provisioned already are taken from the cluster info as they are, the catalog may not offer them anymore.
+func ForCluster(source types.VersionSource, p types.ProviderType, cluster *types.Cluster) (string, error) {
+	if cluster.ClusterInfo != nil {
+		if cluster.ClusterInfo.KubernetesVersion != "" {
+			return cluster.ClusterInfo.KubernetesVersion, nil
+		}
+		return cluster.KubernetesVersion, nil
+	}
+	return Resolve(source, p, cluster.Location, cluster.KubernetesVersion)
+}
+
+// Unresolved returns a copy of the cluster for which ForCluster returns the running or the requested version without resolving it.
+// Deprovisioning uses it, so that deleting a cluster neither depends on the version source nor on the versions it still offers.
+func Unresolved(cluster *types.Cluster) *types.Cluster {
+	c := *cluster
+	info := types.ClusterInfo{}
+	if cluster.ClusterInfo != nil {
+		info = *cluster.ClusterInfo
+	}
+	c.ClusterInfo = &info
+	return &c
+}
+
+// latest returns the newest of the given versions.
+func latest(versions []string) string {
+	sorted := append([]string(nil), versions...)
+	sort.Slice(sorted, func(i, j int) bool { return Compare(sorted[i], sorted[j]) < 0 })
+	return sorted[len(sorted)-1]
+}
+
+// Compare returns -1 if version a is older than b, 1 if it is newer and 0 if they are the same.
+// Provider specific suffixes are compared by their trailing number, so 1.15.9-gke.10 is newer than 1.15.9-gke.8.
+func Compare(a, b string) int {
+	pa, pb := versionRegexp.FindStringSubmatch(a), versionRegexp.FindStringSubmatch(b)
+	if pa == nil || pb == nil {
+		return strings.Compare(a, b)
+	}
+	for i := 1; i <= 3; i++ {
+		if c := compareInts(number(pa[i]), number(pb[i])); c != 0 {
+			return c
+		}
+	}
+	if c := compareInts(number(trailingNumber(pa[4])), number(trailingNumber(pb[4]))); c != 0 {
+		return c
+	}
+	return strings.Compare(pa[4], pb[4])
+}
+
+var trailingNumberRegexp = regexp.MustCompile(`(\d+)$`)
+
+func trailingNumber(s string) string {
+	return trailingNumberRegexp.FindString(s)
+}
+
+func number(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
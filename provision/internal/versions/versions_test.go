@@ -0,0 +1,125 @@
+package versions
+
+import (
+	"testing"
+
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSource map[string]*types.VersionCatalog
+
+func (f fakeSource) Versions(p types.ProviderType, location string) (*types.VersionCatalog, error) {
+	c, ok := f[location]
+	if !ok {
+		return nil, errors.Errorf("location %s not found", location)
+	}
+	return c, nil
+}
+
+func TestResolve(t *testing.T) {
+	source := fakeSource{
+		"europe-west3": {
+			Versions: []string{"1.14.10-gke.17", "1.15.9-gke.8", "1.15.9-gke.10", "1.15.12-gke.2", "1.16.5-gke.2"},
+			Default:  "1.15.9-gke.10",
+		},
+		"us-east1": {
+			Versions: []string{"1.14.10-gke.17", "1.15.9-gke.8"},
+		},
+		"empty": {},
+	}
+
+	cases := []struct {
+		location  string
+		requested string
+		expected  string
+	}{
+		{"europe-west3", "latest", "1.16.5-gke.2"},
+		{"europe-west3", "default", "1.15.9-gke.10"},
+		{"us-east1", "default", "1.15.9-gke.8"},
+		{"europe-west3", "1.15", "1.15.12-gke.2"},
+		{"europe-west3", "1.15.9", "1.15.9-gke.10"},
+		{"europe-west3", "v1.14", "1.14.10-gke.17"},
+		{"europe-west3", "1.15.9-gke.8", "1.15.9-gke.8"},
+		{"us-east1", "1.15", "1.15.9-gke.8"},
+	}
+	for _, c := range cases {
+		v, err := Resolve(source, types.GCP, c.location, c.requested)
+		require.NoError(t, err, "%s should resolve in %s", c.requested, c.location)
+		require.Equal(t, c.expected, v, "%s should resolve in %s", c.requested, c.location)
+	}
+
+	v, err := Resolve(source, types.GCP, "us-east1", "1.16.8-gke.3")
+	require.NoError(t, err, "Versions not in the catalog should be passed to the provider")
+	require.Equal(t, "1.16.8-gke.3", v)
+	v, err = Resolve(source, types.GCP, "europe-west3", "1.1")
+	require.NoError(t, err)
+	require.Equal(t, "1.1", v, "A prefix should only match whole version numbers")
+	v, err = Resolve(source, types.GCP, "empty", "v1.17")
+	require.NoError(t, err)
+	require.Equal(t, "1.17", v, "Versions should be passed to the provider if the catalog is empty")
+
+	_, err = Resolve(source, types.GCP, "europe-west3", "stable")
+	require.Error(t, err, "Requests which are neither versions nor aliases should fail")
+	_, err = Resolve(source, types.GCP, "empty", "latest")
+	require.Error(t, err, "Aliases should fail with an empty catalog")
+	_, err = Resolve(source, types.GCP, "mars-north1", "latest")
+	require.Error(t, err, "Errors of the source should be returned")
+}
+
+func TestResolveSnapshot(t *testing.T) {
+	for p := range snapshot {
+		v, err := Resolve(nil, p, "", "default")
+		require.NoError(t, err, "The snapshot should have versions for %s", p)
+		require.NotEmpty(t, v)
+	}
+
+	v, err := Resolve(nil, types.Azure, "westeurope", "1.16")
+	require.NoError(t, err)
+	require.Equal(t, "1.16.4", v)
+
+	_, err = Resolve(nil, types.AWS, "", "latest")
+	require.Error(t, err, "Unsupported providers should fail")
+}
+
+func TestForCluster(t *testing.T) {
+	cluster := &types.Cluster{KubernetesVersion: "1.15", Location: "westeurope"}
+
+	v, err := ForCluster(nil, types.Azure, cluster)
+	require.NoError(t, err)
+	require.Equal(t, "1.15.7", v, "The version of new clusters should be resolved")
+
+	cluster.KubernetesVersion = "1.12"
+	cluster.ClusterInfo = &types.ClusterInfo{}
+	v, err = ForCluster(nil, types.Azure, cluster)
+	require.NoError(t, err, "Versions of provisioned clusters should not be validated again")
+	require.Equal(t, "1.12", v)
+
+	cluster.ClusterInfo.KubernetesVersion = "1.12.8"
+	v, err = ForCluster(nil, types.Azure, cluster)
+	require.NoError(t, err)
+	require.Equal(t, "1.12.8", v, "The running version should be used for provisioned clusters")
+}
+
+func TestUnresolved(t *testing.T) {
+	cluster := &types.Cluster{KubernetesVersion: "latest", Location: "westeurope"}
+
+	v, err := ForCluster(fakeSource{}, types.Azure, Unresolved(cluster))
+	require.NoError(t, err, "The version source should not be asked")
+	require.Equal(t, "latest", v)
+	require.Nil(t, cluster.ClusterInfo, "The cluster should not be changed")
+
+	cluster.ClusterInfo = &types.ClusterInfo{KubernetesVersion: "1.16.4"}
+	v, err = ForCluster(fakeSource{}, types.Azure, Unresolved(cluster))
+	require.NoError(t, err)
+	require.Equal(t, "1.16.4", v, "The running version should be used")
+}
+
+func TestCompare(t *testing.T) {
+	require.Equal(t, -1, Compare("1.9.0", "1.10.0"))
+	require.Equal(t, 1, Compare("1.15.12", "1.15.9"))
+	require.Equal(t, 1, Compare("1.15.9-gke.10", "1.15.9-gke.8"))
+	require.Equal(t, 0, Compare("v1.16.4", "v1.16.4"))
+	require.Equal(t, -1, Compare("1.16", "1.16.1"))
+}
@@ -0,0 +1,53 @@
+package provision
+
+import (
+	"strings"
+
+	"github.com/kyma-incubator/hydroform/provision/kubeconfig"
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/pkg/errors"
+)
+
+// mergeKubeconfig merges the credentials of the provisioned cluster into the kubeconfig file, if enabled with the options.
+// Existing clusters are skipped, their credentials come from the kubeconfig file of the user already.
+func mergeKubeconfig(cluster *types.Cluster, provider *types.Provider, prov Provisioner, ops ...types.Option) error {
+	os := &types.Options{}
+	for _, o := range ops {
+		o(os)
+	}
+	if os.Kubeconfig == nil || provider.Type == types.Existing {
+		return nil
+	}
+
+	data, err := prov.Credentials(cluster, provider)
+	if err != nil {
+		return errors.Wrap(err, "could not get the kubeconfig to merge it")
+	}
+	return kubeconfig.Merge(os.Kubeconfig.Path, kubeconfigContext(cluster, provider, os.Kubeconfig), data)
+}
+
+// removeKubeconfig removes the credentials of the deprovisioned cluster from the kubeconfig file, if enabled with the options.
+// Existing clusters are skipped, since Hydroform did not add their credentials and must not remove the context of the user.
+func removeKubeconfig(cluster *types.Cluster, provider *types.Provider, ops ...types.Option) error {
+	os := &types.Options{}
+	for _, o := range ops {
+		o(os)
+	}
+	if os.Kubeconfig == nil || provider.Type == types.Existing {
+		return nil
+	}
+	return kubeconfig.Remove(os.Kubeconfig.Path, kubeconfigContext(cluster, provider, os.Kubeconfig))
+}
+
+// kubeconfigContext returns the name of the context of the cluster in the kubeconfig file.
+// By default the name is qualified with the provider and project, since clusters of different projects can have the same name and merging replaces entries with the same name.
+func kubeconfigContext(cluster *types.Cluster, provider *types.Provider, target *types.KubeconfigTarget) string {
+	if target.Context != "" {
+		return target.Context
+	}
+	parts := []string{string(provider.Type)}
+	if provider.ProjectName != "" {
+		parts = append(parts, provider.ProjectName)
+	}
+	return strings.Join(append(parts, cluster.Name), "-")
+}
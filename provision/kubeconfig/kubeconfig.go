@@ -0,0 +1,150 @@
+package kubeconfig
+
+import (
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// mu serializes the changes to kubeconfig files, for example when the clusters of a fleet are merged into the same file.
+var mu sync.Mutex
+
+// DefaultPath returns the default kubeconfig file in the home directory, which is used if a path is empty.
+func DefaultPath() string {
+	return clientcmd.RecommendedHomeFile
+}
+
+// RESTConfig creates the client configuration for the current context of the given kubeconfig.
+func RESTConfig(kubeconfig []byte) (*rest.Config, error) {
+	config, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create the client configuration from the kubeconfig")
+	}
+	return config, nil
+}
+
+// Merge adds the current context of the given kubeconfig to the kubeconfig file at the given path, which is created if it does not exist.
+// The context, its cluster and its user are all named after the given context name, replacing any entries with the same name.
+// The current context of the file is only set if it has none yet, use UseContext to switch to the merged context.
+func Merge(path, context string, kubeconfig []byte) error {
+	if context == "" {
+		return errors.New("the context name must not be empty")
+	}
+	merged, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return errors.Wrap(err, "could not load the kubeconfig to merge")
+	}
+
+	current := merged.CurrentContext
+	if current == "" && len(merged.Contexts) == 1 {
+		for name := range merged.Contexts {
+			current = name
+		}
+	}
+	ctx, ok := merged.Contexts[current]
+	if !ok {
+		return errors.New("the kubeconfig to merge has no current context")
+	}
+	cluster, ok := merged.Clusters[ctx.Cluster]
+	if !ok {
+		return errors.Errorf("the kubeconfig to merge has no cluster %s", ctx.Cluster)
+	}
+	user, ok := merged.AuthInfos[ctx.AuthInfo]
+	if !ok {
+		return errors.Errorf("the kubeconfig to merge has no user %s", ctx.AuthInfo)
+	}
+
+	return modify(path, func(config *api.Config) {
+		config.Clusters[context] = cluster
+		config.AuthInfos[context] = user
+		config.Contexts[context] = &api.Context{
+			Cluster:   context,
+			AuthInfo:  context,
+			Namespace: ctx.Namespace,
+		}
+		if config.CurrentContext == "" {
+			config.CurrentContext = context
+		}
+	})
+}
+
+// Remove deletes the given context from the kubeconfig file at the given path, together with its cluster and user unless other contexts use them.
+// If it was the current context, the file has no current context afterwards. Nothing happens if the file or the context does not exist.
+func Remove(path, context string) error {
+	path = pathOrDefault(path)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+
+	return modify(path, func(config *api.Config) {
+		ctx, ok := config.Contexts[context]
+		if !ok {
+			return
+		}
+		delete(config.Contexts, context)
+
+		clusterUsed, userUsed := false, false
+		for _, c := range config.Contexts {
+			clusterUsed = clusterUsed || c.Cluster == ctx.Cluster
+			userUsed = userUsed || c.AuthInfo == ctx.AuthInfo
+		}
+		if !clusterUsed {
+			delete(config.Clusters, ctx.Cluster)
+		}
+		if !userUsed {
+			delete(config.AuthInfos, ctx.AuthInfo)
+		}
+		if config.CurrentContext == context {
+			config.CurrentContext = ""
+		}
+	})
+}
+
+// UseContext makes the given context the current one in the kubeconfig file at the given path.
+func UseContext(path, context string) error {
+	var found bool
+	err := modify(path, func(config *api.Config) {
+		if _, found = config.Contexts[context]; found {
+			config.CurrentContext = context
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errors.Errorf("context %s not found in %s", context, pathOrDefault(path))
+	}
+	return nil
+}
+
+// modify loads the kubeconfig file at the given path, or an empty one if it does not exist, changes it with the given function and writes it back.
+func modify(path string, change func(*api.Config)) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	path = pathOrDefault(path)
+	config := api.NewConfig()
+	if _, err := os.Stat(path); err == nil {
+		if config, err = clientcmd.LoadFromFile(path); err != nil {
+			return errors.Wrapf(err, "could not load the kubeconfig file %s", path)
+		}
+	}
+
+	change(config)
+
+	if err := clientcmd.WriteToFile(*config, path); err != nil {
+		return errors.Wrapf(err, "could not write the kubeconfig file %s", path)
+	}
+	return nil
+}
+
+func pathOrDefault(path string) string {
+	if path == "" {
+		return DefaultPath()
+	}
+	return path
+}
@@ -0,0 +1,81 @@
+package kubeconfig
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestMergeAndRemove(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubeconfig")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, ".kube", "config")
+
+	require.NoError(t, Remove(path, "dev"), "Removing from a missing file should do nothing")
+
+	require.NoError(t, Merge(path, "dev", testKubeconfig(t, "https://1.2.3.4")))
+	require.NoError(t, Merge(path, "prod", testKubeconfig(t, "https://5.6.7.8")))
+
+	config, err := clientcmd.LoadFromFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "dev", config.CurrentContext, "The first merged context should become the current one")
+	require.Len(t, config.Contexts, 2)
+	require.Equal(t, "prod", config.Contexts["prod"].Cluster)
+	require.Equal(t, "prod", config.Contexts["prod"].AuthInfo)
+	require.Equal(t, "https://5.6.7.8", config.Clusters["prod"].Server)
+	require.Equal(t, "secret", config.AuthInfos["prod"].Token)
+
+	require.NoError(t, UseContext(path, "prod"))
+	require.Error(t, UseContext(path, "unknown"), "Using an unknown context should fail")
+
+	// merging again replaces the entries
+	require.NoError(t, Merge(path, "prod", testKubeconfig(t, "https://9.9.9.9")))
+	config, err = clientcmd.LoadFromFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "prod", config.CurrentContext)
+	require.Equal(t, "https://9.9.9.9", config.Clusters["prod"].Server)
+
+	require.NoError(t, Remove(path, "prod"))
+	config, err = clientcmd.LoadFromFile(path)
+	require.NoError(t, err)
+	require.Empty(t, config.CurrentContext, "Removing the current context should unset it")
+	require.Len(t, config.Contexts, 1)
+	require.NotContains(t, config.Clusters, "prod")
+	require.NotContains(t, config.AuthInfos, "prod")
+	require.Contains(t, config.Clusters, "dev", "Other contexts should be kept")
+}
+
+func TestMergeInvalid(t *testing.T) {
+	require.Error(t, Merge("config", "", testKubeconfig(t, "https://1.2.3.4")), "Merging without a context name should fail")
+	require.Error(t, Merge("config", "dev", []byte("not: [a kubeconfig")), "Merging an invalid kubeconfig should fail")
+	require.Error(t, Merge("config", "dev", []byte("apiVersion: v1\nkind: Config\n")), "Merging a kubeconfig without context should fail")
+}
+
+func TestRESTConfig(t *testing.T) {
+	config, err := RESTConfig(testKubeconfig(t, "https://1.2.3.4"))
+	require.NoError(t, err)
+	require.Equal(t, "https://1.2.3.4", config.Host)
+	require.Equal(t, "secret", config.BearerToken)
+
+	_, err = RESTConfig([]byte("apiVersion: v1\nkind: Config\n"))
+	require.Error(t, err)
+}
+
+// testKubeconfig returns a kubeconfig like the ones returned by Credentials.
+func testKubeconfig(t *testing.T, server string) []byte {
+	config := api.NewConfig()
+	config.Clusters["my-cluster"] = &api.Cluster{Server: server}
+	config.AuthInfos["cluster-user"] = &api.AuthInfo{Token: "secret"}
+	config.Contexts["my-cluster"] = &api.Context{Cluster: "my-cluster", AuthInfo: "cluster-user"}
+	config.CurrentContext = "my-cluster"
+
+	data, err := clientcmd.Write(*config)
+	require.NoError(t, err)
+	return data
+}
@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	defaultNamespace = "hydroform"
+
+	outcomeSuccess = "success"
+	outcomeFailure = "failure"
+)
+
+// durationBuckets cover operations from a second up to a bit more than two hours.
+var durationBuckets = prometheus.ExponentialBuckets(1, 2, 14)
+
+// Prometheus records Hydroform operations as Prometheus metrics. Pass it to the operations with types.WithMetrics, and register it with a Prometheus registry to expose the metrics.
+type Prometheus struct {
+	operations        *prometheus.CounterVec
+	operationDuration *prometheus.HistogramVec
+	phaseDuration     *prometheus.HistogramVec
+	retries           *prometheus.CounterVec
+}
+
+// NewPrometheus creates a Prometheus collector whose metrics are prefixed with the given namespace, or with hydroform if it is empty.
+func NewPrometheus(namespace string) *Prometheus {
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+
+	return &Prometheus{
+		operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "operations_total",
+			Help:      "Number of finished operations by outcome and error class.",
+		}, []string{"operation", "provider", "outcome", "error_class"}),
+		operationDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "operation_duration_seconds",
+			Help:      "Duration of the operations.",
+			Buckets:   durationBuckets,
+		}, []string{"operation", "provider", "outcome"}),
+		phaseDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "phase_duration_seconds",
+			Help:      "Duration of the phases of the operations, such as init, apply, import or destroy.",
+			Buckets:   durationBuckets,
+		}, []string{"phase", "provider", "outcome"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "retries_total",
+			Help:      "Number of retried phases by reason.",
+		}, []string{"phase", "provider", "reason"}),
+	}
+}
+
+// ObserveOperation records a finished operation.
+func (m *Prometheus) ObserveOperation(operation string, p types.ProviderType, d time.Duration, err error) {
+	m.operations.WithLabelValues(operation, string(p), outcome(err), types.ErrorClass(err)).Inc()
+	m.operationDuration.WithLabelValues(operation, string(p), outcome(err)).Observe(d.Seconds())
+}
+
+// ObservePhase records a finished phase of an operation.
+func (m *Prometheus) ObservePhase(phase string, p types.ProviderType, d time.Duration, err error) {
+	m.phaseDuration.WithLabelValues(phase, string(p), outcome(err)).Observe(d.Seconds())
+}
+
+// ObserveRetry records a retried phase of an operation.
+func (m *Prometheus) ObserveRetry(phase string, p types.ProviderType, reason string) {
+	m.retries.WithLabelValues(phase, string(p), reason).Inc()
+}
+
+// Describe implements prometheus.Collector.
+func (m *Prometheus) Describe(ch chan<- *prometheus.Desc) {
+	m.operations.Describe(ch)
+	m.operationDuration.Describe(ch)
+	m.phaseDuration.Describe(ch)
+	m.retries.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (m *Prometheus) Collect(ch chan<- prometheus.Metric) {
+	m.operations.Collect(ch)
+	m.operationDuration.Collect(ch)
+	m.phaseDuration.Collect(ch)
+	m.retries.Collect(ch)
+}
+
+func outcome(err error) string {
+	if err != nil {
+		return outcomeFailure
+	}
+	return outcomeSuccess
+}
@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheus(t *testing.T) {
+	m := NewPrometheus("")
+	var _ types.Metrics = m
+
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(m))
+
+	m.ObserveOperation("provision", types.GCP, 10*time.Minute, nil)
+	m.ObserveOperation("provision", types.GCP, time.Minute, &types.TimeoutError{Phase: "apply", Timeout: time.Minute})
+	m.ObserveOperation("provision", types.Azure, time.Second, types.Classify(types.ErrorClassValidation, errors.New("input validation failed with the following information: \n - Cluster.Location cannot be empty")))
+	m.ObservePhase("apply", types.GCP, 5*time.Minute, nil)
+	m.ObserveRetry("apply", types.GCP, "already exists")
+	m.ObserveRetry("apply", types.GCP, "already exists")
+
+	expected := `
+# HELP hydroform_operations_total Number of finished operations by outcome and error class.
+# TYPE hydroform_operations_total counter
+hydroform_operations_total{error_class="none",operation="provision",outcome="success",provider="gcp"} 1
+hydroform_operations_total{error_class="timeout",operation="provision",outcome="failure",provider="gcp"} 1
+hydroform_operations_total{error_class="validation",operation="provision",outcome="failure",provider="azure"} 1
+# HELP hydroform_retries_total Number of retried phases by reason.
+# TYPE hydroform_retries_total counter
+hydroform_retries_total{phase="apply",provider="gcp",reason="already exists"} 2
+`
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "hydroform_operations_total", "hydroform_retries_total"))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	durations := map[string]uint64{}
+	for _, f := range families {
+		for _, metric := range f.GetMetric() {
+			if h := metric.GetHistogram(); h != nil {
+				durations[f.GetName()] += h.GetSampleCount()
+			}
+		}
+	}
+	require.Equal(t, map[string]uint64{
+		"hydroform_operation_duration_seconds": 3,
+		"hydroform_phase_duration_seconds":     1,
+	}, durations, "Every operation and phase should be observed")
+}
+
+func TestErrorClass(t *testing.T) {
+	for err, class := range map[error]string{
+		nil: types.ErrorClassNone,
+		errors.Wrap(&types.TimeoutError{}, "unable to provision gcp cluster"):                                  types.ErrorClassTimeout,
+		errors.Wrap(types.Classify(types.ErrorClassQuota, errors.New("CPUS exceeded")), "unable to provision"): types.ErrorClassQuota,
+		types.Classify(types.ErrorClassValidation, errors.New("input validation failed")):                      types.ErrorClassValidation,
+		types.Classify(types.ErrorClassNotFound, &types.TimeoutError{}):                                        types.ErrorClassNotFound,
+		errors.New("the credentials file was not found"):                                                       types.ErrorClassOther,
+	} {
+		require.Equal(t, class, types.ErrorClass(err), "%v", err)
+	}
+}
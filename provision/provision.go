@@ -2,18 +2,22 @@ package provision
 
 import (
 	"errors"
+	"fmt"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/kyma-incubator/hydroform/provision/action"
 
 	"github.com/kyma-incubator/hydroform/provision/internal/azure"
+	"github.com/kyma-incubator/hydroform/provision/internal/existing"
 	"github.com/kyma-incubator/hydroform/provision/internal/gardener"
 	"github.com/kyma-incubator/hydroform/provision/internal/kind"
 
 	"github.com/kyma-incubator/hydroform/provision/internal/gcp"
 	"github.com/kyma-incubator/hydroform/provision/internal/operator"
+	terraform_operator "github.com/kyma-incubator/hydroform/provision/internal/operator/terraform"
 	"github.com/kyma-incubator/hydroform/provision/types"
 )
 
@@ -27,44 +31,110 @@ type Provisioner interface {
 	Deprovision(cluster *types.Cluster, provider *types.Provider) error
 }
 
+// Validator is the Hydroform interface for checking against the provider that a cluster can be provisioned, before provisioning it.
+type Validator interface {
+	Validate(cluster *types.Cluster, provider *types.Provider) error
+}
+
+// Importer is the Hydroform interface for bringing existing clusters under management.
+type Importer interface {
+	Import(cluster *types.Cluster, provider *types.Provider, id string) (*types.Cluster, []types.Difference, error)
+}
+
+// Ejector is the Hydroform interface for handing clusters over to plain terraform.
+type Ejector interface {
+	Eject(cluster *types.Cluster, provider *types.Provider, dir string) error
+}
+
+// Rollbacker is the Hydroform interface for restoring clusters to a previous version of their state.
+type Rollbacker interface {
+	Rollback(cluster *types.Cluster, provider *types.Provider, serial uint64) (*types.Cluster, error)
+}
+
+// Hibernator is the Hydroform interface for providers that can hibernate clusters to save costs while they are not used.
+type Hibernator interface {
+	Hibernate(cluster *types.Cluster, provider *types.Provider) error
+	WakeUp(cluster *types.Cluster, provider *types.Provider) error
+}
+
 // Provision creates a new cluster for a given provider based on specific cluster and provider parameters. It returns a cluster object enriched with information from the provider, such as the IP address or the connection endpoint. This object is necessary for the other operations, such as retrieving the cluster status or deprovisioning the cluster. If the cluster cannot be created, the function returns an error.
 func Provision(cluster *types.Cluster, provider *types.Provider, ops ...types.Option) (*types.Cluster, error) {
-	var err error
-	var cl *types.Cluster
+	if err := action.Before(); err != nil {
+		return nil, err
+	}
 
-	if err = action.Before(); err != nil {
+	cl, err := provision(cluster, provider, ops...)
+	if err != nil {
 		return cl, err
 	}
+	return cl, action.After()
+}
+
+// provision creates the cluster without running any actions, so that it can be used for single clusters and fleets alike.
+func provision(cluster *types.Cluster, provider *types.Provider, ops ...types.Option) (cl *types.Cluster, err error) {
+	ops, done := instrument("provision", cluster, provider, ops)
+	defer done(&err)
+
+	if cluster.TTL > 0 && cluster.ExpiresAt.IsZero() {
+		cluster.ExpiresAt = time.Now().Add(cluster.TTL).UTC().Truncate(time.Second)
+	}
+	// keep the provider specification as given by the user to record it
+	spec := *provider
 
 	if runtime.GOOS == "windows" {
 		provider.CredentialsFilePath = updateWindowsPath(provider.CredentialsFilePath)
 	}
 
-	switch provider.Type {
-	case types.GCP:
-		cl, err = newGCPProvisioner(provisioningOperator, ops...).Provision(cluster, provider)
-	case types.Gardener:
-		cl, err = newGardenerProvisioner(provisioningOperator, ops...).Provision(cluster, provider)
-	case types.AWS:
-		err = errors.New("aws not supported yet")
-	case types.Azure:
-		cl, err = newAzureProvisioner(provisioningOperator, ops...).Provision(cluster, provider)
-	case types.Kind:
-		cl, err = newKindProvisioner(provisioningOperator, ops...).Provision(cluster, provider)
-	default:
-		err = errors.New("unknown provider")
+	prov, err := newProvisioner(provider.Type, ops...)
+	if err == nil {
+		cl, err = prov.Provision(cluster, provider)
 	}
 
+	// record the cluster even if provisioning failed, so that left overs can be found and reaped.
+	// Clusters of unsupported providers or rejected before the operator created their directory, for example for invalid inputs, are not recorded.
+	if prov != nil && (err == nil || reachedOperator(cluster, &spec, ops...)) {
+		if recErr := record(cluster, &spec, ops...); recErr != nil && err == nil {
+			err = recErr
+		}
+	}
 	if err != nil {
 		return cl, err
 	}
-	return cl, action.After()
+	if err = mergeKubeconfig(cl, provider, prov, ops...); err != nil {
+		return cl, err
+	}
+	return cl, waitForReadiness(cl, provider, prov, ops...)
+}
+
+// WaitForReadiness waits until an existing cluster is usable, as Provision does with the WithReadiness option: the API server answers, all nodes are ready and the workloads in kube-system are running.
+// The timeout must be set with the WithReadiness option. If the cluster is not usable in time, a TimeoutError is returned.
+func WaitForReadiness(cluster *types.Cluster, provider *types.Provider, ops ...types.Option) (err error) {
+	ops, done := instrument("readiness", cluster, provider, ops)
+	defer done(&err)
+
+	os := &types.Options{}
+	for _, o := range ops {
+		o(os)
+	}
+	if os.ReadinessTimeout <= 0 {
+		return errors.New("no readiness timeout given, set it with the WithReadiness option")
+	}
+
+	if runtime.GOOS == "windows" {
+		provider.CredentialsFilePath = updateWindowsPath(provider.CredentialsFilePath)
+	}
+
+	prov, err := newProvisioner(provider.Type, ops...)
+	if err != nil {
+		return err
+	}
+	return waitForReadiness(cluster, provider, prov, ops...)
 }
 
 // Status returns the cluster status for a given provider, or an error if providing the status is not possible. The possible status values are defined in the ClusterStatus type.
-func Status(cluster *types.Cluster, provider *types.Provider, ops ...types.Option) (*types.ClusterStatus, error) {
-	var err error
-	var cs *types.ClusterStatus
+func Status(cluster *types.Cluster, provider *types.Provider, ops ...types.Option) (cs *types.ClusterStatus, err error) {
+	ops, done := instrument("status", cluster, provider, ops)
+	defer done(&err)
 
 	if err = action.Before(); err != nil {
 		return cs, err
@@ -85,6 +155,8 @@ func Status(cluster *types.Cluster, provider *types.Provider, ops ...types.Optio
 		cs, err = newAzureProvisioner(provisioningOperator, ops...).Status(cluster, provider)
 	case types.Kind:
 		cs, err = newKindProvisioner(provisioningOperator, ops...).Status(cluster, provider)
+	case types.Existing:
+		cs, err = newExistingProvisioner(ops...).Status(cluster, provider)
 	default:
 		err = errors.New("unknown provider")
 	}
@@ -96,9 +168,9 @@ func Status(cluster *types.Cluster, provider *types.Provider, ops ...types.Optio
 }
 
 // Credentials returns the kubeconfig for a specific cluster as a byte array.
-func Credentials(cluster *types.Cluster, provider *types.Provider, ops ...types.Option) ([]byte, error) {
-	var err error
-	var cr []byte
+func Credentials(cluster *types.Cluster, provider *types.Provider, ops ...types.Option) (cr []byte, err error) {
+	ops, done := instrument("credentials", cluster, provider, ops)
+	defer done(&err)
 
 	if err = action.Before(); err != nil {
 		return cr, err
@@ -119,6 +191,8 @@ func Credentials(cluster *types.Cluster, provider *types.Provider, ops ...types.
 		cr, err = newAzureProvisioner(provisioningOperator, ops...).Credentials(cluster, provider)
 	case types.Kind:
 		cr, err = newKindProvisioner(provisioningOperator, ops...).Credentials(cluster, provider)
+	case types.Existing:
+		cr, err = newExistingProvisioner(ops...).Credentials(cluster, provider)
 	default:
 		err = errors.New("unknown provider")
 	}
@@ -131,11 +205,20 @@ func Credentials(cluster *types.Cluster, provider *types.Provider, ops ...types.
 
 // Deprovision removes an existing cluster along or returns an error if removing the cluster is not possible.
 func Deprovision(cluster *types.Cluster, provider *types.Provider, ops ...types.Option) error {
-	var err error
+	if err := action.Before(); err != nil {
+		return err
+	}
 
-	if err = action.Before(); err != nil {
+	if err := deprovision(cluster, provider, ops...); err != nil {
 		return err
 	}
+	return action.After()
+}
+
+// deprovision removes the cluster without running any actions, so that it can be used for single clusters and fleets alike.
+func deprovision(cluster *types.Cluster, provider *types.Provider, ops ...types.Option) (err error) {
+	ops, done := instrument("deprovision", cluster, provider, ops)
+	defer done(&err)
 
 	if runtime.GOOS == "windows" {
 		provider.CredentialsFilePath = updateWindowsPath(provider.CredentialsFilePath)
@@ -152,6 +235,45 @@ func Deprovision(cluster *types.Cluster, provider *types.Provider, ops ...types.
 		err = newAzureProvisioner(provisioningOperator, ops...).Deprovision(cluster, provider)
 	case types.Kind:
 		err = newKindProvisioner(provisioningOperator, ops...).Deprovision(cluster, provider)
+	case types.Existing:
+		err = newExistingProvisioner(ops...).Deprovision(cluster, provider)
+	default:
+		err = errors.New("unknown provider")
+	}
+	if err != nil {
+		return err
+	}
+	return removeKubeconfig(cluster, provider, ops...)
+}
+
+// Validate checks that the cluster can be provisioned with the given provider, without creating anything.
+// Besides validating the inputs, it checks the credentials, that the project, resource group or garden namespace exists, that the account has the needed permissions, and that the location and machine type are available.
+// It returns an error listing all problems found.
+func Validate(cluster *types.Cluster, provider *types.Provider, ops ...types.Option) (err error) {
+	ops, done := instrument("validate", cluster, provider, ops)
+	defer done(&err)
+
+	if err = action.Before(); err != nil {
+		return err
+	}
+
+	if runtime.GOOS == "windows" {
+		provider.CredentialsFilePath = updateWindowsPath(provider.CredentialsFilePath)
+	}
+
+	switch provider.Type {
+	case types.GCP:
+		err = newGCPValidator(provisioningOperator, ops...).Validate(cluster, provider)
+	case types.Gardener:
+		err = newGardenerValidator(provisioningOperator, ops...).Validate(cluster, provider)
+	case types.AWS:
+		err = errors.New("aws not supported yet")
+	case types.Azure:
+		err = newAzureValidator(provisioningOperator, ops...).Validate(cluster, provider)
+	case types.Kind:
+		err = newKindValidator(provisioningOperator, ops...).Validate(cluster, provider)
+	case types.Existing:
+		err = newExistingValidator(ops...).Validate(cluster, provider)
 	default:
 		err = errors.New("unknown provider")
 	}
@@ -161,6 +283,275 @@ func Deprovision(cluster *types.Cluster, provider *types.Provider, ops ...types.
 	return action.After()
 }
 
+// Import brings an existing cluster, which was not created by Hydroform or whose state got lost, under management. Afterwards, the cluster can be managed as if it was created with Provision.
+// The id identifies the cluster at the provider, for example projects/my-project/locations/europe-west3/clusters/my-cluster on GCP. If it is empty, it is derived from the cluster and provider specification.
+// It returns the cluster enriched with information from the provider, and the settings in which the existing cluster differs from the specification. Import does not change the existing cluster.
+func Import(cluster *types.Cluster, provider *types.Provider, id string, ops ...types.Option) (cl *types.Cluster, diffs []types.Difference, err error) {
+	ops, done := instrument("import", cluster, provider, ops)
+	defer done(&err)
+
+	if err = action.Before(); err != nil {
+		return cl, diffs, err
+	}
+
+	// keep the provider specification as given by the user to record it
+	spec := *provider
+
+	if runtime.GOOS == "windows" {
+		provider.CredentialsFilePath = updateWindowsPath(provider.CredentialsFilePath)
+	}
+
+	switch provider.Type {
+	case types.GCP:
+		cl, diffs, err = newGCPImporter(provisioningOperator, ops...).Import(cluster, provider, id)
+	case types.Gardener:
+		cl, diffs, err = newGardenerImporter(provisioningOperator, ops...).Import(cluster, provider, id)
+	case types.AWS:
+		err = errors.New("aws not supported yet")
+	case types.Azure:
+		cl, diffs, err = newAzureImporter(provisioningOperator, ops...).Import(cluster, provider, id)
+	case types.Kind:
+		cl, diffs, err = newKindImporter(provisioningOperator, ops...).Import(cluster, provider, id)
+	case types.Existing:
+		err = errors.New("existing clusters are not managed by Hydroform and cannot be imported, use Provision to connect to them")
+	default:
+		err = errors.New("unknown provider")
+	}
+
+	if err != nil {
+		return cl, diffs, err
+	}
+	if err = record(cluster, &spec, ops...); err != nil {
+		return cl, diffs, err
+	}
+	return cl, diffs, action.After()
+}
+
+// Eject writes a standalone terraform project for an existing cluster into the given directory, which must be empty or not exist yet.
+// The project contains the terraform files, a vars file without secrets, the current state in plain text, and a README listing all variables. Afterwards, the cluster can be managed with plain terraform instead of Hydroform.
+func Eject(cluster *types.Cluster, provider *types.Provider, dir string, ops ...types.Option) (err error) {
+	ops, done := instrument("eject", cluster, provider, ops)
+	defer done(&err)
+
+	if err = action.Before(); err != nil {
+		return err
+	}
+
+	if runtime.GOOS == "windows" {
+		provider.CredentialsFilePath = updateWindowsPath(provider.CredentialsFilePath)
+	}
+
+	switch provider.Type {
+	case types.GCP:
+		err = newGCPEjector(provisioningOperator, ops...).Eject(cluster, provider, dir)
+	case types.Gardener:
+		err = newGardenerEjector(provisioningOperator, ops...).Eject(cluster, provider, dir)
+	case types.AWS:
+		err = errors.New("aws not supported yet")
+	case types.Azure:
+		err = newAzureEjector(provisioningOperator, ops...).Eject(cluster, provider, dir)
+	case types.Kind:
+		err = newKindEjector(provisioningOperator, ops...).Eject(cluster, provider, dir)
+	case types.Existing:
+		err = errors.New("existing clusters have no terraform project to eject")
+	default:
+		err = errors.New("unknown provider")
+	}
+	if err != nil {
+		return err
+	}
+	return action.After()
+}
+
+// Rollback restores the version of the cluster state with the given serial, as listed by History, and applies the configuration the cluster had at that version.
+// The specification recorded with that version is restored as well. The current state stays in the history, so a rollback can be undone with another rollback.
+// State versions are only kept in persistent data directories, see types.Persistent. It returns the cluster enriched with the information of the restored state.
+func Rollback(cluster *types.Cluster, provider *types.Provider, serial uint64, ops ...types.Option) (cl *types.Cluster, err error) {
+	ops, done := instrument("rollback", cluster, provider, ops)
+	defer done(&err)
+
+	if err = action.Before(); err != nil {
+		return cl, err
+	}
+
+	if runtime.GOOS == "windows" {
+		provider.CredentialsFilePath = updateWindowsPath(provider.CredentialsFilePath)
+	}
+
+	switch provider.Type {
+	case types.GCP:
+		cl, err = newGCPRollbacker(provisioningOperator, ops...).Rollback(cluster, provider, serial)
+	case types.Gardener:
+		cl, err = newGardenerRollbacker(provisioningOperator, ops...).Rollback(cluster, provider, serial)
+	case types.AWS:
+		err = errors.New("aws not supported yet")
+	case types.Azure:
+		cl, err = newAzureRollbacker(provisioningOperator, ops...).Rollback(cluster, provider, serial)
+	case types.Kind:
+		cl, err = newKindRollbacker(provisioningOperator, ops...).Rollback(cluster, provider, serial)
+	case types.Existing:
+		err = errors.New("existing clusters have no state to roll back")
+	default:
+		err = errors.New("unknown provider")
+	}
+	if err != nil {
+		return cl, err
+	}
+	return cl, action.After()
+}
+
+// Hibernate scales down an existing cluster to save costs and waits until the cluster is hibernated. Its state and configuration are kept, so it can be woken up again.
+// Only Gardener supports hibernation, for all other providers an error is returned.
+func Hibernate(cluster *types.Cluster, provider *types.Provider, ops ...types.Option) (err error) {
+	ops, done := instrument("hibernate", cluster, provider, ops)
+	defer done(&err)
+
+	if err = action.Before(); err != nil {
+		return err
+	}
+
+	if runtime.GOOS == "windows" {
+		provider.CredentialsFilePath = updateWindowsPath(provider.CredentialsFilePath)
+	}
+
+	switch provider.Type {
+	case types.Gardener:
+		err = newGardenerHibernator(provisioningOperator, ops...).Hibernate(cluster, provider)
+	default:
+		err = fmt.Errorf("hibernation is not supported for provider %s", provider.Type)
+	}
+	if err != nil {
+		return err
+	}
+	return action.After()
+}
+
+// WakeUp brings a hibernated cluster back and waits until the cluster is usable again.
+// Only Gardener supports hibernation, for all other providers an error is returned.
+func WakeUp(cluster *types.Cluster, provider *types.Provider, ops ...types.Option) (err error) {
+	ops, done := instrument("wakeup", cluster, provider, ops)
+	defer done(&err)
+
+	if err = action.Before(); err != nil {
+		return err
+	}
+
+	if runtime.GOOS == "windows" {
+		provider.CredentialsFilePath = updateWindowsPath(provider.CredentialsFilePath)
+	}
+
+	switch provider.Type {
+	case types.Gardener:
+		err = newGardenerHibernator(provisioningOperator, ops...).WakeUp(cluster, provider)
+	default:
+		err = fmt.Errorf("hibernation is not supported for provider %s", provider.Type)
+	}
+	if err != nil {
+		return err
+	}
+	return action.After()
+}
+
+// List returns a description of every cluster Hydroform keeps data about in the data directory, including left overs of failed operations.
+// Use the same options as for the other operations, in particular the data directory and the encryption key.
+func List(ops ...types.Option) ([]*types.ClusterDescription, error) {
+	inv, err := newInventory(provisioningOperator, ops...)
+	if err != nil {
+		return nil, err
+	}
+	return inv.List()
+}
+
+// Describe returns the description of a single cluster Hydroform keeps data about in the data directory.
+func Describe(p types.ProviderType, project, cluster string, ops ...types.Option) (*types.ClusterDescription, error) {
+	inv, err := newInventory(provisioningOperator, ops...)
+	if err != nil {
+		return nil, err
+	}
+	return inv.Describe(p, project, cluster)
+}
+
+// History returns the versions of the state of a cluster Hydroform keeps in the data directory, oldest first.
+// Every operation changing the state adds a version, as long as the data directory is persistent.
+func History(p types.ProviderType, project, cluster string, ops ...types.Option) ([]*types.StateVersion, error) {
+	inv, err := newInventory(provisioningOperator, ops...)
+	if err != nil {
+		return nil, err
+	}
+	return inv.History(p, project, cluster)
+}
+
+// StateVersion returns the version of the state of a cluster with the given serial, as listed by History.
+// If an encryption key is given, the state is encrypted as in the ClusterInfo returned by the other operations.
+func StateVersion(p types.ProviderType, project, cluster string, serial uint64, ops ...types.Option) (*types.InternalState, error) {
+	inv, err := newInventory(provisioningOperator, ops...)
+	if err != nil {
+		return nil, err
+	}
+	return inv.StateVersion(p, project, cluster, serial)
+}
+
+// record stores the specification of the cluster in the data directory if it is persistent.
+func record(cluster *types.Cluster, provider *types.Provider, ops ...types.Option) error {
+	os := &types.Options{}
+	for _, o := range ops {
+		o(os)
+	}
+	if !os.Persistent {
+		return nil
+	}
+
+	inv, err := newInventory(provisioningOperator, ops...)
+	if err != nil {
+		return err
+	}
+	return inv.Record(cluster, provider)
+}
+
+// reachedOperator tells if the operator created the directory of the cluster in the data directory, so that it may have created resources.
+func reachedOperator(cluster *types.Cluster, provider *types.Provider, ops ...types.Option) bool {
+	inv, err := newInventory(provisioningOperator, ops...)
+	if err != nil {
+		return false
+	}
+	_, err = inv.Describe(provider.Type, provider.ProjectName, cluster.Name)
+	return err == nil
+}
+
+func newInventory(operatorType operator.Type, ops ...types.Option) (operator.Inventory, error) {
+	os := &types.Options{}
+	for _, o := range ops {
+		o(os)
+	}
+
+	switch operatorType {
+	case operator.TerraformOperator:
+		return terraform_operator.New(terraform_operator.ToTerraformOptions(os)...), nil
+	default:
+		return nil, errors.New("unknown operator")
+	}
+}
+
+// newProvisioner returns the provisioner for the given provider type, or an error if the provider is not supported.
+func newProvisioner(p types.ProviderType, ops ...types.Option) (Provisioner, error) {
+	switch p {
+	case types.GCP:
+		return newGCPProvisioner(provisioningOperator, ops...), nil
+	case types.Gardener:
+		return newGardenerProvisioner(provisioningOperator, ops...), nil
+	case types.AWS:
+		return nil, errors.New("aws not supported yet")
+	case types.Azure:
+		return newAzureProvisioner(provisioningOperator, ops...), nil
+	case types.Kind:
+		return newKindProvisioner(provisioningOperator, ops...), nil
+	case types.Existing:
+		return newExistingProvisioner(ops...), nil
+	default:
+		return nil, errors.New("unknown provider")
+	}
+}
+
 func newGCPProvisioner(operatorType operator.Type, ops ...types.Option) Provisioner {
 	return gcp.New(operatorType, ops...)
 }
@@ -169,6 +560,78 @@ func newGardenerProvisioner(operatorType operator.Type, ops ...types.Option) Pro
 	return gardener.New(operatorType, ops...)
 }
 
+func newGCPValidator(operatorType operator.Type, ops ...types.Option) Validator {
+	return gcp.New(operatorType, ops...)
+}
+
+func newGardenerValidator(operatorType operator.Type, ops ...types.Option) Validator {
+	return gardener.New(operatorType, ops...)
+}
+
+func newAzureValidator(operatorType operator.Type, ops ...types.Option) Validator {
+	return azure.New(operatorType, ops...)
+}
+
+func newKindValidator(operatorType operator.Type, ops ...types.Option) Validator {
+	return kind.New(operatorType, ops...)
+}
+
+func newExistingValidator(ops ...types.Option) Validator {
+	return existing.New(ops...)
+}
+
+func newGCPImporter(operatorType operator.Type, ops ...types.Option) Importer {
+	return gcp.New(operatorType, ops...)
+}
+
+func newGardenerImporter(operatorType operator.Type, ops ...types.Option) Importer {
+	return gardener.New(operatorType, ops...)
+}
+
+func newAzureImporter(operatorType operator.Type, ops ...types.Option) Importer {
+	return azure.New(operatorType, ops...)
+}
+
+func newKindImporter(operatorType operator.Type, ops ...types.Option) Importer {
+	return kind.New(operatorType, ops...)
+}
+
+func newGCPEjector(operatorType operator.Type, ops ...types.Option) Ejector {
+	return gcp.New(operatorType, ops...)
+}
+
+func newGardenerEjector(operatorType operator.Type, ops ...types.Option) Ejector {
+	return gardener.New(operatorType, ops...)
+}
+
+func newAzureEjector(operatorType operator.Type, ops ...types.Option) Ejector {
+	return azure.New(operatorType, ops...)
+}
+
+func newKindEjector(operatorType operator.Type, ops ...types.Option) Ejector {
+	return kind.New(operatorType, ops...)
+}
+
+func newGCPRollbacker(operatorType operator.Type, ops ...types.Option) Rollbacker {
+	return gcp.New(operatorType, ops...)
+}
+
+func newGardenerRollbacker(operatorType operator.Type, ops ...types.Option) Rollbacker {
+	return gardener.New(operatorType, ops...)
+}
+
+func newAzureRollbacker(operatorType operator.Type, ops ...types.Option) Rollbacker {
+	return azure.New(operatorType, ops...)
+}
+
+func newKindRollbacker(operatorType operator.Type, ops ...types.Option) Rollbacker {
+	return kind.New(operatorType, ops...)
+}
+
+func newGardenerHibernator(operatorType operator.Type, ops ...types.Option) Hibernator {
+	return gardener.New(operatorType, ops...)
+}
+
 func newAWSProvisioner(operatorType operator.Type, ops ...types.Option) Provisioner {
 	return nil
 }
@@ -181,6 +644,11 @@ func newKindProvisioner(operatorType operator.Type, ops ...types.Option) Provisi
 	return kind.New(operatorType, ops...)
 }
 
+// newExistingProvisioner does not take an operator, since existing clusters are not managed with one.
+func newExistingProvisioner(ops ...types.Option) Provisioner {
+	return existing.New(ops...)
+}
+
 func updateWindowsPath(windowsPath string) string {
 	cleanWindowsPath := filepath.Clean(windowsPath)
 	return strings.Replace(cleanWindowsPath, `\`, `\\`, -1)
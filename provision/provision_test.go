@@ -0,0 +1,116 @@
+package provision
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kyma-incubator/hydroform/provision/internal/tracing"
+	"github.com/kyma-incubator/hydroform/provision/kubeconfig"
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestProvisionTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	cluster := &types.Cluster{Name: "my-cluster"}
+	provider := &types.Provider{Type: types.AWS, ProjectName: "my-project"}
+	_, err := Provision(cluster, provider, types.WithTracerProvider(tp))
+	require.Error(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, "provision", spans[0].Name())
+	require.Subset(t, spans[0].Attributes(), []attribute.KeyValue{
+		tracing.ProviderKey.String("aws"),
+		tracing.ProjectKey.String("my-project"),
+		tracing.ClusterKey.String("my-cluster"),
+		tracing.OutcomeKey.String("failure"),
+	})
+}
+
+type auditRecorder struct {
+	records []types.AuditRecord
+}
+
+func (a *auditRecorder) Record(r types.AuditRecord) error {
+	a.records = append(a.records, r)
+	return nil
+}
+
+func TestProvisionAudit(t *testing.T) {
+	sink := &auditRecorder{}
+
+	cluster := &types.Cluster{Name: "my-cluster", ClusterInfo: &types.ClusterInfo{Endpoint: "1.2.3.4"}}
+	provider := &types.Provider{
+		Type:        types.AWS,
+		ProjectName: "my-project",
+		Credentials: &types.Credentials{Data: []byte("secret key")},
+		CustomConfigurations: map[string]interface{}{
+			"target_secret": "my-binding",
+			"client_secret": "my-secret",
+		},
+	}
+	_, err := Provision(cluster, provider, types.WithAuditSink(sink), types.WithCaller("jane"))
+	require.Error(t, err)
+
+	require.Len(t, sink.records, 1)
+	r := sink.records[0]
+	require.Equal(t, "provision", r.Operation)
+	require.Equal(t, "jane", r.Caller)
+	require.Equal(t, types.AuditFailure, r.Result)
+	require.Equal(t, "aws not supported yet", r.Error)
+	require.Nil(t, r.Plan, "Nothing was applied")
+
+	require.Equal(t, "my-cluster", r.Cluster.Name)
+	require.Nil(t, r.Cluster.ClusterInfo, "The cluster state should not be audited")
+	require.Nil(t, r.Provider.Credentials.Data, "Credentials should not be audited")
+	require.Equal(t, "my-binding", r.Provider.CustomConfigurations["target_secret"])
+	require.Equal(t, "[REDACTED]", r.Provider.CustomConfigurations["client_secret"], "Secret configurations should be redacted")
+	require.Equal(t, "my-secret", provider.CustomConfigurations["client_secret"], "The given provider should not change")
+}
+
+func TestKubeconfigContext(t *testing.T) {
+	cluster := &types.Cluster{Name: "my-cluster"}
+
+	require.Equal(t, "gcp-my-project-my-cluster", kubeconfigContext(cluster, &types.Provider{Type: types.GCP, ProjectName: "my-project"}, &types.KubeconfigTarget{}))
+	require.NotEqual(t,
+		kubeconfigContext(cluster, &types.Provider{Type: types.GCP, ProjectName: "one"}, &types.KubeconfigTarget{}),
+		kubeconfigContext(cluster, &types.Provider{Type: types.GCP, ProjectName: "two"}, &types.KubeconfigTarget{}),
+		"Clusters of different projects should not share a context")
+	require.Equal(t, "kind-my-cluster", kubeconfigContext(cluster, &types.Provider{Type: types.Kind}, &types.KubeconfigTarget{}))
+	require.Equal(t, "mine", kubeconfigContext(cluster, &types.Provider{Type: types.GCP, ProjectName: "my-project"}, &types.KubeconfigTarget{Context: "mine"}))
+}
+
+func TestKubeconfigExisting(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubeconfig")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config")
+
+	config := api.NewConfig()
+	config.Clusters["c"] = &api.Cluster{Server: "https://1.2.3.4"}
+	config.AuthInfos["u"] = &api.AuthInfo{Token: "secret"}
+	config.Contexts["c"] = &api.Context{Cluster: "c", AuthInfo: "u"}
+	data, err := clientcmd.Write(*config)
+	require.NoError(t, err)
+	require.NoError(t, kubeconfig.Merge(path, "minikube", data))
+
+	cluster := &types.Cluster{Name: "minikube"}
+	provider := &types.Provider{Type: types.Existing}
+	ops := []types.Option{types.WithKubeconfig(path, "minikube")}
+	require.NoError(t, mergeKubeconfig(cluster, provider, nil, ops...), "Existing clusters should not be merged")
+	require.NoError(t, removeKubeconfig(cluster, provider, ops...))
+
+	merged, err := clientcmd.LoadFromFile(path)
+	require.NoError(t, err)
+	require.Contains(t, merged.Contexts, "minikube", "The context of an existing cluster should be kept")
+}
@@ -0,0 +1,53 @@
+package provision
+
+import (
+	"context"
+	"time"
+
+	"github.com/kyma-incubator/hydroform/provision/internal/readiness"
+	"github.com/kyma-incubator/hydroform/provision/internal/tracing"
+	"github.com/kyma-incubator/hydroform/provision/kubeconfig"
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/kubernetes"
+)
+
+// waitForReadiness waits until the provisioned cluster is usable, if enabled with the options.
+// The cluster is reached with the kubeconfig the provisioner returns as credentials.
+func waitForReadiness(cluster *types.Cluster, provider *types.Provider, prov Provisioner, ops ...types.Option) (err error) {
+	os := &types.Options{}
+	for _, o := range ops {
+		o(os)
+	}
+	if os.ReadinessTimeout <= 0 {
+		return nil
+	}
+
+	start := time.Now()
+	_, span := tracing.New(os).Start("readiness")
+	defer func() {
+		tracing.End(span, err)
+		if os.Metrics != nil {
+			os.Metrics.ObservePhase("readiness", provider.Type, time.Since(start), err)
+		}
+	}()
+
+	data, err := prov.Credentials(cluster, provider)
+	if err != nil {
+		return errors.Wrap(err, "could not get the kubeconfig to wait for the cluster")
+	}
+	config, err := kubeconfig.RESTConfig(data)
+	if err != nil {
+		return err
+	}
+	k8s, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return errors.Wrap(err, "could not create a client to wait for the cluster")
+	}
+
+	ctx := os.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return readiness.Wait(ctx, k8s, cluster.Name, os.ReadinessTimeout, os.ReadinessObserver)
+}
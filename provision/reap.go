@@ -0,0 +1,60 @@
+package provision
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/pkg/errors"
+)
+
+// Reap deprovisions all clusters in the data directory that expired and are not protected, see Cluster.TTL.
+// Only clusters provisioned with the Persistent option can be reaped, since Reap relies on the specification Hydroform records for them.
+// Expired clusters provisioned with in-memory credentials cannot be reaped, since their credentials are never recorded. They are reported in the returned error.
+// In dry run mode, no cluster is deprovisioned.
+// Reap returns the clusters that were deprovisioned, or would have been in dry run mode. Failing to deprovision a cluster does not stop the others from being reaped.
+func Reap(dryRun bool, ops ...types.Option) ([]*types.ClusterDescription, error) {
+	clusters, err := List(ops...)
+	if err != nil {
+		return nil, err
+	}
+
+	// reaped clusters do not need their files anymore, the files of clusters that fail to be reaped are kept to reap them later
+	deprovisionOps := make([]types.Option, len(ops), len(ops)+1)
+	copy(deprovisionOps, ops)
+	deprovisionOps = append(deprovisionOps, func(o *types.Options) {
+		o.Persistent = false
+	})
+
+	now := time.Now()
+	reaped := make([]*types.ClusterDescription, 0)
+	var errMessage string
+	for _, c := range clusters {
+		if c.ClusterSpec == nil || c.ProviderSpec == nil || !c.ClusterSpec.Expired(now) {
+			continue
+		}
+		if inMemoryCredentials(c.ProviderSpec) {
+			errMessage += fmt.Sprintf("\n - %s/%s/%s: the cluster was provisioned with in-memory credentials, which are not recorded; deprovision it with its credentials", c.Provider, c.Project, c.Name)
+			continue
+		}
+
+		if !dryRun {
+			if err := Deprovision(c.ClusterSpec, c.ProviderSpec, deprovisionOps...); err != nil {
+				errMessage += fmt.Sprintf("\n - %s/%s/%s: %s", c.Provider, c.Project, c.Name, err)
+				continue
+			}
+		}
+		reaped = append(reaped, c)
+	}
+
+	if errMessage != "" {
+		return reaped, errors.New("could not reap the following clusters: " + errMessage)
+	}
+	return reaped, nil
+}
+
+// inMemoryCredentials tells whether the recorded provider got its credentials from memory, which are not recorded.
+func inMemoryCredentials(p *types.Provider) bool {
+	c := p.Credentials
+	return c != nil && c.File == "" && !c.Env
+}
@@ -0,0 +1,70 @@
+package provision
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReapInMemoryCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reap")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	ops := []types.Option{types.WithDataDir(dir), types.Persistent()}
+
+	expired := time.Now().Add(-time.Hour)
+	cluster := &types.Cluster{Name: "my-cluster", ExpiresAt: expired}
+	provider := &types.Provider{Type: types.GCP, ProjectName: "my-project", Credentials: &types.Credentials{Data: []byte("secret key")}}
+	require.NoError(t, record(cluster, provider, ops...))
+
+	reaped, err := Reap(false, ops...)
+	require.Error(t, err, "Clusters which cannot be reaped should be reported")
+	require.Contains(t, err.Error(), "gcp/my-project/my-cluster: the cluster was provisioned with in-memory credentials")
+	require.Empty(t, reaped)
+
+	clusters, err := List(ops...)
+	require.NoError(t, err)
+	require.Len(t, clusters, 1, "The cluster should be kept")
+}
+
+func TestProvisionDoesNotRecordRejectedClusters(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reap")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	ops := []types.Option{types.WithDataDir(dir), types.Persistent()}
+
+	_, err = Provision(&types.Cluster{Name: "my-cluster", TTL: time.Hour}, &types.Provider{Type: types.AWS, ProjectName: "my-project"}, ops...)
+	require.Error(t, err, "Unsupported providers should fail")
+
+	_, err = Provision(&types.Cluster{Name: "Invalid_Name", TTL: time.Hour}, &types.Provider{Type: types.Kind, ProjectName: "my-project"}, ops...)
+	require.Error(t, err, "Invalid inputs should fail")
+	require.Equal(t, types.ErrorClassValidation, types.ErrorClass(err))
+
+	_, err = Provision(&types.Cluster{Name: "minikube", TTL: time.Hour}, &types.Provider{Type: types.Existing}, ops...)
+	require.Error(t, err, "Existing clusters cannot expire")
+	require.Contains(t, err.Error(), "Cluster.TTL and Cluster.ExpiresAt cannot be set for existing clusters")
+
+	clusters, err := List(ops...)
+	require.NoError(t, err)
+	require.Empty(t, clusters, "Clusters which never reached the provider should not be recorded")
+}
+
+func TestReachedOperator(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reap")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	ops := []types.Option{types.WithDataDir(dir), types.Persistent()}
+
+	cluster := &types.Cluster{Name: "my-cluster"}
+	provider := &types.Provider{Type: types.GCP, ProjectName: "my-project"}
+	require.False(t, reachedOperator(cluster, provider, ops...))
+
+	// the operator failed after creating the directory of the cluster, for example because terraform apply failed
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "clusters", "gcp", "my-project", "my-cluster"), 0700))
+	require.True(t, reachedOperator(cluster, provider, ops...), "Clusters the operator worked on should be recorded")
+}
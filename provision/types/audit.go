@@ -0,0 +1,45 @@
+package types
+
+import "time"
+
+// AuditSink receives a record of every operation, see WithAuditSink. Implementations must be safe for concurrent use.
+type AuditSink interface {
+	// Record stores the record of a finished operation. If it fails, the operation returns the error.
+	Record(r AuditRecord) error
+}
+
+// AuditRecord describes a finished operation for the audit log.
+// The cluster and provider specifications are copies without the cluster state and with all secrets removed.
+type AuditRecord struct {
+	// Time is the point in time the operation started.
+	Time time.Time `json:"time"`
+	// Operation is the name of the operation, such as provision or deprovision.
+	Operation string `json:"operation"`
+	// Caller is the identity of the caller, as given with WithCaller.
+	Caller string `json:"caller,omitempty"`
+	// Cluster is the specification of the cluster the operation ran on.
+	Cluster *Cluster `json:"cluster"`
+	// Provider is the specification of the provider the operation ran on.
+	Provider *Provider `json:"provider"`
+	// Plan summarizes the changes the operation applied to the resources of the cluster, if it applied any.
+	Plan *PlanSummary `json:"plan,omitempty"`
+	// Duration is the time the operation took.
+	Duration time.Duration `json:"duration"`
+	// Result is either success or failure.
+	Result string `json:"result"`
+	// Error is the error the operation failed with, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// Results of audit records.
+const (
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+)
+
+// PlanSummary counts the resources a plan added, changed and destroyed.
+type PlanSummary struct {
+	Added     int `json:"added"`
+	Changed   int `json:"changed"`
+	Destroyed int `json:"destroyed"`
+}
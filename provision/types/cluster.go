@@ -1,12 +1,18 @@
 package types
 
-import "github.com/hashicorp/terraform/states/statefile"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/hashicorp/terraform/states/statefile"
+)
 
 // Cluster contains detailed cluster specification and properties.
 type Cluster struct {
 	// Name specifies the unique name used to identify the cluster.
 	Name string `json:"name"`
 	// KubernetesVersion specifies the Kubernetes version used.
+	// Besides exact versions, it accepts a minor version such as 1.16, "latest" and "default", which are resolved against the versions the provider offers.
 	KubernetesVersion string `json:"kubernetesVersion"`
 	// CPU specifies the number of CPUs available in the cluster.
 	CPU int `json:"cpu"`
@@ -17,8 +23,34 @@ type Cluster struct {
 	// MachineType specifies the hardware cluster is provisioned on.
 	MachineType string `json:"machineType"`
 	// Location specifies the location of the actual cluster.
-	Location    string       `json:"location"`
-	ClusterInfo *ClusterInfo `json:"clusterInfo"`
+	Location string `json:"location"`
+	// TTL specifies how long the cluster is allowed to exist before Reap removes it. Zero means the cluster never expires.
+	// Clusters of the existing provider cannot have a TTL, since Hydroform never deletes them.
+	TTL time.Duration `json:"ttl,omitempty"`
+	// ExpiresAt is the point in time after which Reap removes the cluster.
+	// If not set, Provision calculates it from the TTL.
+	ExpiresAt time.Time `json:"expiresAt,omitempty"`
+	// Protected prevents Reap from removing the cluster even if it expired.
+	// Setting the ProtectedLabel to "true" has the same effect.
+	Protected bool `json:"protected,omitempty"`
+	// Labels specifies the labels put on the provider resources of the cluster.
+	// They are rendered as resource labels on GCP, tags on Azure, shoot labels on Gardener and node labels on kind.
+	// If the nodes of a kind cluster cannot be labeled, Provision still returns the cluster and reports the failure as a NodesLabeled condition in its status.
+	Labels      map[string]string `json:"labels,omitempty"`
+	ClusterInfo *ClusterInfo      `json:"clusterInfo"`
+}
+
+const (
+	// ExpiresAtLabel is the label on the provider resources containing the expiry date of the cluster as unix time.
+	ExpiresAtLabel = "hydroform-expires-at"
+	// ProtectedLabel is the label on the provider resources marking clusters that must never be reaped.
+	ProtectedLabel = "hydroform-protected"
+)
+
+// Expired returns true if the cluster has an expiry date in the past and is not protected.
+func (c *Cluster) Expired(now time.Time) bool {
+	protected := c.Protected || c.Labels[ProtectedLabel] == "true"
+	return !protected && !c.ExpiresAt.IsZero() && c.ExpiresAt.Before(now)
 }
 
 // ClusterInfo contains the actual provider-related cluster details retrieved after the cluster was provisioned.
@@ -27,14 +59,57 @@ type ClusterInfo struct {
 	Endpoint string `json:"endpoint"`
 	// CertificateAuthorityData contains certificates required to access the cluster.
 	CertificateAuthorityData []byte `json:"certificateAuthorityData"`
+	// KubernetesVersion is the exact Kubernetes version running on the cluster, as read from the cluster state.
+	// It is empty if the state does not contain the version, it is never filled in with the requested version.
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
 	// InternalState contains the Hydroform-specific information used to manage the cluster.
 	InternalState *InternalState `json:"internalState"`
 	Status        *ClusterStatus `json:"status"`
 }
 
+// Difference is a setting of an imported cluster whose actual value differs from the requested one.
+type Difference struct {
+	// Setting is the name of the setting, for example Cluster.MachineType.
+	Setting string `json:"setting"`
+	// Requested is the value given in the cluster or provider specification.
+	Requested string `json:"requested"`
+	// Actual is the value of the existing cluster.
+	Actual string `json:"actual"`
+}
+
 // ClusterStatus contains possible values used to indicate the current cluster status.
 type ClusterStatus struct {
 	Phase Phase `json:"phase"`
+	// LastOperation describes the last operation the provider ran on the cluster, if the provider reports it.
+	LastOperation *Operation `json:"lastOperation,omitempty"`
+	// Conditions contains the health checks the provider reports for the cluster.
+	Conditions []Condition `json:"conditions,omitempty"`
+}
+
+// Operation describes an operation the provider runs on a cluster, such as a creation or an update.
+type Operation struct {
+	// Type is the kind of operation, for example Create, Reconcile or Delete.
+	Type string `json:"type"`
+	// State is the state of the operation, for example Processing, Succeeded or Error.
+	State string `json:"state"`
+	// Progress is the completion of the operation in percent.
+	Progress int `json:"progress"`
+	// Description is a human readable message about the operation.
+	Description string `json:"description,omitempty"`
+	// LastUpdateTime is the time the operation was last updated.
+	LastUpdateTime time.Time `json:"lastUpdateTime,omitempty"`
+}
+
+// Condition is a health check of the cluster.
+type Condition struct {
+	// Type is the name of the check, for example APIServerAvailable.
+	Type string `json:"type"`
+	// Status is True, False, Unknown or Progressing.
+	Status string `json:"status"`
+	// Reason is a short machine readable explanation of the status.
+	Reason string `json:"reason,omitempty"`
+	// Message is a human readable explanation of the status.
+	Message string `json:"message,omitempty"`
 }
 
 // Phase indicates the current status of the cluster.
@@ -43,6 +118,12 @@ type Phase string
 const (
 	// Provisioned indicates that the cluster has been created and is fully usable.
 	Provisioned Phase = "Provisioned"
+	// Provisioning indicates that the cluster is being created.
+	Provisioning Phase = "Provisioning"
+	// Deprovisioning indicates that the cluster is being deleted.
+	Deprovisioning Phase = "Deprovisioning"
+	// Hibernated indicates that the cluster is scaled down to save costs and must be woken up before it can be used.
+	Hibernated Phase = "Hibernated"
 	// Errored indicates that the cluster may be unusable due to errors.
 	Errored Phase = "Errored"
 	// Unknown indicates that the cluster status is not known.
@@ -51,5 +132,17 @@ const (
 
 // InternalState holds the state information of the internal operator which is currently in use. Hydroform uses this information for internal purposes only.
 type InternalState struct {
-	TerraformState *statefile.File
+	TerraformState *statefile.File `json:"terraformState,omitempty"`
+	// EncryptedTerraformState contains the terraform state encrypted with the key provided in the options.
+	// If present, the plain TerraformState is never serialized.
+	EncryptedTerraformState []byte `json:"encryptedTerraformState,omitempty"`
+}
+
+// MarshalJSON serializes the internal state making sure that the plain state is left out if an encrypted one is available.
+func (s InternalState) MarshalJSON() ([]byte, error) {
+	type plain InternalState
+	if len(s.EncryptedTerraformState) > 0 {
+		s.TerraformState = nil
+	}
+	return json.Marshal(plain(s))
 }
@@ -0,0 +1,52 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// FleetMember is a cluster to be handled as part of a fleet, together with the provider it belongs to.
+type FleetMember struct {
+	Cluster  *Cluster
+	Provider *Provider
+}
+
+// FleetResult is the outcome of the operation on a single member of a fleet.
+type FleetResult struct {
+	// Cluster is the cluster enriched with information from the provider if the operation succeeded, otherwise the cluster as given.
+	Cluster *Cluster
+	// Provider is the provider of the cluster as given.
+	Provider *Provider
+	// Duration is the time the operation on the cluster took.
+	Duration time.Duration
+	// Err is the error the operation failed with, if any.
+	Err error
+}
+
+// FleetSummary aggregates the results of an operation on a fleet of clusters.
+type FleetSummary struct {
+	// Results contains the result of each member, in the order the members were given.
+	Results []*FleetResult
+	// Succeeded is the number of clusters the operation succeeded for.
+	Succeeded int
+	// Failed is the number of clusters the operation failed for.
+	Failed int
+	// Duration is the time the operation on the whole fleet took.
+	Duration time.Duration
+}
+
+// Err returns an error listing all clusters the operation failed for, or nil if it succeeded for all of them.
+func (s *FleetSummary) Err() error {
+	var errMessage string
+	for _, r := range s.Results {
+		if r.Err != nil {
+			errMessage += fmt.Sprintf("\n - %s/%s/%s: %s", r.Provider.Type, r.Provider.ProjectName, r.Cluster.Name, r.Err)
+		}
+	}
+	if errMessage != "" {
+		return errors.Errorf("%d of %d clusters failed: %s", s.Failed, len(s.Results), errMessage)
+	}
+	return nil
+}
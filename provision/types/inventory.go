@@ -0,0 +1,39 @@
+package types
+
+import "time"
+
+// ClusterDescription summarizes a cluster found in the Hydroform data directory.
+type ClusterDescription struct {
+	// Name is the name of the cluster.
+	Name string `json:"name"`
+	// Provider is the cloud provider the cluster was created on.
+	Provider ProviderType `json:"provider"`
+	// Project is the project the cluster belongs to. In the case of Azure, it represents the resource group.
+	Project string `json:"project"`
+	// Dir is the directory containing the state and configuration files of the cluster.
+	Dir string `json:"dir"`
+	// LastModified is the last time the cluster state was changed.
+	LastModified time.Time `json:"lastModified"`
+	// ResourceCount is the number of resources in the cluster state.
+	ResourceCount int `json:"resourceCount"`
+	// Phase is the cluster phase according to its state.
+	Phase Phase `json:"phase"`
+	// ClusterSpec is the specification the cluster was provisioned with, if Hydroform recorded it.
+	ClusterSpec *Cluster `json:"clusterSpec,omitempty"`
+	// ProviderSpec is the provider specification the cluster was provisioned with, if Hydroform recorded it.
+	ProviderSpec *Provider `json:"providerSpec,omitempty"`
+	// Error describes why the state or the specification of the cluster could not be read, for example because of a wrong encryption key.
+	// The phase of such a cluster is unknown.
+	Error string `json:"error,omitempty"`
+}
+
+// StateVersion describes a version of the cluster state that Hydroform keeps in the history of the cluster.
+type StateVersion struct {
+	// Serial is the serial of the state, which terraform increases with every change.
+	Serial uint64 `json:"serial"`
+	// Time is when the version was stored.
+	Time time.Time `json:"time"`
+	// Operation is the operation that produced the state, such as create, delete, import or rollback.
+	// States which Hydroform did not write itself, for example states passed in with the cluster information, are marked as existing.
+	Operation string `json:"operation"`
+}
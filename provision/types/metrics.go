@@ -0,0 +1,80 @@
+package types
+
+import "time"
+
+// Metrics records measurements of Hydroform operations, see WithMetrics. Implementations must be safe for concurrent use.
+type Metrics interface {
+	// ObserveOperation records a finished operation, such as provision or deprovision, how long it took and the error it failed with, if any.
+	ObserveOperation(operation string, p ProviderType, d time.Duration, err error)
+	// ObservePhase records a finished phase of an operation, such as init, apply, import or destroy, how long it took and the error it failed with, if any.
+	ObservePhase(phase string, p ProviderType, d time.Duration, err error)
+	// ObserveRetry records that a phase of an operation was retried and why.
+	ObserveRetry(phase string, p ProviderType, reason string)
+}
+
+// Error classes returned by ErrorClass.
+const (
+	ErrorClassNone          = "none"
+	ErrorClassTimeout       = "timeout"
+	ErrorClassValidation    = "validation"
+	ErrorClassCredentials   = "credentials"
+	ErrorClassQuota         = "quota"
+	ErrorClassAlreadyExists = "already_exists"
+	ErrorClassNotFound      = "not_found"
+	ErrorClassOther         = "other"
+)
+
+// ClassifiedError is an error whose class is known where it occurs, see ErrorClass.
+type ClassifiedError struct {
+	// Class is one of the error classes, such as ErrorClassValidation.
+	Class string
+	// Err is the classified error.
+	Err error
+}
+
+func (e *ClassifiedError) Error() string {
+	return e.Err.Error()
+}
+
+// Cause returns the classified error, so that it can be inspected with errors.Cause.
+func (e *ClassifiedError) Cause() error {
+	return e.Err
+}
+
+// Unwrap returns the classified error, so that it can be inspected with the errors package of the standard library.
+func (e *ClassifiedError) Unwrap() error {
+	return e.Err
+}
+
+// Classify marks the error with the given class. A nil error stays nil.
+func Classify(class string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ClassifiedError{Class: class, Err: err}
+}
+
+// ErrorClass returns the class of the given error, which is coarse enough to label metrics with, such as timeout or quota.
+// The class is taken from the outermost TimeoutError or ClassifiedError the error was caused by, errors without one are of class other.
+func ErrorClass(err error) string {
+	if err == nil {
+		return ErrorClassNone
+	}
+	for err != nil {
+		switch e := err.(type) {
+		case *TimeoutError:
+			return ErrorClassTimeout
+		case *ClassifiedError:
+			return e.Class
+		}
+		switch e := err.(type) {
+		case interface{ Cause() error }:
+			err = e.Cause()
+		case interface{ Unwrap() error }:
+			err = e.Unwrap()
+		default:
+			err = nil
+		}
+	}
+	return ErrorClassOther
+}
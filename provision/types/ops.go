@@ -1,6 +1,11 @@
 package types
 
-import "time"
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
 
 // Options contains all possible configuration options for Hydroform.
 // Options need to be set each time a Hydroform function is called
@@ -8,6 +13,31 @@ type Options struct {
 	DataDir    string
 	Persistent bool
 	Timeouts   *Timeouts
+	// EncryptionKey specifies where to get the key used to encrypt the cluster state at rest.
+	// If not set, the state is stored in plain text.
+	EncryptionKey *EncryptionKey
+	// VersionSource provides the Kubernetes versions used to resolve and validate Cluster.KubernetesVersion.
+	// If not set, the snapshot shipped with Hydroform is used.
+	VersionSource VersionSource
+	// Metrics records the operations and their phases. If not set, nothing is recorded.
+	Metrics Metrics
+	// TracerProvider creates the tracer for the spans of the operations. If not set, nothing is traced.
+	TracerProvider trace.TracerProvider
+	// Context is the parent context of the operations. If it carries a span, the spans of the operations become its children.
+	Context context.Context
+	// AuditSink receives a record of every operation. If not set, nothing is audited.
+	AuditSink AuditSink
+	// Caller identifies who runs the operations in the audit records.
+	Caller string
+	// PlanObserver is called with the summary of every plan applied during an operation.
+	PlanObserver func(PlanSummary)
+	// ReadinessTimeout makes Provision wait up to the given time until the cluster is usable, see WithReadiness. Zero means Provision does not wait.
+	ReadinessTimeout time.Duration
+	// ReadinessObserver is called with the progress of waiting for the cluster to become usable.
+	ReadinessObserver func(ReadinessEvent)
+	// Kubeconfig is the kubeconfig file that Provision merges the credentials of the cluster into and Deprovision removes them from again.
+	// If not set, no kubeconfig file is changed.
+	Kubeconfig *KubeconfigTarget
 }
 
 // Timeouts specifies timeouts on various operation
@@ -15,6 +45,28 @@ type Timeouts struct {
 	Create time.Duration
 	Update time.Duration
 	Delete time.Duration
+	// Operation bounds a whole operation, including initialization and downloads. When it is exceeded, terraform is stopped gracefully and a TimeoutError is returned.
+	// Zero means the operation is not bound.
+	Operation time.Duration
+}
+
+// EncryptionKey specifies the source of the key used to encrypt the cluster state.
+// Only one of the sources must be set. Any secret can be used as key, Hydroform derives an AES-256 key from it.
+type EncryptionKey struct {
+	// Value contains the key itself.
+	Value []byte
+	// File is the path to a file containing the key.
+	File string
+	// Env is the name of an environment variable containing the key.
+	Env string
+}
+
+// KubeconfigTarget specifies where the credentials of a cluster are merged into, see WithKubeconfig.
+type KubeconfigTarget struct {
+	// Path is the kubeconfig file. If empty, the default kubeconfig file in the home directory is used.
+	Path string
+	// Context is the name of the context, cluster and user of the cluster in the kubeconfig file. If empty, the provider type, the project and the name of the cluster joined with dashes are used, for example gcp-my-project-my-cluster.
+	Context string
 }
 
 // Option is a function that allows to extensibly configure Hydroform.
@@ -40,3 +92,108 @@ func WithTimeouts(timeouts *Timeouts) Option {
 		ops.Timeouts = timeouts
 	}
 }
+
+// Bound each operation as a whole by the given timeout, see Timeouts.Operation.
+func WithOperationTimeout(timeout time.Duration) Option {
+	return func(ops *Options) {
+		timeouts := Timeouts{}
+		if ops.Timeouts != nil {
+			timeouts = *ops.Timeouts
+		}
+		timeouts.Operation = timeout
+		ops.Timeouts = &timeouts
+	}
+}
+
+// Encrypt the cluster state with the given key, both in the data directory and in the cluster's internal state.
+func WithEncryptionKey(key []byte) Option {
+	return func(ops *Options) {
+		ops.EncryptionKey = &EncryptionKey{Value: key}
+	}
+}
+
+// Encrypt the cluster state with the key contained in the given file.
+func WithEncryptionKeyFile(path string) Option {
+	return func(ops *Options) {
+		ops.EncryptionKey = &EncryptionKey{File: path}
+	}
+}
+
+// Encrypt the cluster state with the key contained in the given environment variable.
+func WithEncryptionKeyEnv(name string) Option {
+	return func(ops *Options) {
+		ops.EncryptionKey = &EncryptionKey{Env: name}
+	}
+}
+
+// Resolve and validate Kubernetes versions against the given source instead of the snapshot shipped with Hydroform.
+func WithVersionSource(source VersionSource) Option {
+	return func(ops *Options) {
+		ops.VersionSource = source
+	}
+}
+
+// Record operations and their phases with the given metrics, for example with the Prometheus collector of the metrics package.
+func WithMetrics(m Metrics) Option {
+	return func(ops *Options) {
+		ops.Metrics = m
+	}
+}
+
+// Trace the operations and their phases with the given OpenTelemetry tracer provider.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(ops *Options) {
+		ops.TracerProvider = tp
+	}
+}
+
+// Run the operations in the given context, for example to continue a trace.
+func WithContext(ctx context.Context) Option {
+	return func(ops *Options) {
+		ops.Context = ctx
+	}
+}
+
+// Send a record of every operation to the given audit sink, for example to the JSON lines file of the audit package.
+func WithAuditSink(sink AuditSink) Option {
+	return func(ops *Options) {
+		ops.AuditSink = sink
+	}
+}
+
+// Identify the caller of the operations in the audit records, for example with the name of the user of the embedding application.
+func WithCaller(identity string) Option {
+	return func(ops *Options) {
+		ops.Caller = identity
+	}
+}
+
+// Call the given function with the summary of every plan applied during an operation.
+func WithPlanObserver(f func(PlanSummary)) Option {
+	return func(ops *Options) {
+		ops.PlanObserver = f
+	}
+}
+
+// Make Provision wait up to the given timeout until the cluster is usable: the API server answers, all nodes are ready and the workloads in kube-system are running.
+// If the cluster is not usable in time, Provision fails with a TimeoutError, but the cluster is kept.
+func WithReadiness(timeout time.Duration) Option {
+	return func(ops *Options) {
+		ops.ReadinessTimeout = timeout
+	}
+}
+
+// Call the given function with the progress of waiting for the cluster to become usable, see WithReadiness.
+func WithReadinessObserver(f func(ReadinessEvent)) Option {
+	return func(ops *Options) {
+		ops.ReadinessObserver = f
+	}
+}
+
+// Merge the credentials of provisioned clusters into the given kubeconfig file under the given context name, and remove them again when the clusters are deprovisioned.
+// An empty path means the default kubeconfig file in the home directory. An empty context name means the provider type, the project and the name of the cluster joined with dashes, for example gcp-my-project-my-cluster, see KubeconfigTarget.
+func WithKubeconfig(path, context string) Option {
+	return func(ops *Options) {
+		ops.Kubeconfig = &KubeconfigTarget{Path: path, Context: context}
+	}
+}
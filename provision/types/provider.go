@@ -9,10 +9,26 @@ type Provider struct {
 	ProjectName string `json:"projectName"`
 	// CredentialsFilePath specifies the path to credentials used to access the cloud provider.
 	CredentialsFilePath string `json:"credentialsFilePath"`
+	// Credentials specifies other sources of the credentials used to access the cloud provider.
+	// If set, it takes precedence over CredentialsFilePath.
+	Credentials *Credentials `json:"credentials,omitempty"`
 	// CustomConfigurations is a list of custom properties relevant for the chosen provider.
 	CustomConfigurations map[string]interface{} `json:"customConfigurations"`
 }
 
+// Credentials specifies the source of the credentials used to access the cloud provider.
+// Only one of the sources must be set. The accepted formats depend on the provider:
+// a service account key for GCP, a kubeconfig for Gardener and existing clusters, and a TOML file or an SDK auth file (az ad sp create-for-rbac --sdk-auth) for Azure.
+type Credentials struct {
+	// Data contains the credentials themselves. It is never persisted.
+	Data []byte `json:"-"`
+	// File is the path to a file containing the credentials.
+	File string `json:"file,omitempty"`
+	// Env reads the credentials from the standard environment variables of the provider:
+	// GOOGLE_CREDENTIALS or GOOGLE_APPLICATION_CREDENTIALS for GCP, KUBECONFIG for Gardener and existing clusters, and ARM_SUBSCRIPTION_ID, ARM_TENANT_ID, ARM_CLIENT_ID and ARM_CLIENT_SECRET for Azure.
+	Env bool `json:"env,omitempty"`
+}
+
 // ProviderType lists available cloud providers.
 type ProviderType string
 
@@ -27,4 +43,6 @@ const (
 	Gardener ProviderType = "gardener"
 	// Kind stands for the kind (kubernetes in docker) platform.
 	Kind ProviderType = "kind"
+	// Existing stands for a cluster created outside of Hydroform, such as minikube, k3s or an on-premise cluster, which is reached with its kubeconfig.
+	Existing ProviderType = "existing"
 )
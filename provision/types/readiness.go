@@ -0,0 +1,28 @@
+package types
+
+import "time"
+
+// Readiness checks, which are passed in the given order while waiting for a cluster to become usable.
+const (
+	// ReadinessAPIServer checks that the API server answers with the kubeconfig of the cluster.
+	ReadinessAPIServer = "api server"
+	// ReadinessNodes checks that the cluster has nodes and all of them are ready.
+	ReadinessNodes = "nodes"
+	// ReadinessSystemWorkloads checks that all deployments and daemon sets in kube-system are running.
+	ReadinessSystemWorkloads = "system workloads"
+)
+
+// ReadinessEvent reports the progress of waiting for a cluster to become usable, see WithReadinessObserver.
+// An event is sent whenever the result of a check changes.
+type ReadinessEvent struct {
+	// Cluster is the name of the cluster.
+	Cluster string
+	// Check is the readiness check, such as ReadinessNodes.
+	Check string
+	// Ready tells whether the check passed.
+	Ready bool
+	// Message describes the result of the check, for example how many nodes are ready.
+	Message string
+	// Elapsed is the time since waiting started.
+	Elapsed time.Duration
+}
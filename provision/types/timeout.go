@@ -0,0 +1,42 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeoutError is returned when an operation exceeds Timeouts.Operation.
+type TimeoutError struct {
+	// Phase is the phase the operation was in when the timeout was exceeded, such as init or apply.
+	Phase string
+	// Timeout is the timeout that was exceeded.
+	Timeout time.Duration
+	// Err is the error the phase failed with after it was stopped, if any.
+	Err error
+}
+
+func (e *TimeoutError) Error() string {
+	msg := fmt.Sprintf("operation timed out after %s during %s", e.Timeout, e.Phase)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// AsTimeout returns the TimeoutError the given error was caused by, or nil if it was not caused by a timeout.
+func AsTimeout(err error) *TimeoutError {
+	for err != nil {
+		if te, ok := err.(*TimeoutError); ok {
+			return te
+		}
+		switch e := err.(type) {
+		case interface{ Cause() error }:
+			err = e.Cause()
+		case interface{ Unwrap() error }:
+			err = e.Unwrap()
+		default:
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package types
+
+const (
+	// LatestVersion resolves to the newest Kubernetes version a provider offers.
+	LatestVersion = "latest"
+	// DefaultVersion resolves to the Kubernetes version a provider uses when none is requested.
+	DefaultVersion = "default"
+)
+
+// VersionCatalog lists the Kubernetes versions a provider offers.
+type VersionCatalog struct {
+	// Versions contains the exact versions the provider accepts, for example 1.16.4 or 1.15.9-gke.8.
+	Versions []string `json:"versions"`
+	// Default is the version the provider uses when none is requested. If empty, the latest version is the default.
+	Default string `json:"default,omitempty"`
+}
+
+// VersionSource provides the Kubernetes versions offered by the providers.
+// The versions package reads them from the provider APIs, implement it to resolve versions against an internal mirror instead of the snapshot shipped with Hydroform.
+type VersionSource interface {
+	// Versions returns the catalog of the provider in the given location.
+	Versions(p ProviderType, location string) (*VersionCatalog, error)
+}
@@ -0,0 +1,120 @@
+package versions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/services/containerservice/mgmt/2017-09-30/containerservice"
+	"github.com/Azure/go-autorest/autorest/azure/auth"
+	"github.com/kyma-incubator/hydroform/provision/internal/credentials"
+	snapshot "github.com/kyma-incubator/hydroform/provision/internal/versions"
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/pkg/errors"
+	"google.golang.org/api/container/v1"
+	"google.golang.org/api/option"
+)
+
+// Live is a version source reading the Kubernetes versions from the API of a provider, so that versions can be requested as soon as the provider offers them.
+// Pass it to the operations with types.WithVersionSource. Versions of other providers are taken from the snapshot shipped with Hydroform.
+type Live struct {
+	provider types.ProviderType
+	// catalog reads the versions of a location from the provider, replaced in tests.
+	catalog func(location string) (*types.VersionCatalog, error)
+
+	mu sync.Mutex
+	// cache keeps the catalogs per location, the versions change rarely compared to how often they are resolved.
+	cache map[string]*types.VersionCatalog
+}
+
+// NewLive creates a version source for the given provider, authenticated with its credentials.
+// GKE and AKS are supported, the versions of Gardener and kind are not offered by an API.
+func NewLive(p *types.Provider) (*Live, error) {
+	l := &Live{provider: p.Type, cache: map[string]*types.VersionCatalog{}}
+
+	switch p.Type {
+	case types.GCP:
+		key, err := credentials.GCP(p)
+		if err != nil {
+			return nil, err
+		}
+		svc, err := container.NewService(context.Background(), option.WithCredentialsJSON(key))
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to connect to gcp")
+		}
+		l.catalog = func(location string) (*types.VersionCatalog, error) {
+			cfg, err := svc.Projects.Locations.GetServerConfig(fmt.Sprintf("projects/%s/locations/%s", p.ProjectName, location)).Do()
+			if err != nil {
+				return nil, err
+			}
+			return gkeCatalog(cfg), nil
+		}
+	case types.Azure:
+		principal, err := credentials.Azure(p)
+		if err != nil {
+			return nil, err
+		}
+		authorizer, err := auth.NewClientCredentialsConfig(principal.ClientID, principal.ClientSecret, principal.TenantID).Authorizer()
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to connect to azure")
+		}
+		client := containerservice.NewContainerServicesClient(principal.SubscriptionID)
+		client.Authorizer = authorizer
+		l.catalog = func(location string) (*types.VersionCatalog, error) {
+			res, err := client.ListOrchestrators(context.Background(), location, "managedClusters")
+			if err != nil {
+				return nil, err
+			}
+			return aksCatalog(res), nil
+		}
+	default:
+		return nil, errors.Errorf("provider %s does not offer its Kubernetes versions through an API", p.Type)
+	}
+	return l, nil
+}
+
+// Versions returns the versions the provider offers in the location.
+func (l *Live) Versions(p types.ProviderType, location string) (*types.VersionCatalog, error) {
+	if p != l.provider {
+		return snapshot.Snapshot().Versions(p, location)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if c, ok := l.cache[location]; ok {
+		return c, nil
+	}
+	c, err := l.catalog(location)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read the Kubernetes versions of %s", p)
+	}
+	l.cache[location] = c
+	return c, nil
+}
+
+// gkeCatalog reads the versions from the server config of a GKE location.
+func gkeCatalog(cfg *container.ServerConfig) *types.VersionCatalog {
+	return &types.VersionCatalog{
+		Versions: append([]string(nil), cfg.ValidMasterVersions...),
+		Default:  cfg.DefaultClusterVersion,
+	}
+}
+
+// aksCatalog reads the Kubernetes versions from the orchestrators AKS offers in a location.
+func aksCatalog(res containerservice.OrchestratorVersionProfileListResult) *types.VersionCatalog {
+	c := &types.VersionCatalog{}
+	if res.OrchestratorVersionProfileProperties == nil || res.Orchestrators == nil {
+		return c
+	}
+	for _, o := range *res.Orchestrators {
+		if o.OrchestratorType == nil || !strings.EqualFold(*o.OrchestratorType, "Kubernetes") || o.OrchestratorVersion == nil {
+			continue
+		}
+		c.Versions = append(c.Versions, *o.OrchestratorVersion)
+		if o.Default != nil && *o.Default {
+			c.Default = *o.OrchestratorVersion
+		}
+	}
+	return c
+}
@@ -0,0 +1,69 @@
+package versions
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/containerservice/mgmt/2017-09-30/containerservice"
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/container/v1"
+)
+
+func TestLiveVersions(t *testing.T) {
+	calls := 0
+	l := &Live{
+		provider: types.GCP,
+		cache:    map[string]*types.VersionCatalog{},
+		catalog: func(location string) (*types.VersionCatalog, error) {
+			calls++
+			if location == "mars-north1" {
+				return nil, errors.New("location not found")
+			}
+			return gkeCatalog(&container.ServerConfig{
+				ValidMasterVersions:   []string{"1.17.4-gke.10", "1.16.8-gke.15"},
+				DefaultClusterVersion: "1.16.8-gke.15",
+			}), nil
+		},
+	}
+
+	c, err := l.Versions(types.GCP, "europe-west3")
+	require.NoError(t, err)
+	require.Equal(t, &types.VersionCatalog{Versions: []string{"1.17.4-gke.10", "1.16.8-gke.15"}, Default: "1.16.8-gke.15"}, c)
+	_, err = l.Versions(types.GCP, "europe-west3")
+	require.NoError(t, err)
+	require.Equal(t, 1, calls, "The catalog of a location should be cached")
+
+	_, err = l.Versions(types.GCP, "mars-north1")
+	require.Error(t, err, "Errors of the provider should be returned")
+
+	c, err = l.Versions(types.Kind, "")
+	require.NoError(t, err)
+	require.NotEmpty(t, c.Versions, "Other providers should use the snapshot")
+	require.Equal(t, 2, calls)
+}
+
+func TestAKSCatalog(t *testing.T) {
+	str := func(s string) *string { return &s }
+	yes := true
+	res := containerservice.OrchestratorVersionProfileListResult{
+		OrchestratorVersionProfileProperties: &containerservice.OrchestratorVersionProfileProperties{
+			Orchestrators: &[]containerservice.OrchestratorVersionProfile{
+				{OrchestratorType: str("Kubernetes"), OrchestratorVersion: str("1.16.7")},
+				{OrchestratorType: str("Kubernetes"), OrchestratorVersion: str("1.15.10"), Default: &yes},
+				{OrchestratorType: str("DCOS"), OrchestratorVersion: str("1.11.0")},
+			},
+		},
+	}
+
+	require.Equal(t, &types.VersionCatalog{Versions: []string{"1.16.7", "1.15.10"}, Default: "1.15.10"}, aksCatalog(res))
+	require.Empty(t, aksCatalog(containerservice.OrchestratorVersionProfileListResult{}).Versions)
+}
+
+func TestNewLive(t *testing.T) {
+	_, err := NewLive(&types.Provider{Type: types.Gardener})
+	require.Error(t, err, "Providers without a version API should fail")
+
+	_, err = NewLive(&types.Provider{Type: types.GCP})
+	require.Error(t, err, "Missing credentials should fail")
+}
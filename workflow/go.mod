@@ -0,0 +1,18 @@
+module github.com/kyma-incubator/hydroform/workflow
+
+go 1.15
+
+replace (
+	github.com/kyma-incubator/hydroform/install => ../install
+	github.com/kyma-incubator/hydroform/provision => ../provision
+)
+
+require (
+	github.com/hashicorp/terraform v0.12.13
+	github.com/kyma-incubator/hydroform/install v0.0.0-00010101000000-000000000000
+	github.com/kyma-incubator/hydroform/provision v0.0.0-00010101000000-000000000000
+	github.com/kyma-project/kyma v0.5.1-0.20191106070956-5aa08d114ca0
+	github.com/stretchr/testify v1.7.0
+	github.com/zclconf/go-cty v1.1.0
+	k8s.io/client-go v0.0.0-20191114101336-8cba805ad12d
+)
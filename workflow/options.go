@@ -0,0 +1,65 @@
+package workflow
+
+import (
+	"time"
+
+	"github.com/kyma-incubator/hydroform/install/installation"
+	"github.com/kyma-incubator/hydroform/provision/types"
+)
+
+// defaultReadinessTimeout is how long the workflow waits for the cluster to become usable if no timeout is given.
+const defaultReadinessTimeout = 20 * time.Minute
+
+// Options configures a workflow.
+type Options struct {
+	// StateFile is where the workflow keeps the steps it completed, so that it can resume after an interruption.
+	// The file contains the cluster state and its kubeconfig, so it is only readable by its owner. If empty, the workflow cannot resume.
+	// If the provision options encrypt the cluster state, the kubeconfig is not kept in the file and is read from the provider again when resuming.
+	StateFile string
+	// ProvisionOptions are passed to all provision operations.
+	ProvisionOptions []types.Option
+	// InstallationOptions are passed to the Kyma installer.
+	InstallationOptions []installation.InstallationOption
+	// ReadinessTimeout bounds waiting for the cluster to become usable.
+	ReadinessTimeout time.Duration
+	// Progress is called with the progress of all steps.
+	Progress func(Progress)
+}
+
+// Option is a function that configures a workflow.
+type Option func(*Options)
+
+// Keep the completed steps in the given file to resume the workflow from there.
+func WithStateFile(path string) Option {
+	return func(ops *Options) {
+		ops.StateFile = path
+	}
+}
+
+// Pass the given options to all provision operations, for example types.Persistent.
+func WithProvisionOptions(ops ...types.Option) Option {
+	return func(o *Options) {
+		o.ProvisionOptions = append(o.ProvisionOptions, ops...)
+	}
+}
+
+// Pass the given options to the Kyma installer.
+func WithInstallationOptions(ops ...installation.InstallationOption) Option {
+	return func(o *Options) {
+		o.InstallationOptions = append(o.InstallationOptions, ops...)
+	}
+}
+
+// Wait up to the given timeout for the cluster to become usable before installing Kyma.
+func WithReadinessTimeout(timeout time.Duration) Option {
+	return func(ops *Options) {
+		ops.ReadinessTimeout = timeout
+	}
+}
+
+// Call the given function with the progress of all steps of the workflow.
+func WithProgress(f func(Progress)) Option {
+	return func(ops *Options) {
+		ops.Progress = f
+	}
+}
@@ -0,0 +1,122 @@
+package workflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/terraform/states/statefile"
+	"github.com/kyma-incubator/hydroform/provision/types"
+)
+
+// state is what the workflow keeps in its state file to resume.
+type state struct {
+	// Completed are the steps completed so far.
+	Completed []Step `json:"completed"`
+	// Provider is the type of the provider, to make sure the workflow resumes with the same cluster.
+	Provider types.ProviderType `json:"provider"`
+	// Project is the project of the provider, to make sure the workflow resumes with the same cluster.
+	Project string `json:"project"`
+	// Cluster is the cluster as returned by the provision step.
+	Cluster *types.Cluster `json:"cluster,omitempty"`
+	// TerraformState is the plain cluster state, since it cannot be serialized as part of the cluster.
+	// It is only set if the state is not encrypted.
+	TerraformState []byte `json:"terraformState,omitempty"`
+	// Kubeconfig is the kubeconfig returned by the credentials step.
+	// It is only set if the state is not encrypted.
+	Kubeconfig []byte `json:"kubeconfig,omitempty"`
+}
+
+// loadState reads the state of the workflow from the given file.
+// An empty state is returned if the path is empty or the file does not exist yet.
+func loadState(path string, cluster *types.Cluster, provider *types.Provider) (*state, error) {
+	st := &state{Provider: provider.Type, Project: provider.ProjectName}
+	if path == "" {
+		return st, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return st, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read the workflow state: %w", err)
+	}
+	if err := json.Unmarshal(data, st); err != nil {
+		return nil, fmt.Errorf("could not decode the workflow state in %s: %w", path, err)
+	}
+
+	if st.Provider != provider.Type || st.Project != provider.ProjectName || (st.Cluster != nil && st.Cluster.Name != cluster.Name) {
+		return nil, fmt.Errorf("the workflow state in %s belongs to another cluster", path)
+	}
+
+	if len(st.TerraformState) > 0 && st.Cluster != nil && st.Cluster.ClusterInfo != nil {
+		sf, err := statefile.Read(bytes.NewReader(st.TerraformState))
+		if err != nil {
+			return nil, fmt.Errorf("could not decode the cluster state in %s: %w", path, err)
+		}
+		st.Cluster.ClusterInfo.InternalState = &types.InternalState{TerraformState: sf}
+	}
+	return st, nil
+}
+
+// completed tells whether the given step was completed.
+func (st *state) completed(s Step) bool {
+	for _, c := range st.Completed {
+		if c == s {
+			return true
+		}
+	}
+	return false
+}
+
+// save writes the state into the given file, unless the path is empty.
+// If the cluster state is encrypted, the kubeconfig is left out, since the file itself is not encrypted.
+// The file is replaced as a whole, so that an interruption never leaves a broken state behind.
+func (st *state) save(path string, encrypted bool) error {
+	if path == "" {
+		return nil
+	}
+
+	saved := *st
+	saved.TerraformState = nil
+	if encrypted {
+		saved.Kubeconfig = nil
+	}
+	if st.Cluster != nil && st.Cluster.ClusterInfo != nil {
+		// keep the plain cluster state aside, the encrypted one is serialized with the cluster
+		cl, info := *st.Cluster, *st.Cluster.ClusterInfo
+		if is := info.InternalState; is != nil && len(is.EncryptedTerraformState) == 0 && is.TerraformState != nil {
+			buf := &bytes.Buffer{}
+			if err := statefile.Write(is.TerraformState, buf); err != nil {
+				return fmt.Errorf("could not encode the cluster state: %w", err)
+			}
+			saved.TerraformState = buf.Bytes()
+			info.InternalState = nil
+		}
+		cl.ClusterInfo = &info
+		saved.Cluster = &cl
+	}
+
+	data, err := json.MarshalIndent(&saved, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode the workflow state: %w", err)
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path))
+	if err != nil {
+		return fmt.Errorf("could not write the workflow state: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("could not write the workflow state: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("could not write the workflow state: %w", err)
+	}
+	return os.Rename(f.Name(), path)
+}
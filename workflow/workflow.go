@@ -0,0 +1,308 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kyma-incubator/hydroform/install/installation"
+	"github.com/kyma-incubator/hydroform/provision"
+	"github.com/kyma-incubator/hydroform/provision/kubeconfig"
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/kyma-project/kyma/components/kyma-operator/pkg/apis/installer/v1alpha1"
+	"k8s.io/client-go/rest"
+)
+
+// Step is a step of the workflow.
+type Step string
+
+const (
+	// StepProvision creates the cluster.
+	StepProvision Step = "provision"
+	// StepReadiness waits until the cluster is usable.
+	StepReadiness Step = "readiness"
+	// StepCredentials gets the kubeconfig of the cluster.
+	StepCredentials Step = "credentials"
+	// StepPrepareInstallation deploys Tiller, the Kyma Installer and the installation configuration.
+	StepPrepareInstallation Step = "prepare installation"
+	// StepInstallation installs Kyma and waits until it is installed.
+	StepInstallation Step = "installation"
+)
+
+// Steps are all steps of the workflow in the order they run.
+var Steps = []Step{StepProvision, StepReadiness, StepCredentials, StepPrepareInstallation, StepInstallation}
+
+// pollInterval is the time between two checks of an installation the workflow did not start itself.
+var pollInterval = 30 * time.Second
+
+// Progress reports what happens in a step of the workflow.
+type Progress struct {
+	// Step is the step the progress belongs to.
+	Step Step
+	// Message describes what happens, for example which readiness check passed or the state of the installation.
+	Message string
+	// Completed tells whether the step is completed.
+	Completed bool
+	// Time is when the progress was reported.
+	Time time.Time
+}
+
+// Workflow provisions a cluster and installs Kyma on it.
+type Workflow struct {
+	cluster      *types.Cluster
+	provider     *types.Provider
+	installation installation.Installation
+	ops          Options
+
+	// the functions of the provision and install modules, replaced in tests
+	provision         func(*types.Cluster, *types.Provider, ...types.Option) (*types.Cluster, error)
+	waitForReadiness  func(*types.Cluster, *types.Provider, ...types.Option) error
+	credentials       func(*types.Cluster, *types.Provider, ...types.Option) ([]byte, error)
+	newInstaller      func(*rest.Config, ...installation.InstallationOption) (installation.Installer, error)
+	installationState func(*rest.Config) (installation.InstallationState, error)
+}
+
+// New creates a workflow that provisions the given cluster on the given provider and installs Kyma with the given artifacts on it.
+func New(cluster *types.Cluster, provider *types.Provider, artifacts installation.Installation, ops ...Option) *Workflow {
+	w := &Workflow{
+		cluster:      cluster,
+		provider:     provider,
+		installation: artifacts,
+		ops: Options{
+			ReadinessTimeout: defaultReadinessTimeout,
+		},
+		provision:        provision.Provision,
+		waitForReadiness: provision.WaitForReadiness,
+		credentials:      provision.Credentials,
+		newInstaller: func(config *rest.Config, ops ...installation.InstallationOption) (installation.Installer, error) {
+			return installation.NewKymaInstaller(config, ops...)
+		},
+		installationState: installation.CheckInstallationState,
+	}
+	for _, o := range ops {
+		o(&w.ops)
+	}
+	return w
+}
+
+// Run runs all steps of the workflow and returns the provisioned cluster.
+// If the workflow has a state file, steps completed in an earlier run are skipped, so that an interrupted workflow continues where it stopped.
+func (w *Workflow) Run(ctx context.Context) (*types.Cluster, error) {
+	st, err := loadState(w.ops.StateFile, w.cluster, w.provider)
+	if err != nil {
+		return nil, err
+	}
+	if st.Cluster != nil {
+		// continue with the cluster as it was provisioned
+		w.cluster = st.Cluster
+	}
+
+	for _, s := range Steps {
+		if st.completed(s) {
+			w.report(s, "already completed", true)
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return w.cluster, fmt.Errorf("workflow interrupted before step %s: %w", s, err)
+		}
+
+		if err := w.run(ctx, s, st); err != nil {
+			return w.cluster, fmt.Errorf("step %s failed: %w", s, err)
+		}
+
+		st.Completed = append(st.Completed, s)
+		st.Cluster = w.cluster
+		if err := st.save(w.ops.StateFile, w.encrypted()); err != nil {
+			return w.cluster, err
+		}
+		w.report(s, "completed", true)
+	}
+	return w.cluster, nil
+}
+
+// run runs a single step of the workflow.
+func (w *Workflow) run(ctx context.Context, s Step, st *state) error {
+	switch s {
+	case StepProvision:
+		w.report(s, fmt.Sprintf("provisioning cluster %s on %s", w.cluster.Name, w.provider.Type), false)
+		cl, err := w.provision(w.cluster, w.provider, w.provisionOptions(ctx)...)
+		if err != nil {
+			return err
+		}
+		w.cluster = cl
+		return nil
+
+	case StepReadiness:
+		ops := append(w.provisionOptions(ctx),
+			types.WithReadiness(w.ops.ReadinessTimeout),
+			types.WithReadinessObserver(func(e types.ReadinessEvent) {
+				w.report(s, fmt.Sprintf("%s: %s", e.Check, e.Message), false)
+			}))
+		return w.waitForReadiness(w.cluster, w.provider, ops...)
+
+	case StepCredentials:
+		st.Kubeconfig = nil
+		_, err := w.kubeconfig(ctx, st)
+		return err
+
+	case StepPrepareInstallation:
+		installer, err := w.installer(ctx, st)
+		if err != nil {
+			return err
+		}
+		w.report(s, "deploying Tiller and the Kyma Installer", false)
+		return installer.PrepareInstallation(w.installation)
+
+	case StepInstallation:
+		return w.install(ctx, st)
+
+	default:
+		return fmt.Errorf("unknown step %s", s)
+	}
+}
+
+// install starts the installation of Kyma and waits until it is installed.
+// If an installation is in progress already, for example because the workflow was interrupted while waiting, it waits for that installation instead.
+func (w *Workflow) install(ctx context.Context, st *state) error {
+	data, err := w.kubeconfig(ctx, st)
+	if err != nil {
+		return err
+	}
+	config, err := kubeconfig.RESTConfig(data)
+	if err != nil {
+		return err
+	}
+
+	current, err := w.installationState(config)
+	if err != nil {
+		return fmt.Errorf("could not check the installation state: %w", err)
+	}
+	switch current.State {
+	case string(v1alpha1.StateInstalled):
+		w.report(StepInstallation, "Kyma is installed already", false)
+		return nil
+	case string(v1alpha1.StateInProgress):
+		w.report(StepInstallation, "waiting for the installation in progress", false)
+		return w.waitForInstallation(ctx, config)
+	}
+
+	installer, err := w.installer(ctx, st)
+	if err != nil {
+		return err
+	}
+	states, errs, err := installer.StartInstallation(ctx)
+	if err != nil {
+		return err
+	}
+
+	var last installation.InstallationState
+	var lastErr error
+	for states != nil || errs != nil {
+		select {
+		case s, ok := <-states:
+			if !ok {
+				states = nil
+				continue
+			}
+			last = s
+			w.report(StepInstallation, fmt.Sprintf("%s: %s", s.State, s.Description), false)
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			// the installer retries failed components, so their errors are progress
+			if ie := (installation.InstallationError{}); errors.As(err, &ie) {
+				w.report(StepInstallation, ie.Details(), false)
+				continue
+			}
+			lastErr = err
+		}
+	}
+
+	if last.State == string(v1alpha1.StateInstalled) {
+		return nil
+	}
+	if lastErr != nil {
+		return lastErr
+	}
+	return errors.New("the installation stopped before Kyma was installed")
+}
+
+// waitForInstallation polls the state of an installation until Kyma is installed or the context is done.
+func (w *Workflow) waitForInstallation(ctx context.Context, config *rest.Config) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("stopped waiting for the installation: %w", ctx.Err())
+		case <-ticker.C:
+		}
+
+		s, err := w.installationState(config)
+		if err != nil {
+			return fmt.Errorf("could not check the installation state: %w", err)
+		}
+		w.report(StepInstallation, fmt.Sprintf("%s: %s", s.State, s.Description), false)
+		if s.State == string(v1alpha1.StateInstalled) {
+			return nil
+		}
+	}
+}
+
+// installer creates the Kyma installer for the cluster from the kubeconfig of the workflow state.
+func (w *Workflow) installer(ctx context.Context, st *state) (installation.Installer, error) {
+	data, err := w.kubeconfig(ctx, st)
+	if err != nil {
+		return nil, err
+	}
+	config, err := kubeconfig.RESTConfig(data)
+	if err != nil {
+		return nil, err
+	}
+	installer, err := w.newInstaller(config, w.ops.InstallationOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the Kyma installer: %w", err)
+	}
+	return installer, nil
+}
+
+// kubeconfig returns the kubeconfig of the cluster from the workflow state.
+// The kubeconfig is not kept in the state file if the cluster state is encrypted, so after resuming it is read from the provider again.
+func (w *Workflow) kubeconfig(ctx context.Context, st *state) ([]byte, error) {
+	if len(st.Kubeconfig) > 0 {
+		return st.Kubeconfig, nil
+	}
+	data, err := w.credentials(w.cluster, w.provider, w.provisionOptions(ctx)...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := kubeconfig.RESTConfig(data); err != nil {
+		return nil, err
+	}
+	st.Kubeconfig = data
+	return data, nil
+}
+
+// provisionOptions returns a copy of the provision options bound to the context of the workflow, so that steps can add their own.
+func (w *Workflow) provisionOptions(ctx context.Context) []types.Option {
+	return append(append([]types.Option{}, w.ops.ProvisionOptions...), types.WithContext(ctx))
+}
+
+// encrypted tells whether the cluster state is encrypted with the provision options.
+func (w *Workflow) encrypted() bool {
+	ops := &types.Options{}
+	for _, o := range w.ops.ProvisionOptions {
+		o(ops)
+	}
+	return ops.EncryptionKey != nil
+}
+
+func (w *Workflow) report(s Step, msg string, completed bool) {
+	if w.ops.Progress != nil {
+		w.ops.Progress(Progress{Step: s, Message: msg, Completed: completed, Time: time.Now()})
+	}
+}
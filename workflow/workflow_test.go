@@ -0,0 +1,318 @@
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/addrs"
+	"github.com/hashicorp/terraform/states"
+	"github.com/hashicorp/terraform/states/statefile"
+	"github.com/kyma-incubator/hydroform/install/installation"
+	"github.com/kyma-incubator/hydroform/provision"
+	"github.com/kyma-incubator/hydroform/provision/types"
+	"github.com/kyma-project/kyma/components/kyma-operator/pkg/apis/installer/v1alpha1"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "workflow")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	stateFile := filepath.Join(dir, "workflow.json")
+
+	var progress []Progress
+	w := testWorkflow(t, WithStateFile(stateFile), WithProgress(func(p Progress) { progress = append(progress, p) }))
+	fake := &fakeInstaller{prepareErr: errors.New("tiller did not start")}
+	w.newInstaller = fake.new
+
+	_, err = w.Run(context.Background())
+	require.Error(t, err, "A failing step should fail the workflow")
+	require.Contains(t, err.Error(), "step prepare installation failed")
+	require.Equal(t, StepReadiness, progress[2].Step)
+	require.Equal(t, "nodes: 1 of 1 nodes ready", progress[2].Message, "Readiness events should be reported as progress")
+
+	info, err := os.Stat(stateFile)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm(), "The state file contains secrets")
+
+	// resume with a new workflow, the cluster must not be provisioned again
+	progress = nil
+	w = testWorkflow(t, WithStateFile(stateFile), WithProgress(func(p Progress) { progress = append(progress, p) }))
+	w.provision = func(*types.Cluster, *types.Provider, ...types.Option) (*types.Cluster, error) {
+		t.Fatal("A completed step should not run again")
+		return nil, nil
+	}
+	fake = &fakeInstaller{}
+	w.newInstaller = fake.new
+
+	cl, err := w.Run(context.Background())
+	require.NoError(t, err)
+	require.True(t, fake.prepared)
+	require.Equal(t, "1.2.3.4", cl.ClusterInfo.Endpoint)
+	require.Equal(t, uint64(7), cl.ClusterInfo.InternalState.TerraformState.Serial, "The cluster state should be restored from the state file")
+	require.Equal(t, "https://1.2.3.4", fake.config.Host, "The installer should use the credentials of the cluster")
+
+	var completed []Step
+	var installProgress []string
+	for _, p := range progress {
+		if p.Completed {
+			completed = append(completed, p.Step)
+		} else if p.Step == StepInstallation {
+			installProgress = append(installProgress, p.Message)
+		}
+	}
+	require.Equal(t, Steps, completed)
+	require.Equal(t, []string{"InProgress: installing", "Installation errors: \nComponent: istio, Log: timeout", "Installed: done"}, installProgress)
+
+	// everything is done already
+	_, err = testWorkflow(t, WithStateFile(stateFile)).Run(context.Background())
+	require.NoError(t, err)
+
+	// another cluster must not use the state
+	w = testWorkflow(t, WithStateFile(stateFile))
+	w.cluster = &types.Cluster{Name: "other"}
+	_, err = w.Run(context.Background())
+	require.Error(t, err)
+}
+
+func TestRunEncrypted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "workflow")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	stateFile := filepath.Join(dir, "workflow.json")
+
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "workflow")
+
+	w := testWorkflow(t, WithStateFile(stateFile), WithProvisionOptions(types.WithEncryptionKey([]byte("0123456789abcdef0123456789abcdef"))))
+	provision := w.provision
+	w.provision = func(cl *types.Cluster, p *types.Provider, ops ...types.Option) (*types.Cluster, error) {
+		os := &types.Options{}
+		for _, o := range ops {
+			o(os)
+		}
+		require.Equal(t, ctx, os.Context, "The provision operations should be bound to the context of the workflow")
+		return provision(cl, p, ops...)
+	}
+	w.newInstaller = (&fakeInstaller{prepareErr: errors.New("tiller did not start")}).new
+
+	_, err = w.Run(ctx)
+	require.Error(t, err)
+
+	data, err := ioutil.ReadFile(stateFile)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "kubeconfig", "The kubeconfig should not be kept in the state file if the cluster state is encrypted")
+
+	// resume with a new workflow, the kubeconfig is read again
+	w = testWorkflow(t, WithStateFile(stateFile), WithProvisionOptions(types.WithEncryptionKey([]byte("0123456789abcdef0123456789abcdef"))))
+	fake := &fakeInstaller{}
+	w.newInstaller = fake.new
+
+	_, err = w.Run(ctx)
+	require.NoError(t, err)
+	require.True(t, fake.prepared)
+	require.Equal(t, "https://1.2.3.4", fake.config.Host, "The installer should use the credentials read again")
+}
+
+func TestRunEncryptedReadsCredentialsFromState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "workflow")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	stateFile := filepath.Join(dir, "workflow.json")
+	secret := []byte("0123456789abcdef0123456789abcdef")
+
+	// Azure reads the kubeconfig from the outputs of the cluster state, which is only kept encrypted in the state file
+	cluster := &types.Cluster{Name: "my-cluster", NodeCount: 1, Location: "westeurope", MachineType: "Standard_D4_v3", KubernetesVersion: "1.12"}
+	provider := &types.Provider{Type: types.Azure, ProjectName: "my-resource-group", CredentialsFilePath: "testdata/credentials.toml"}
+	config := api.NewConfig()
+	config.Clusters["c"] = &api.Cluster{Server: "https://1.2.3.4"}
+	config.AuthInfos["u"] = &api.AuthInfo{Token: "secret"}
+	config.Contexts["c"] = &api.Context{Cluster: "c", AuthInfo: "u"}
+	config.CurrentContext = "c"
+	kubeconfig, err := clientcmd.Write(*config)
+	require.NoError(t, err)
+	sf := statefile.New(states.BuildState(func(s *states.SyncState) {
+		s.SetOutputValue(addrs.OutputValue{Name: "kube_config"}.Absolute(addrs.RootModuleInstance), cty.StringVal(string(kubeconfig)), true)
+	}), "lineage", 1)
+
+	newWorkflow := func() *Workflow {
+		w := testWorkflow(t, WithStateFile(stateFile), WithProvisionOptions(types.WithEncryptionKey(secret)))
+		w.cluster, w.provider = cluster, provider
+		w.credentials = provision.Credentials
+		return w
+	}
+
+	w := newWorkflow()
+	w.provision = func(cl *types.Cluster, p *types.Provider, ops ...types.Option) (*types.Cluster, error) {
+		cl.ClusterInfo = &types.ClusterInfo{
+			Endpoint:      "1.2.3.4",
+			InternalState: &types.InternalState{TerraformState: sf, EncryptedTerraformState: encryptState(t, secret, sf)},
+		}
+		return cl, nil
+	}
+	w.newInstaller = (&fakeInstaller{prepareErr: errors.New("tiller did not start")}).new
+
+	_, err = w.Run(context.Background())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "step prepare installation failed", "The credentials should be read from the provisioned cluster")
+
+	// resume with a new workflow, only the encrypted cluster state is left to read the kubeconfig from
+	w = newWorkflow()
+	fake := &fakeInstaller{}
+	w.newInstaller = fake.new
+
+	cl, err := w.Run(context.Background())
+	require.NoError(t, err)
+	require.Nil(t, cl.ClusterInfo.InternalState.TerraformState, "The plain cluster state should not be kept in the state file")
+	require.True(t, fake.prepared)
+	require.Equal(t, "https://1.2.3.4", fake.config.Host, "The installer should use the kubeconfig decrypted from the cluster state")
+
+	// the state cannot be read without the key
+	w = newWorkflow()
+	w.cluster = cl
+	w.ops.ProvisionOptions = nil
+	_, err = w.kubeconfig(context.Background(), &state{})
+	require.Error(t, err)
+}
+
+func TestRunInstallationInProgress(t *testing.T) {
+	defer func(i time.Duration) { pollInterval = i }(pollInterval)
+	pollInterval = 10 * time.Millisecond
+	w := testWorkflow(t)
+	w.newInstaller = func(*rest.Config, ...installation.InstallationOption) (installation.Installer, error) {
+		return &fakeInstaller{startErr: errors.New("installation already in progress")}, nil
+	}
+	checks := 0
+	w.installationState = func(*rest.Config) (installation.InstallationState, error) {
+		checks++
+		if checks < 3 {
+			return installation.InstallationState{State: string(v1alpha1.StateInProgress)}, nil
+		}
+		return installation.InstallationState{State: string(v1alpha1.StateInstalled)}, nil
+	}
+
+	_, err := w.Run(context.Background())
+	require.NoError(t, err, "An installation in progress should be waited for instead of started")
+	require.Equal(t, 3, checks)
+}
+
+func TestRunInstallationFailed(t *testing.T) {
+	w := testWorkflow(t)
+	w.newInstaller = func(*rest.Config, ...installation.InstallationOption) (installation.Installer, error) {
+		return &fakeInstaller{watchErr: errors.New("failed to setup installation watcher")}, nil
+	}
+
+	_, err := w.Run(context.Background())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to setup installation watcher")
+}
+
+func testWorkflow(t *testing.T, ops ...Option) *Workflow {
+	cluster := &types.Cluster{Name: "my-cluster"}
+	provider := &types.Provider{Type: types.GCP, ProjectName: "my-project"}
+	w := New(cluster, provider, installation.Installation{TillerYaml: "tiller"}, ops...)
+
+	w.provision = func(cl *types.Cluster, p *types.Provider, ops ...types.Option) (*types.Cluster, error) {
+		cl.ClusterInfo = &types.ClusterInfo{
+			Endpoint:      "1.2.3.4",
+			InternalState: &types.InternalState{TerraformState: statefile.New(states.NewState(), "lineage", 7)},
+		}
+		return cl, nil
+	}
+	w.waitForReadiness = func(cl *types.Cluster, p *types.Provider, ops ...types.Option) error {
+		os := &types.Options{}
+		for _, o := range ops {
+			o(os)
+		}
+		require.Equal(t, defaultReadinessTimeout, os.ReadinessTimeout)
+		os.ReadinessObserver(types.ReadinessEvent{Check: types.ReadinessNodes, Ready: true, Message: "1 of 1 nodes ready"})
+		return nil
+	}
+	w.credentials = func(cl *types.Cluster, p *types.Provider, ops ...types.Option) ([]byte, error) {
+		config := api.NewConfig()
+		config.Clusters["c"] = &api.Cluster{Server: "https://" + cl.ClusterInfo.Endpoint}
+		config.AuthInfos["u"] = &api.AuthInfo{Token: "secret"}
+		config.Contexts["c"] = &api.Context{Cluster: "c", AuthInfo: "u"}
+		config.CurrentContext = "c"
+		return clientcmd.Write(*config)
+	}
+	w.newInstaller = (&fakeInstaller{}).new
+	w.installationState = func(*rest.Config) (installation.InstallationState, error) {
+		return installation.InstallationState{State: installation.NoInstallationState}, nil
+	}
+	return w
+}
+
+// fakeInstaller reports an installation which fails for a component once and then succeeds.
+type fakeInstaller struct {
+	prepareErr error
+	startErr   error
+	watchErr   error
+
+	config   *rest.Config
+	prepared bool
+}
+
+func (f *fakeInstaller) new(config *rest.Config, ops ...installation.InstallationOption) (installation.Installer, error) {
+	f.config = config
+	return f, nil
+}
+
+func (f *fakeInstaller) PrepareInstallation(installation.Installation) error {
+	f.prepared = f.prepareErr == nil
+	return f.prepareErr
+}
+
+func (f *fakeInstaller) PrepareUpgrade(installation.Installation) error {
+	return nil
+}
+
+func (f *fakeInstaller) StartInstallation(context.Context) (<-chan installation.InstallationState, <-chan error, error) {
+	if f.startErr != nil {
+		return nil, nil, f.startErr
+	}
+
+	states := make(chan installation.InstallationState)
+	errs := make(chan error)
+	go func() {
+		defer close(errs)
+		defer close(states)
+		if f.watchErr != nil {
+			errs <- f.watchErr
+			return
+		}
+		states <- installation.InstallationState{State: string(v1alpha1.StateInProgress), Description: "installing"}
+		errs <- installation.InstallationError{ShortMessage: "istio failed", ErrorEntries: []installation.ErrorEntry{{Component: "istio", Log: "timeout"}}}
+		states <- installation.InstallationState{State: string(v1alpha1.StateInstalled), Description: "done"}
+	}()
+	return states, errs, nil
+}
+
+// encryptState encrypts the cluster state the way the provision module does with the given key, which is not accessible from here.
+func encryptState(t *testing.T, secret []byte, sf *statefile.File) []byte {
+	buf := &bytes.Buffer{}
+	require.NoError(t, statefile.Write(sf, buf))
+	key := sha256.Sum256(secret)
+	block, err := aes.NewCipher(key[:])
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = rand.Read(nonce)
+	require.NoError(t, err)
+	return gcm.Seal(nonce, nonce, buf.Bytes(), nil)
+}